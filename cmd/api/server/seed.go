@@ -0,0 +1,39 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/Vinodbagra/cache-thread/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// seedPrefixVariable names the setting holding the prefix, so it is never seeded itself
+const seedPrefixVariable = "CACHE_SEED_PREFIX"
+
+// seedFromEnv stores every variable in environ (as returned by os.Environ) named prefix+key
+// under key, e.g. CACHE_SEED_foo=bar as "foo" when prefix is CACHE_SEED_. Values are stored
+// as strings that never expire. It returns how many keys were seeded.
+func seedFromEnv(cacheService *service.CacheService, prefix string, environ []string) int {
+	fields := logrus.Fields{constants.LoggerCategory: constants.LoggerCategorySeeder}
+	noExpiration := service.NoExpiration
+
+	seeded := 0
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+		key, found := strings.CutPrefix(name, prefix)
+		if !found || key == "" || name == seedPrefixVariable {
+			continue
+		}
+
+		if _, err := cacheService.Put(key, value, &noExpiration); err != nil {
+			logger.ErrorF("seeding key '%s' from %s: %v", fields, key, name, err)
+			continue
+		}
+		seeded++
+	}
+
+	logger.InfoF("seeded %d keys from environment variables prefixed %s", fields, seeded, prefix)
+	return seeded
+}
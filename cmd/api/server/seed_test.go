@@ -0,0 +1,37 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/Vinodbagra/cache-thread/internal/service"
+)
+
+func TestSeedFromEnv(t *testing.T) {
+	cs := service.NewCacheServiceWithOptions(service.CacheOptions{MaxSize: 10, DisableCleanup: true})
+	defer cs.Close()
+
+	seeded := seedFromEnv(cs, "CACHE_SEED_", []string{
+		"CACHE_SEED_PREFIX=CACHE_SEED_", // the prefix setting itself, which shares the prefix
+		"CACHE_SEED_greeting=hello",
+		"CACHE_SEED_url=http://example.com/?a=b", // only the first = separates the value
+		"CACHE_SEED_=empty key",
+		"CACHE_SEED_blank=",
+		"OTHER_VAR=ignored",
+		"cache_seed_lower=ignored",
+	})
+	if seeded != 3 {
+		t.Fatalf("seeded %d keys, want 3", seeded)
+	}
+
+	for key, want := range map[string]string{"greeting": "hello", "url": "http://example.com/?a=b", "blank": ""} {
+		if entry, found := cs.Get(key); !found || entry.GetValue() != want {
+			t.Fatalf("Get(%q) = %v, %v; want %q", key, entry, found, want)
+		}
+	}
+	if keys := cs.ListKeys(); len(keys) != 3 {
+		t.Fatalf("cache holds %v, want only the 3 seeded keys", keys)
+	}
+	if info, _ := cs.GetInfo("greeting"); info.TTL != -1 {
+		t.Fatalf("seeded key has TTL %d, want none", info.TTL)
+	}
+}
@@ -2,24 +2,31 @@ package server
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/Vinodbagra/cache-thread/internal/config"
 	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/handler"
 	"github.com/Vinodbagra/cache-thread/internal/routes"
+	"github.com/Vinodbagra/cache-thread/internal/service"
 	"github.com/Vinodbagra/cache-thread/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
 type App struct {
-	HttpServer *http.Server
+	HttpServer   *http.Server
+	CacheService *service.CacheService
+	CacheHandler *handler.CacheHandler
+	Replicator   *handler.Replicator
 }
 
 func NewApp() (*App, error) {
@@ -32,24 +39,107 @@ func NewApp() (*App, error) {
 	api := router.Group("api")
 	api.GET("/", routes.RootHandler)
 
-	// Register cache routes
-	cacheRoutes := routes.NewCacheRoute(api, config.AppConfig.CacheMaxSize, config.AppConfig.CacheTTL)
+	// Register cache routes (the encryption key was validated when loading config)
+	encryptionKey, _ := hex.DecodeString(config.AppConfig.CacheEncryptionKey)
+	replicator := handler.NewReplicator(splitList(config.AppConfig.CacheReplicationPeers), config.AppConfig.CacheReplicationWaitTimeout)
+	cacheRoutes := routes.NewCacheRoute(api, service.CacheOptions{
+		MaxSize:               config.AppConfig.CacheMaxSize,
+		DefaultTTL:            config.AppConfig.CacheTTL,
+		BackpressureThreshold: config.AppConfig.CacheBackpressureThreshold,
+		BackpressureMode:      config.AppConfig.CacheBackpressureMode,
+		ThrashThreshold:       config.AppConfig.CacheThrashThreshold,
+		ThrashMode:            config.AppConfig.CacheThrashMode,
+		EncryptionKey:         encryptionKey,
+		OpLogSize:             config.AppConfig.CacheOpLogSize,
+		KeyHashThreshold:      config.AppConfig.CacheKeyHashThreshold,
+		ReadOnly:              config.AppConfig.CacheReadOnly,
+		StaleWhileRevalidate:  config.AppConfig.CacheStaleWhileRevalidate,
+		CompactThreshold:      config.AppConfig.CacheCompactThreshold,
+		TTLPromotionStep:      config.AppConfig.CacheTTLPromotionStep,
+		TTLPromotionMax:       config.AppConfig.CacheTTLPromotionMax,
+		MaxTTL:                config.AppConfig.CacheMaxTTL,
+		MaxTTLMode:            config.AppConfig.CacheMaxTTLMode,
+		HighWatermark:         config.AppConfig.CacheHighWatermark,
+		LowWatermark:          config.AppConfig.CacheLowWatermark,
+		CleanupJitter:         config.AppConfig.CacheCleanupJitter,
+		DisableCleanup:        config.AppConfig.CacheCleanupDisabled,
+		EvictionPolicy:        config.AppConfig.CacheEvictionPolicy,
+		LRUK:                  config.AppConfig.CacheLRUK,
+		BatchPromotions:       config.AppConfig.CacheBatchPromotions,
+		ChecksumValues:        config.AppConfig.CacheChecksumValues,
+	}, handler.HandlerOptions{
+		StatsStreamInterval: config.AppConfig.CacheStatsStreamInterval,
+		GzipMinSize:         config.AppConfig.ServerGzipMinSize,
+		Replicator:          replicator,
+		MaxBodyBytes:        config.AppConfig.ServerMaxBodyBytes,
+		MaxBulkBodyBytes:    config.AppConfig.ServerMaxBulkBodyBytes,
+		ResponseEnvelope:    config.AppConfig.ServerResponseEnvelope,
+		PanicStackBytes:     config.AppConfig.ServerPanicStackBytes,
+		Debug:               config.AppConfig.Debug,
+		HotTierSize:         config.AppConfig.CacheHotTierSize,
+	})
 	cacheRoutes.Routes()
+	cacheRoutes.Service.OnThrashing(logThrashing)
+	cacheRoutes.Service.OnCorruption(logCorruption)
 
-	// setup http server
-	server := &http.Server{
-		Addr:           fmt.Sprintf(":%d", config.AppConfig.Port),
-		Handler:        router,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		MaxHeaderBytes: 1 << 20,
+	// Restore the persisted cache before seeding, so seeded keys take precedence
+	if path := config.AppConfig.CachePersistPath; path != "" {
+		restored, err := cacheRoutes.Service.OpenWriteBehind(path, config.AppConfig.CachePersistInterval, config.AppConfig.CachePersistCompactInterval)
+		if err != nil {
+			return nil, fmt.Errorf("error when restoring cache from %s: %w", path, err)
+		}
+		cacheRoutes.Service.OnPersistError(logPersistError)
+		logger.InfoF("restored %d keys from %s", logrus.Fields{constants.LoggerCategory: constants.LoggerCategoryCache}, restored, path)
+	}
+
+	// Seed keys from the environment before the server accepts requests
+	if config.AppConfig.CacheSeedPrefix != "" {
+		seedFromEnv(cacheRoutes.Service, config.AppConfig.CacheSeedPrefix, os.Environ())
 	}
 
 	return &App{
-		HttpServer: server,
+		HttpServer:   newHTTPServer(config.AppConfig, router),
+		CacheService: cacheRoutes.Service,
+		CacheHandler: cacheRoutes.Handler,
+		Replicator:   replicator,
 	}, nil
 }
 
+// logThrashing warns when the cache starts thrashing and notes when it recovers
+func logThrashing(engaged bool, rate float64) {
+	fields := logrus.Fields{constants.LoggerCategory: constants.LoggerCategoryCache}
+	if engaged {
+		logger.WarnF("cache is thrashing: %.1f evictions/s, the working set does not fit in CACHE_MAX_SIZE", fields, rate)
+		return
+	}
+	logger.InfoF("cache stopped thrashing: %.1f evictions/s", fields, rate)
+}
+
+// logCorruption reports a value that failed its checksum on read; the key was removed
+func logCorruption(key string) {
+	logger.ErrorF("cache value for key %q failed its checksum and was removed", logrus.Fields{constants.LoggerCategory: constants.LoggerCategoryCache}, key)
+}
+
+// logPersistError reports a failed write-behind flush or compaction; the next tick retries
+// with a full compaction
+func logPersistError(err error) {
+	logger.ErrorF("cache persistence failed: %v", logrus.Fields{constants.LoggerCategory: constants.LoggerCategoryCache}, err)
+}
+
+// newHTTPServer builds the http server from config; h2c lets HTTP/2 clients connect without TLS
+func newHTTPServer(cfg config.Config, router *gin.Engine) *http.Server {
+	router.UseH2C = true
+
+	return &http.Server{
+		Addr:           fmt.Sprintf(":%d", cfg.Port),
+		Handler:        router.Handler(),
+		ReadTimeout:    cfg.ServerReadTimeout,
+		WriteTimeout:   cfg.ServerWriteTimeout,
+		IdleTimeout:    cfg.ServerIdleTimeout,
+		MaxHeaderBytes: cfg.ServerMaxHeaderBytes,
+	}
+}
+
 func (a *App) Run() (err error) {
 	// Gracefull Shutdown
 	go func() {
@@ -64,13 +154,33 @@ func (a *App) Run() (err error) {
 
 	// make blocking channel and waiting for a signal
 	<-quit
+	a.Drain()
 	logger.Info("shutdown server ...", logrus.Fields{constants.LoggerCategory: constants.LoggerCategoryServer})
 
+	// End SSE streams with a final event first; Shutdown would otherwise wait on them
+	// until its timeout and then leave them to be cut
+	graceCtx, cancelGrace := context.WithTimeout(context.Background(), config.AppConfig.ServerStreamGracePeriod)
+	if open := a.CacheHandler.CloseStreams(graceCtx); open > 0 {
+		logger.WarnF("%d streams still open after the grace period", logrus.Fields{constants.LoggerCategory: constants.LoggerCategoryServer}, open)
+	}
+	cancelGrace()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := a.HttpServer.Shutdown(ctx); err != nil {
-		return fmt.Errorf("error when shutdown server: %v", err)
+	shutdownErr := a.HttpServer.Shutdown(ctx)
+
+	// Flush writes not yet persisted, even when requests were cut off by the timeout, and
+	// before delivering to peers, which may take as long as they are slow to answer
+	if err := a.CacheService.CloseWriteBehind(); err != nil {
+		logger.ErrorF("final cache flush failed: %v", logrus.Fields{constants.LoggerCategory: constants.LoggerCategoryCache}, err)
+	}
+
+	// Deliver writes still queued for peers
+	a.Replicator.Close()
+
+	if shutdownErr != nil {
+		return fmt.Errorf("error when shutdown server: %v", shutdownErr)
 	}
 
 	// catching ctx.Done(). timeout of 5 seconds.
@@ -80,6 +190,12 @@ func (a *App) Run() (err error) {
 	return
 }
 
+// Drain stops accepting cache writes so in-flight reads can finish before shutdown
+func (a *App) Drain() {
+	a.CacheService.Drain()
+	logger.Info("draining: cache writes are now rejected", logrus.Fields{constants.LoggerCategory: constants.LoggerCategoryServer})
+}
+
 func setupRouter() *gin.Engine {
 	// set the runtime mode
 	var mode = gin.ReleaseMode
@@ -90,26 +206,69 @@ func setupRouter() *gin.Engine {
 
 	// create a new router instance
 	router := gin.New()
+	// match routes on the escaped path so percent-encoded slashes stay inside a :key
+	// segment; handlers unescape path values themselves (see handler.pathParam)
+	router.UseRawPath = true
+	router.UnescapePathValues = false
 
 	// set up middlewares
-	router.Use(CORSMiddleware())
+	router.Use(handler.RequestID())
+	router.Use(CORSMiddleware(config.AppConfig))
 	router.Use(gin.LoggerWithFormatter(logger.HTTPLogger))
 	router.Use(gin.Recovery())
 
 	return router
 }
 
-func CORSMiddleware() gin.HandlerFunc {
+// CORSMiddleware answers cross-origin requests only for origins in the configured allowlist,
+// echoing the origin back. "*" is sent only when explicitly configured.
+func CORSMiddleware(cfg config.Config) gin.HandlerFunc {
+	origins := make(map[string]bool)
+	for _, origin := range splitList(cfg.CORSAllowedOrigins) {
+		origins[origin] = true
+	}
+
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			// Not a cross-origin request
+			c.Next()
+			return
+		}
+
+		c.Header("Vary", "Origin")
+		allowed := origins["*"] || origins[origin]
+		if allowed {
+			if origins["*"] {
+				c.Header("Access-Control-Allow-Origin", "*")
+			} else {
+				c.Header("Access-Control-Allow-Origin", origin)
+			}
+			c.Header("Access-Control-Allow-Methods", cfg.CORSAllowedMethods)
+			c.Header("Access-Control-Allow-Headers", cfg.CORSAllowedHeaders)
+		}
 
 		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+			// Preflight: refuse origins outside the allowlist outright
+			if !allowed {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
 		c.Next()
 	}
 }
+
+// splitList splits a comma-separated config value, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
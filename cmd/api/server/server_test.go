@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewHTTPServerUsesConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Config{
+		Port:                 8123,
+		ServerReadTimeout:    3 * time.Second,
+		ServerWriteTimeout:   4 * time.Second,
+		ServerIdleTimeout:    5 * time.Second,
+		ServerMaxHeaderBytes: 4096,
+	}
+
+	srv := newHTTPServer(cfg, gin.New())
+	if srv.Addr != ":8123" {
+		t.Errorf("Addr = %q, want :8123", srv.Addr)
+	}
+	if srv.ReadTimeout != cfg.ServerReadTimeout || srv.WriteTimeout != cfg.ServerWriteTimeout || srv.IdleTimeout != cfg.ServerIdleTimeout {
+		t.Errorf("timeouts read %v, write %v, idle %v; want %v, %v and %v", srv.ReadTimeout, srv.WriteTimeout, srv.IdleTimeout,
+			cfg.ServerReadTimeout, cfg.ServerWriteTimeout, cfg.ServerIdleTimeout)
+	}
+	if srv.MaxHeaderBytes != cfg.ServerMaxHeaderBytes {
+		t.Errorf("MaxHeaderBytes = %d, want %d", srv.MaxHeaderBytes, cfg.ServerMaxHeaderBytes)
+	}
+}
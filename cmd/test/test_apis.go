@@ -1,16 +1,30 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 const baseURL = "http://localhost:8080/api/cache"
 
+// corsAllowedOrigin must be listed in the server's CORS_ALLOWED_ORIGINS for the CORS test
+const corsAllowedOrigin = "http://localhost:3000"
+
+// replicationStubAddr is where the replication wait test serves a stub peer; the server's
+// CACHE_REPLICATION_PEERS must be http://localhost:8091/api/cache for it to run
+const replicationStubAddr = "localhost:8091"
+
 // TestResults holds the results of API tests
 type TestResults struct {
 	TotalTests         int
@@ -65,6 +79,183 @@ func main() {
 	// Test 13: Get after clear (should be empty)
 	testGetAfterClear(results)
 
+	// Test 14: Put overwrite (should be 200, not 201)
+	testPutOverwrite(results)
+
+	// Test 15: Size tracks puts and deletes
+	testGetSize(results)
+
+	// Test 16: Key info counts accesses
+	testGetInfo(results)
+
+	// Test 17: Idempotent put is applied once
+	testIdempotentPut(results)
+
+	// Test 18: Rename key
+	testRename(results)
+
+	// Test 19: MessagePack content negotiation
+	testGetMsgPack(results)
+
+	// Test 20: Ping
+	testPing(results)
+
+	// Test 21: Operation log
+	testOpLog(results)
+
+	// Test 22: Stats stream
+	testStatsStream(results)
+
+	// Test 23: Put with expire at
+	testPutExpireAt(results)
+
+	// Test 24: Http stats
+	testHTTPStats(results)
+
+	// Test 25: Bulk put batch ttl
+	testBulkPutBatchTTL(results)
+
+	// Test 26: Delete by pattern
+	testDeleteByPattern(results)
+
+	// Test 27: Ttl histogram
+	testTTLHistogram(results)
+
+	// Test 28: Jsonpath read
+	testJSONPathRead(results)
+
+	// Test 29: Publish subscribe
+	testPubSub(results)
+
+	// Test 30: Get with default
+	testGetDefault(results)
+
+	// Test 31: Cors
+	testCORS(results)
+
+	// Test 32: Stale while revalidate
+	testStaleWhileRevalidate(results)
+
+	// Test 33: Count keys
+	testCountKeys(results)
+
+	// Test 34: Conditional delete
+	testConditionalDelete(results)
+
+	// Test 35: Ttl promotion
+	testTTLPromotion(results)
+
+	// Test 36: Diagnostics
+	testDiagnostics(results)
+
+	// Test 37: Gzip compression
+	testGzipCompression(results)
+
+	// Test 38: Bulk get ttl
+	testBulkGetTTL(results)
+
+	// Test 39: Latency percentiles
+	testLatencyPercentiles(results)
+
+	// Test 40: Preserve ttl
+	testPreserveTTL(results)
+
+	// Test 41: Last-modified
+	testLastModified(results)
+
+	// Test 42: Async bulk put
+	testAsyncBulkPut(results)
+
+	// Test 43: Persistent put
+	testPersistentPut(results)
+
+	// Test 44: Request body limit
+	testRequestBodyLimit(results)
+
+	// Test 45: Scan cursor
+	testScanCursor(results)
+
+	// Test 46: Next eviction
+	testNextEviction(results)
+
+	// Test 47: Expire and persist
+	testExpirePersist(results)
+
+	// Test 48: Bulk exists
+	testBulkExists(results)
+
+	// Test 49: Cleanup control
+	testCleanupControl(results)
+
+	// Test 50: Response envelope
+	testResponseEnvelope(results)
+
+	// Test 51: Max idle
+	testMaxIdle(results)
+
+	// Test 52: Request id
+	testRequestID(results)
+
+	// Test 53: Special character keys
+	testSpecialCharacterKeys(results)
+
+	// Test 54: Bulk get default
+	testBulkGetDefault(results)
+
+	// Test 55: Value size stats
+	testValueSizeStats(results)
+
+	// Test 56: Bulk touch
+	testBulkTouch(results)
+
+	// Test 57: Expiring soon
+	testExpiringSoon(results)
+
+	// Test 58: Worker health
+	testWorkerHealth(results)
+
+	// Test 59: Import strategies
+	testImportStrategies(results)
+
+	// Test 60: Return evicted
+	testReturnEvicted(results)
+
+	// Test 61: Compare keys
+	testCompareKeys(results)
+
+	// Test 62: Raw value
+	testRawValue(results)
+
+	// Test 63: Eviction rate
+	testEvictionRate(results)
+
+	// Test 64: Delete by hierarchy
+	testDeleteByHierarchy(results)
+
+	// Test 65: Warmup
+	testWarmup(results)
+
+	// Test 66: Csv export
+	testExportCSV(results)
+
+	// Test 67: Default ttl
+	testSetDefaultTTL(results)
+
+	// Test 68: Bulk item status
+	testBulkItemStatus(results)
+
+	// Test 69: Max ttl
+	testMaxTTL(results)
+
+	// Test 70: Random sample
+	testSample(results)
+
+	// Test 71: Lru order
+	testLRUOrder(results)
+
+	// Test 72: Replication wait
+	testReplicationWait(results)
+
 	// Print final results
 	printResults(results)
 }
@@ -412,6 +603,3457 @@ func testGetAfterClear(results *TestResults) {
 	passTest(results)
 }
 
+func testPutOverwrite(results *TestResults) {
+	fmt.Println("\n📋 Test 14: Put Overwrite")
+
+	put := func(value string) (*http.Response, error) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"key":   "test:overwrite:1",
+			"value": value,
+		})
+		req, err := http.NewRequest("PUT", baseURL+"/put", bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return http.DefaultClient.Do(req)
+	}
+
+	resp, err := put("first")
+	if err != nil {
+		failTest(results, "Put Overwrite", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		failTest(results, "Put Overwrite", fmt.Sprintf("Expected 201 on create, got %d", resp.StatusCode))
+		return
+	}
+
+	resp, err = put("second")
+	if err != nil {
+		failTest(results, "Put Overwrite", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		failTest(results, "Put Overwrite", fmt.Sprintf("Expected 200 on update, got %d", resp.StatusCode))
+		return
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("✅ Put Overwrite Passed - Status: %d\n", resp.StatusCode)
+	fmt.Printf("   Response: %s\n", string(body))
+	passTest(results)
+}
+
+func testGetSize(results *TestResults) {
+	fmt.Println("\n📋 Test 15: Get Size")
+
+	getSize := func() (int, error) {
+		resp, err := http.Get(baseURL + "/size")
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+
+		var size struct {
+			CurrentSize int `json:"current_size"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&size); err != nil {
+			return 0, err
+		}
+		return size.CurrentSize, nil
+	}
+
+	before, err := getSize()
+	if err != nil {
+		failTest(results, "Get Size", err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"items": []map[string]interface{}{
+			{"key": "size:1", "value": "one"},
+			{"key": "size:2", "value": "two"},
+		},
+	}
+	jsonData, _ := json.Marshal(data)
+	resp, err := http.Post(baseURL+"/bulk/put", "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		failTest(results, "Get Size", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest("DELETE", baseURL+"/delete/size:1", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		failTest(results, "Get Size", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	after, err := getSize()
+	if err != nil {
+		failTest(results, "Get Size", err.Error())
+		return
+	}
+
+	if after != before+1 {
+		failTest(results, "Get Size", fmt.Sprintf("Expected size %d, got %d", before+1, after))
+		return
+	}
+
+	fmt.Printf("✅ Get Size Passed - Size: %d -> %d\n", before, after)
+	passTest(results)
+}
+
+func testGetInfo(results *TestResults) {
+	fmt.Println("\n📋 Test 16: Get Key Info")
+
+	jsonData, _ := json.Marshal(map[string]interface{}{"key": "info:1", "value": "tracked"})
+	req, _ := http.NewRequest("PUT", baseURL+"/put", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		failTest(results, "Get Key Info", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	for i := 0; i < 3; i++ {
+		resp, err := http.Get(baseURL + "/get/info:1")
+		if err != nil {
+			failTest(results, "Get Key Info", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err = http.Get(baseURL + "/info/info:1")
+	if err != nil {
+		failTest(results, "Get Key Info", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		failTest(results, "Get Key Info", fmt.Sprintf("Expected 200, got %d", resp.StatusCode))
+		return
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var info struct {
+		AccessCount int64 `json:"access_count"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		failTest(results, "Get Key Info", err.Error())
+		return
+	}
+
+	if info.AccessCount != 3 {
+		failTest(results, "Get Key Info", fmt.Sprintf("Expected access_count 3, got %d", info.AccessCount))
+		return
+	}
+
+	fmt.Printf("✅ Get Key Info Passed - Status: %d\n", resp.StatusCode)
+	fmt.Printf("   Response: %s\n", string(body))
+	passTest(results)
+}
+
+func testIdempotentPut(results *TestResults) {
+	fmt.Println("\n📋 Test 17: Idempotent Put")
+
+	idempotencyKey := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	put := func(value string) (*http.Response, error) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"key":   "test:idempotent:" + idempotencyKey,
+			"value": value,
+		})
+		req, err := http.NewRequest("PUT", baseURL+"/put", bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+		return http.DefaultClient.Do(req)
+	}
+
+	resp, err := put("first")
+	if err != nil {
+		failTest(results, "Idempotent Put", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	// The retry replays the original 201 instead of overwriting (which would be 200)
+	resp, err = put("second")
+	if err != nil {
+		failTest(results, "Idempotent Put", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Idempotent-Replayed") != "true" {
+		failTest(results, "Idempotent Put", fmt.Sprintf("Expected replayed 201, got %d", resp.StatusCode))
+		return
+	}
+
+	getResp, err := http.Get(baseURL + "/get/test:idempotent:" + idempotencyKey)
+	if err != nil {
+		failTest(results, "Idempotent Put", err.Error())
+		return
+	}
+	defer getResp.Body.Close()
+
+	var entry struct {
+		Value string `json:"value"`
+	}
+	json.NewDecoder(getResp.Body).Decode(&entry)
+	if entry.Value != "first" {
+		failTest(results, "Idempotent Put", fmt.Sprintf("Expected value 'first', got '%s'", entry.Value))
+		return
+	}
+
+	fmt.Printf("✅ Idempotent Put Passed - Status: %d\n", resp.StatusCode)
+	passTest(results)
+}
+
+func testRename(results *TestResults) {
+	fmt.Println("\n📋 Test 18: Rename Key")
+
+	for _, key := range []string{"rename:from", "rename:taken"} {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": key})
+		if err != nil {
+			failTest(results, "Rename Key", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	cases := []struct {
+		from, to string
+		status   int
+	}{
+		{"rename:from", "rename:taken", http.StatusConflict},
+		{"rename:from", "rename:to", http.StatusOK},
+		{"rename:from", "rename:again", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		resp, err := doJSON("POST", "/rename", map[string]interface{}{"from": tc.from, "to": tc.to})
+		if err != nil {
+			failTest(results, "Rename Key", err.Error())
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.status {
+			failTest(results, "Rename Key", fmt.Sprintf("%s -> %s: expected %d, got %d", tc.from, tc.to, tc.status, resp.StatusCode))
+			return
+		}
+	}
+
+	resp, err := http.Get(baseURL + "/get/rename:to")
+	if err != nil {
+		failTest(results, "Rename Key", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		failTest(results, "Rename Key", fmt.Sprintf("Expected renamed key to exist, got %d", resp.StatusCode))
+		return
+	}
+
+	fmt.Printf("✅ Rename Key Passed - Status: %d\n", resp.StatusCode)
+	passTest(results)
+}
+
+func testGetMsgPack(results *TestResults) {
+	fmt.Println("\n📋 Test 19: Get as MessagePack")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "msgpack:1", "value": "compact"})
+	if err != nil {
+		failTest(results, "Get as MessagePack", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest("GET", baseURL+"/get/msgpack:1", nil)
+	req.Header.Set("Accept", "application/msgpack")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		failTest(results, "Get as MessagePack", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(contentType, "application/msgpack") {
+		failTest(results, "Get as MessagePack", fmt.Sprintf("Expected 200 msgpack, got %d %s", resp.StatusCode, contentType))
+		return
+	}
+
+	fmt.Printf("✅ Get as MessagePack Passed - Content-Type: %s\n", contentType)
+	passTest(results)
+}
+
+func testPing(results *TestResults) {
+	fmt.Println("\n📋 Test 20: Ping")
+
+	resp, err := http.Get(baseURL + "/ping")
+	if err != nil {
+		failTest(results, "Ping", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "pong" {
+		failTest(results, "Ping", fmt.Sprintf("Expected 200 pong, got %d %s", resp.StatusCode, string(body)))
+		return
+	}
+
+	fmt.Printf("✅ Ping Passed - Status: %d\n", resp.StatusCode)
+	passTest(results)
+}
+
+func testOpLog(results *TestResults) {
+	fmt.Println("\n📋 Test 21: Operation Log")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "oplog:1", "value": "audited"})
+	if err != nil {
+		failTest(results, "Operation Log", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest("DELETE", baseURL+"/delete/oplog:1", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		failTest(results, "Operation Log", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(baseURL + "/oplog")
+	if err != nil {
+		failTest(results, "Operation Log", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	var oplog struct {
+		Entries []struct {
+			Op  string `json:"op"`
+			Key string `json:"key"`
+		} `json:"entries"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&oplog); err != nil {
+		failTest(results, "Operation Log", err.Error())
+		return
+	}
+
+	// Newest first: the delete precedes the put that created the key
+	if len(oplog.Entries) < 2 ||
+		oplog.Entries[0].Op != "delete" || oplog.Entries[0].Key != "oplog:1" ||
+		oplog.Entries[1].Op != "put" || oplog.Entries[1].Key != "oplog:1" {
+		failTest(results, "Operation Log", fmt.Sprintf("Unexpected oplog head: %+v", oplog.Entries))
+		return
+	}
+
+	fmt.Printf("✅ Operation Log Passed - Entries: %d\n", len(oplog.Entries))
+	passTest(results)
+}
+
+func testStatsStream(results *TestResults) {
+	fmt.Println("\n📋 Test 22: Stats Stream")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(baseURL + "/stats/stream?interval=1")
+	if err != nil {
+		failTest(results, "Stats Stream", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		failTest(results, "Stats Stream", fmt.Sprintf("Expected 200, got %d", resp.StatusCode))
+		return
+	}
+
+	// Read until two stats frames have arrived
+	frames := 0
+	scanner := bufio.NewScanner(resp.Body)
+	for frames < 2 && scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "data:") {
+			frames++
+		}
+	}
+
+	if frames < 2 {
+		failTest(results, "Stats Stream", fmt.Sprintf("Expected 2 frames, got %d", frames))
+		return
+	}
+
+	fmt.Printf("✅ Stats Stream Passed - Frames: %d\n", frames)
+	passTest(results)
+}
+
+func testPutExpireAt(results *TestResults) {
+	fmt.Println("\n📋 Test 23: Put With Expire At")
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Unix()
+
+	cases := []struct {
+		body      map[string]interface{}
+		putStatus int
+		getStatus int
+	}{
+		{map[string]interface{}{"key": "expireat:future", "value": "v", "expire_at": future}, http.StatusCreated, http.StatusOK},
+		{map[string]interface{}{"key": "expireat:past", "value": "v", "expire_at": past}, http.StatusCreated, http.StatusNotFound},
+		{map[string]interface{}{"key": "expireat:both", "value": "v", "expire_at": future, "ttl": 60}, http.StatusBadRequest, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		key := tc.body["key"].(string)
+
+		resp, err := doJSON("PUT", "/put", tc.body)
+		if err != nil {
+			failTest(results, "Put With Expire At", err.Error())
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.putStatus {
+			failTest(results, "Put With Expire At", fmt.Sprintf("%s: expected put %d, got %d", key, tc.putStatus, resp.StatusCode))
+			return
+		}
+
+		resp, err = http.Get(baseURL + "/get/" + key)
+		if err != nil {
+			failTest(results, "Put With Expire At", err.Error())
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.getStatus {
+			failTest(results, "Put With Expire At", fmt.Sprintf("%s: expected get %d, got %d", key, tc.getStatus, resp.StatusCode))
+			return
+		}
+	}
+
+	fmt.Println("✅ Put With Expire At Passed")
+	passTest(results)
+}
+
+func testHTTPStats(results *TestResults) {
+	fmt.Println("\n📋 Test 24: HTTP Stats")
+
+	// routeCounts fetches the per-route request counts
+	routeCounts := func() (map[string]int64, error) {
+		resp, err := http.Get(baseURL + "/http-stats")
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var stats struct {
+			Routes []struct {
+				Route string `json:"route"`
+				Count int64  `json:"count"`
+			} `json:"routes"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			return nil, err
+		}
+
+		counts := make(map[string]int64)
+		for _, route := range stats.Routes {
+			counts[route.Route] = route.Count
+		}
+		return counts, nil
+	}
+
+	before, err := routeCounts()
+	if err != nil {
+		failTest(results, "HTTP Stats", err.Error())
+		return
+	}
+
+	for _, path := range []string{"/ping", "/ping", "/size"} {
+		resp, err := http.Get(baseURL + path)
+		if err != nil {
+			failTest(results, "HTTP Stats", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	after, err := routeCounts()
+	if err != nil {
+		failTest(results, "HTTP Stats", err.Error())
+		return
+	}
+
+	expected := map[string]int64{
+		"GET /api/cache/ping": 2,
+		"GET /api/cache/size": 1,
+	}
+	for route, delta := range expected {
+		if after[route]-before[route] != delta {
+			failTest(results, "HTTP Stats", fmt.Sprintf("%s: expected +%d, got +%d", route, delta, after[route]-before[route]))
+			return
+		}
+	}
+
+	fmt.Println("✅ HTTP Stats Passed")
+	passTest(results)
+}
+
+func testBulkPutBatchTTL(results *TestResults) {
+	fmt.Println("\n📋 Test 25: Bulk Put Batch TTL")
+
+	resp, err := doJSON("POST", "/bulk/put", map[string]interface{}{
+		"ttl": 120,
+		"items": []map[string]interface{}{
+			{"key": "batchttl:inherit", "value": "a"},
+			{"key": "batchttl:override", "value": "b", "ttl": 3600},
+		},
+	})
+	if err != nil {
+		failTest(results, "Bulk Put Batch TTL", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	expected := map[string]struct{ min, max int64 }{
+		"batchttl:inherit":  {100, 120},
+		"batchttl:override": {3500, 3600},
+	}
+	for key, bounds := range expected {
+		resp, err := http.Get(baseURL + "/info/" + key)
+		if err != nil {
+			failTest(results, "Bulk Put Batch TTL", err.Error())
+			return
+		}
+
+		var info struct {
+			TTL int64 `json:"ttl"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&info)
+		resp.Body.Close()
+		if err != nil {
+			failTest(results, "Bulk Put Batch TTL", err.Error())
+			return
+		}
+
+		if info.TTL < bounds.min || info.TTL > bounds.max {
+			failTest(results, "Bulk Put Batch TTL", fmt.Sprintf("%s: expected ttl in [%d, %d], got %d", key, bounds.min, bounds.max, info.TTL))
+			return
+		}
+	}
+
+	fmt.Println("✅ Bulk Put Batch TTL Passed")
+	passTest(results)
+}
+
+func testDeleteByPattern(results *TestResults) {
+	fmt.Println("\n📋 Test 26: Delete By Pattern")
+
+	keys := []string{"pattern:1:temp", "pattern:2:temp", "pattern:3:keep"}
+	for _, key := range keys {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": key})
+		if err != nil {
+			failTest(results, "Delete By Pattern", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := doJSON("DELETE", "/pattern?p=pattern:*:temp", nil)
+	if err != nil {
+		failTest(results, "Delete By Pattern", err.Error())
+		return
+	}
+
+	var result struct {
+		Deleted int `json:"deleted"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	resp.Body.Close()
+	if err != nil {
+		failTest(results, "Delete By Pattern", err.Error())
+		return
+	}
+
+	if result.Deleted != 2 {
+		failTest(results, "Delete By Pattern", fmt.Sprintf("Expected 2 deleted, got %d", result.Deleted))
+		return
+	}
+
+	expected := map[string]int{
+		"pattern:1:temp": http.StatusNotFound,
+		"pattern:2:temp": http.StatusNotFound,
+		"pattern:3:keep": http.StatusOK,
+	}
+	for key, status := range expected {
+		resp, err := http.Get(baseURL + "/get/" + key)
+		if err != nil {
+			failTest(results, "Delete By Pattern", err.Error())
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != status {
+			failTest(results, "Delete By Pattern", fmt.Sprintf("%s: expected %d, got %d", key, status, resp.StatusCode))
+			return
+		}
+	}
+
+	// An empty pattern is rejected
+	resp, err = doJSON("DELETE", "/pattern", nil)
+	if err != nil {
+		failTest(results, "Delete By Pattern", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		failTest(results, "Delete By Pattern", fmt.Sprintf("Expected 400 for empty pattern, got %d", resp.StatusCode))
+		return
+	}
+
+	fmt.Printf("✅ Delete By Pattern Passed - Deleted: %d\n", result.Deleted)
+	passTest(results)
+}
+
+func testTTLHistogram(results *TestResults) {
+	fmt.Println("\n📋 Test 27: TTL Histogram")
+
+	// ttlBuckets fetches the TTL distribution from detailed stats
+	ttlBuckets := func() (map[string]int, error) {
+		resp, err := http.Get(baseURL + "/stats?detailed=true")
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var stats struct {
+			TTLBuckets map[string]int `json:"ttl_buckets"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			return nil, err
+		}
+		return stats.TTLBuckets, nil
+	}
+
+	before, err := ttlBuckets()
+	if err != nil {
+		failTest(results, "TTL Histogram", err.Error())
+		return
+	}
+
+	ttls := map[string]int{
+		"histogram:short":  30,
+		"histogram:medium": 300,
+		"histogram:long":   1200,
+		"histogram:day":    86400,
+	}
+	for key, ttl := range ttls {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": key, "ttl": ttl})
+		if err != nil {
+			failTest(results, "TTL Histogram", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	after, err := ttlBuckets()
+	if err != nil {
+		failTest(results, "TTL Histogram", err.Error())
+		return
+	}
+
+	for _, bucket := range []string{"<1m", "1-10m", "10m-1h", ">1h"} {
+		if after[bucket]-before[bucket] != 1 {
+			failTest(results, "TTL Histogram", fmt.Sprintf("%s: expected +1, got +%d", bucket, after[bucket]-before[bucket]))
+			return
+		}
+	}
+
+	fmt.Printf("✅ TTL Histogram Passed - Buckets: %v\n", after)
+	passTest(results)
+}
+
+func testJSONPathRead(results *TestResults) {
+	fmt.Println("\n📋 Test 28: JSONPath Read")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{
+		"key": "jsonpath:1",
+		"value": map[string]interface{}{
+			"user": map[string]interface{}{"name": "Alice", "tags": []string{"admin", "ops"}},
+		},
+	})
+	if err != nil {
+		failTest(results, "JSONPath Read", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	cases := []struct {
+		path   string
+		status int
+		value  interface{}
+	}{
+		{"$.user.name", http.StatusOK, "Alice"},
+		{"$.user.tags[1]", http.StatusOK, "ops"},
+		{"$.user.email", http.StatusNotFound, nil},
+		{"user.name", http.StatusBadRequest, nil},
+	}
+	for _, tc := range cases {
+		resp, err := http.Get(baseURL + "/get/jsonpath:1?path=" + url.QueryEscape(tc.path))
+		if err != nil {
+			failTest(results, "JSONPath Read", err.Error())
+			return
+		}
+
+		var result struct {
+			Value interface{} `json:"value"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			failTest(results, "JSONPath Read", err.Error())
+			return
+		}
+
+		if resp.StatusCode != tc.status || result.Value != tc.value {
+			failTest(results, "JSONPath Read", fmt.Sprintf("%s: expected %d %v, got %d %v", tc.path, tc.status, tc.value, resp.StatusCode, result.Value))
+			return
+		}
+	}
+
+	fmt.Println("✅ JSONPath Read Passed")
+	passTest(results)
+}
+
+func testPubSub(results *TestResults) {
+	fmt.Println("\n📋 Test 29: Publish Subscribe")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(baseURL + "/subscribe/pubsub:news")
+	if err != nil {
+		failTest(results, "Publish Subscribe", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	// Wait for the subscription to be registered before publishing
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() && scanner.Text() != "event:subscribed" {
+	}
+
+	pub, err := doJSON("POST", "/publish/pubsub:news", map[string]interface{}{"message": "hello"})
+	if err != nil {
+		failTest(results, "Publish Subscribe", err.Error())
+		return
+	}
+
+	var published struct {
+		Subscribers int `json:"subscribers"`
+	}
+	err = json.NewDecoder(pub.Body).Decode(&published)
+	pub.Body.Close()
+	if err != nil {
+		failTest(results, "Publish Subscribe", err.Error())
+		return
+	}
+
+	if published.Subscribers != 1 {
+		failTest(results, "Publish Subscribe", fmt.Sprintf("Expected 1 subscriber, got %d", published.Subscribers))
+		return
+	}
+
+	received := ""
+	for scanner.Scan() {
+		if scanner.Text() == "event:message" && scanner.Scan() {
+			received = strings.TrimPrefix(scanner.Text(), "data:")
+			break
+		}
+	}
+
+	if received != "hello" {
+		failTest(results, "Publish Subscribe", fmt.Sprintf("Expected message 'hello', got '%s'", received))
+		return
+	}
+
+	fmt.Printf("✅ Publish Subscribe Passed - Received: %s\n", received)
+	passTest(results)
+}
+
+func testGetDefault(results *TestResults) {
+	fmt.Println("\n📋 Test 30: Get With Default")
+
+	cases := []struct {
+		value   string
+		status  int
+		created bool
+	}{
+		// First call stores the default, second returns it and ignores the new default
+		{"first", http.StatusCreated, true},
+		{"second", http.StatusOK, false},
+	}
+	for _, tc := range cases {
+		resp, err := doJSON("POST", "/getdefault/getdefault:1", map[string]interface{}{"default": tc.value, "ttl": 60})
+		if err != nil {
+			failTest(results, "Get With Default", err.Error())
+			return
+		}
+
+		var result struct {
+			Value   string `json:"value"`
+			Created bool   `json:"created"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			failTest(results, "Get With Default", err.Error())
+			return
+		}
+
+		if resp.StatusCode != tc.status || result.Created != tc.created || result.Value != "first" {
+			failTest(results, "Get With Default", fmt.Sprintf("default %s: got %d created=%v value=%s", tc.value, resp.StatusCode, result.Created, result.Value))
+			return
+		}
+	}
+
+	fmt.Println("✅ Get With Default Passed")
+	passTest(results)
+}
+
+func testCORS(results *TestResults) {
+	fmt.Println("\n📋 Test 31: CORS")
+
+	cases := []struct {
+		method, origin string
+		status         int
+		allowOrigin    string
+	}{
+		{"GET", corsAllowedOrigin, http.StatusOK, corsAllowedOrigin},
+		{"GET", "http://evil.example", http.StatusOK, ""},
+		{"OPTIONS", corsAllowedOrigin, http.StatusNoContent, corsAllowedOrigin},
+		{"OPTIONS", "http://evil.example", http.StatusForbidden, ""},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(tc.method, baseURL+"/ping", nil)
+		req.Header.Set("Origin", tc.origin)
+		if tc.method == "OPTIONS" {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			failTest(results, "CORS", err.Error())
+			return
+		}
+		resp.Body.Close()
+
+		allowOrigin := resp.Header.Get("Access-Control-Allow-Origin")
+		if resp.StatusCode != tc.status || allowOrigin != tc.allowOrigin {
+			failTest(results, "CORS", fmt.Sprintf("%s from %s: expected %d '%s', got %d '%s'", tc.method, tc.origin, tc.status, tc.allowOrigin, resp.StatusCode, allowOrigin))
+			return
+		}
+	}
+
+	fmt.Println("✅ CORS Passed")
+	passTest(results)
+}
+
+func testStaleWhileRevalidate(results *TestResults) {
+	fmt.Println("\n📋 Test 32: Stale While Revalidate")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "swr:1", "value": "old", "ttl": 1})
+	if err != nil {
+		failTest(results, "Stale While Revalidate", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	// Expired after ~2s, past the 2s grace window after ~4s
+	cases := []struct {
+		wait   time.Duration
+		status int
+		stale  bool
+	}{
+		{2200 * time.Millisecond, http.StatusOK, true},
+		{2200 * time.Millisecond, http.StatusNotFound, false},
+	}
+	for _, tc := range cases {
+		time.Sleep(tc.wait)
+
+		resp, err := http.Get(baseURL + "/get/swr:1")
+		if err != nil {
+			failTest(results, "Stale While Revalidate", err.Error())
+			return
+		}
+
+		var result struct {
+			Stale bool `json:"stale"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			failTest(results, "Stale While Revalidate", err.Error())
+			return
+		}
+
+		if resp.StatusCode != tc.status || result.Stale != tc.stale {
+			failTest(results, "Stale While Revalidate", fmt.Sprintf("expected %d stale=%v, got %d stale=%v", tc.status, tc.stale, resp.StatusCode, result.Stale))
+			return
+		}
+	}
+
+	fmt.Println("✅ Stale While Revalidate Passed")
+	passTest(results)
+}
+
+func testCountKeys(results *TestResults) {
+	fmt.Println("\n📋 Test 33: Count Keys")
+
+	// countKeys fetches the key count for a prefix
+	countKeys := func(prefix string) (int, error) {
+		resp, err := http.Get(baseURL + "/keys/count?prefix=" + url.QueryEscape(prefix))
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		var result struct {
+			Count int `json:"count"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return 0, err
+		}
+		return result.Count, nil
+	}
+
+	allBefore, err := countKeys("")
+	if err != nil {
+		failTest(results, "Count Keys", err.Error())
+		return
+	}
+
+	for _, key := range []string{"countkeys:user:1", "countkeys:user:2", "countkeys:user:3", "countkeys:order:1"} {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": key})
+		if err != nil {
+			failTest(results, "Count Keys", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	users, err := countKeys("countkeys:user:")
+	if err != nil {
+		failTest(results, "Count Keys", err.Error())
+		return
+	}
+	allAfter, err := countKeys("")
+	if err != nil {
+		failTest(results, "Count Keys", err.Error())
+		return
+	}
+
+	if users != 3 || allAfter-allBefore != 4 {
+		failTest(results, "Count Keys", fmt.Sprintf("Expected 3 user keys and 4 new keys, got %d and %d", users, allAfter-allBefore))
+		return
+	}
+
+	fmt.Printf("✅ Count Keys Passed - Prefix: %d, All: %d\n", users, allAfter)
+	passTest(results)
+}
+
+func testConditionalDelete(results *TestResults) {
+	fmt.Println("\n📋 Test 34: Conditional Delete")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "deleteif:1", "value": map[string]interface{}{"version": 2}})
+	if err != nil {
+		failTest(results, "Conditional Delete", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	cases := []struct {
+		key      string
+		expected interface{}
+		status   int
+	}{
+		{"deleteif:1", map[string]interface{}{"version": 1}, http.StatusConflict},
+		{"deleteif:1", map[string]interface{}{"version": 2}, http.StatusOK},
+		{"deleteif:1", map[string]interface{}{"version": 2}, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		resp, err := doJSON("DELETE", "/delete/"+tc.key, map[string]interface{}{"expected": tc.expected})
+		if err != nil {
+			failTest(results, "Conditional Delete", err.Error())
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.status {
+			failTest(results, "Conditional Delete", fmt.Sprintf("expected %v: expected %d, got %d", tc.expected, tc.status, resp.StatusCode))
+			return
+		}
+	}
+
+	fmt.Println("✅ Conditional Delete Passed")
+	passTest(results)
+}
+
+func testTTLPromotion(results *TestResults) {
+	fmt.Println("\n📋 Test 35: TTL Promotion")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "promote:1", "value": "hot", "ttl": 5})
+	if err != nil {
+		failTest(results, "TTL Promotion", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	// remainingTTL reads the key's TTL without promoting it
+	remainingTTL := func() (int64, error) {
+		resp, err := http.Get(baseURL + "/info/promote:1")
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+
+		var info struct {
+			TTL int64 `json:"ttl"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+			return 0, err
+		}
+		return info.TTL, nil
+	}
+
+	previous, err := remainingTTL()
+	if err != nil {
+		failTest(results, "TTL Promotion", err.Error())
+		return
+	}
+
+	// Each read adds the 10s step until the 60s cap is reached
+	var ttl int64
+	for i := 0; i < 10; i++ {
+		resp, err := http.Get(baseURL + "/get/promote:1")
+		if err != nil {
+			failTest(results, "TTL Promotion", err.Error())
+			return
+		}
+		resp.Body.Close()
+
+		if ttl, err = remainingTTL(); err != nil {
+			failTest(results, "TTL Promotion", err.Error())
+			return
+		}
+		if ttl < previous || ttl > 60 {
+			failTest(results, "TTL Promotion", fmt.Sprintf("read %d: ttl went from %d to %d", i+1, previous, ttl))
+			return
+		}
+		if i < 4 && ttl <= previous {
+			failTest(results, "TTL Promotion", fmt.Sprintf("read %d: ttl did not grow from %d", i+1, previous))
+			return
+		}
+		previous = ttl
+	}
+
+	if ttl < 59 {
+		failTest(results, "TTL Promotion", fmt.Sprintf("Expected ttl near the 60s cap, got %d", ttl))
+		return
+	}
+
+	fmt.Printf("✅ TTL Promotion Passed - TTL: %d\n", ttl)
+	passTest(results)
+}
+
+func testDiagnostics(results *TestResults) {
+	fmt.Println("\n📋 Test 36: Diagnostics")
+
+	resp, err := http.Get(baseURL + "/diagnostics")
+	if err != nil {
+		failTest(results, "Diagnostics", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	var report struct {
+		Healthy bool `json:"healthy"`
+		Checks  []struct {
+			Name   string `json:"name"`
+			Passed bool   `json:"passed"`
+			Detail string `json:"detail"`
+		} `json:"checks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		failTest(results, "Diagnostics", err.Error())
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK || !report.Healthy || len(report.Checks) == 0 {
+		failTest(results, "Diagnostics", fmt.Sprintf("Expected healthy report, got %d %+v", resp.StatusCode, report))
+		return
+	}
+
+	fmt.Printf("✅ Diagnostics Passed - Checks: %d\n", len(report.Checks))
+	passTest(results)
+}
+
+func testGzipCompression(results *TestResults) {
+	fmt.Println("\n📋 Test 37: Gzip Compression")
+
+	large := strings.Repeat("compressible ", 1000)
+	for key, value := range map[string]string{"gzip:large": large, "gzip:small": "tiny"} {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": value})
+		if err != nil {
+			failTest(results, "Gzip Compression", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	// Setting Accept-Encoding explicitly stops the transport from decompressing transparently
+	get := func(key string) (*http.Response, error) {
+		req, err := http.NewRequest("GET", baseURL+"/get/"+key, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept-Encoding", "gzip")
+		return http.DefaultClient.Do(req)
+	}
+
+	resp, err := get("gzip:large")
+	if err != nil {
+		failTest(results, "Gzip Compression", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		failTest(results, "Gzip Compression", fmt.Sprintf("Expected gzip encoding for large value, got %q", resp.Header.Get("Content-Encoding")))
+		return
+	}
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		failTest(results, "Gzip Compression", err.Error())
+		return
+	}
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(reader).Decode(&body); err != nil {
+		failTest(results, "Gzip Compression", err.Error())
+		return
+	}
+	if body.Value != large {
+		failTest(results, "Gzip Compression", "Decompressed value does not match")
+		return
+	}
+
+	small, err := get("gzip:small")
+	if err != nil {
+		failTest(results, "Gzip Compression", err.Error())
+		return
+	}
+	small.Body.Close()
+
+	if encoding := small.Header.Get("Content-Encoding"); encoding != "" {
+		failTest(results, "Gzip Compression", fmt.Sprintf("Expected small response uncompressed, got %q", encoding))
+		return
+	}
+
+	fmt.Println("✅ Gzip Compression Passed")
+	passTest(results)
+}
+
+func testBulkGetTTL(results *TestResults) {
+	fmt.Println("\n📋 Test 38: Bulk Get TTL")
+
+	for key, ttl := range map[string]int{"ttl:short": 120, "ttl:default": 0} {
+		item := map[string]interface{}{"key": key, "value": "v"}
+		if ttl > 0 {
+			item["ttl"] = ttl
+		}
+		resp, err := doJSON("PUT", "/put", item)
+		if err != nil {
+			failTest(results, "Bulk Get TTL", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := doJSON("POST", "/bulk/ttl", map[string]interface{}{
+		"keys": []string{"ttl:short", "ttl:default", "ttl:missing"},
+	})
+	if err != nil {
+		failTest(results, "Bulk Get TTL", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		TTLs map[string]int64 `json:"ttls"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		failTest(results, "Bulk Get TTL", err.Error())
+		return
+	}
+
+	// The test server runs with a 30m default TTL, so no key is persistent here
+	if ttl := body.TTLs["ttl:short"]; ttl < 119 || ttl > 120 {
+		failTest(results, "Bulk Get TTL", fmt.Sprintf("Expected ttl:short near 120, got %d", ttl))
+		return
+	}
+	if ttl := body.TTLs["ttl:default"]; ttl < 1790 || ttl > 1800 {
+		failTest(results, "Bulk Get TTL", fmt.Sprintf("Expected ttl:default near 1800, got %d", ttl))
+		return
+	}
+	if ttl := body.TTLs["ttl:missing"]; ttl != -2 {
+		failTest(results, "Bulk Get TTL", fmt.Sprintf("Expected -2 for missing key, got %d", ttl))
+		return
+	}
+
+	// The lookup must not count as an access
+	info, err := http.Get(baseURL + "/info/ttl:short")
+	if err != nil {
+		failTest(results, "Bulk Get TTL", err.Error())
+		return
+	}
+	defer info.Body.Close()
+
+	var keyInfo struct {
+		AccessCount int64 `json:"access_count"`
+	}
+	if err := json.NewDecoder(info.Body).Decode(&keyInfo); err != nil {
+		failTest(results, "Bulk Get TTL", err.Error())
+		return
+	}
+	if keyInfo.AccessCount != 0 {
+		failTest(results, "Bulk Get TTL", fmt.Sprintf("Expected no accesses, got %d", keyInfo.AccessCount))
+		return
+	}
+
+	fmt.Printf("✅ Bulk Get TTL Passed - TTLs: %v\n", body.TTLs)
+	passTest(results)
+}
+
+func testLatencyPercentiles(results *TestResults) {
+	fmt.Println("\n📋 Test 39: Latency Percentiles")
+
+	for i := 0; i < 20; i++ {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": fmt.Sprintf("latency:%d", i), "value": i})
+		if err != nil {
+			failTest(results, "Latency Percentiles", err.Error())
+			return
+		}
+		resp.Body.Close()
+
+		if resp, err = http.Get(fmt.Sprintf("%s/get/latency:%d", baseURL, i)); err != nil {
+			failTest(results, "Latency Percentiles", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(baseURL + "/latency")
+	if err != nil {
+		failTest(results, "Latency Percentiles", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Operations map[string]struct {
+			Count int64  `json:"count"`
+			P50   string `json:"p50"`
+			P95   string `json:"p95"`
+			P99   string `json:"p99"`
+		} `json:"operations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		failTest(results, "Latency Percentiles", err.Error())
+		return
+	}
+
+	for _, op := range []string{"get", "put"} {
+		stats, ok := body.Operations[op]
+		if !ok || stats.Count < 20 {
+			failTest(results, "Latency Percentiles", fmt.Sprintf("Expected at least 20 %s operations, got %+v", op, stats))
+			return
+		}
+		p50, err50 := time.ParseDuration(stats.P50)
+		p95, err95 := time.ParseDuration(stats.P95)
+		p99, err99 := time.ParseDuration(stats.P99)
+		if err50 != nil || err95 != nil || err99 != nil || p50 > p95 || p95 > p99 {
+			failTest(results, "Latency Percentiles", fmt.Sprintf("Bad %s percentiles: %+v", op, stats))
+			return
+		}
+	}
+
+	fmt.Printf("✅ Latency Percentiles Passed - Get p99: %s, Put p99: %s\n", body.Operations["get"].P99, body.Operations["put"].P99)
+	passTest(results)
+}
+
+func testPreserveTTL(results *TestResults) {
+	fmt.Println("\n📋 Test 40: Preserve TTL")
+
+	type keyInfo struct {
+		TTL       int64     `json:"ttl"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	// putAndInspect stores the item and returns the key's TTL and creation time
+	putAndInspect := func(item map[string]interface{}) (keyInfo, error) {
+		var info keyInfo
+		resp, err := doJSON("PUT", "/put", item)
+		if err != nil {
+			return info, err
+		}
+		resp.Body.Close()
+
+		if resp, err = http.Get(fmt.Sprintf("%s/info/%s", baseURL, item["key"])); err != nil {
+			return info, err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&info)
+		return info, err
+	}
+
+	original, err := putAndInspect(map[string]interface{}{"key": "preserve:1", "value": "v1", "ttl": 100})
+	if err != nil {
+		failTest(results, "Preserve TTL", err.Error())
+		return
+	}
+
+	preserved, err := putAndInspect(map[string]interface{}{"key": "preserve:1", "value": "v2", "ttl": 1000, "preserve_ttl": true})
+	if err != nil {
+		failTest(results, "Preserve TTL", err.Error())
+		return
+	}
+	if preserved.TTL > 100 || !preserved.CreatedAt.Equal(original.CreatedAt) {
+		failTest(results, "Preserve TTL", fmt.Sprintf("Expected ttl <= 100 and unchanged created_at, got %+v (was %+v)", preserved, original))
+		return
+	}
+
+	resp, err := http.Get(baseURL + "/get/preserve:1")
+	if err != nil {
+		failTest(results, "Preserve TTL", err.Error())
+		return
+	}
+	var got struct {
+		Value string `json:"value"`
+	}
+	json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if got.Value != "v2" {
+		failTest(results, "Preserve TTL", fmt.Sprintf("Expected updated value v2, got %q", got.Value))
+		return
+	}
+
+	reset, err := putAndInspect(map[string]interface{}{"key": "preserve:1", "value": "v3", "ttl": 1000})
+	if err != nil {
+		failTest(results, "Preserve TTL", err.Error())
+		return
+	}
+	if reset.TTL < 999 {
+		failTest(results, "Preserve TTL", fmt.Sprintf("Expected plain Put to reset ttl to 1000, got %d", reset.TTL))
+		return
+	}
+
+	// A new key has nothing to preserve, so its ttl applies
+	fresh, err := putAndInspect(map[string]interface{}{"key": "preserve:2", "value": "v", "ttl": 50, "preserve_ttl": true})
+	if err != nil {
+		failTest(results, "Preserve TTL", err.Error())
+		return
+	}
+	if fresh.TTL < 49 || fresh.TTL > 50 {
+		failTest(results, "Preserve TTL", fmt.Sprintf("Expected new key ttl 50, got %d", fresh.TTL))
+		return
+	}
+
+	fmt.Printf("✅ Preserve TTL Passed - Preserved: %d, Reset: %d\n", preserved.TTL, reset.TTL)
+	passTest(results)
+}
+
+func testLastModified(results *TestResults) {
+	fmt.Println("\n📋 Test 41: Last-Modified")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "modified:1", "value": "v1"})
+	if err != nil {
+		failTest(results, "Last-Modified", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	// getSince fetches the key, sending If-Modified-Since when since is non-empty
+	getSince := func(since string) (*http.Response, error) {
+		req, err := http.NewRequest("GET", baseURL+"/get/modified:1", nil)
+		if err != nil {
+			return nil, err
+		}
+		if since != "" {
+			req.Header.Set("If-Modified-Since", since)
+		}
+		return http.DefaultClient.Do(req)
+	}
+
+	resp, err = getSince("")
+	if err != nil {
+		failTest(results, "Last-Modified", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	lastModified := resp.Header.Get("Last-Modified")
+	if resp.StatusCode != http.StatusOK || lastModified == "" {
+		failTest(results, "Last-Modified", fmt.Sprintf("Expected 200 with Last-Modified, got %d %q", resp.StatusCode, lastModified))
+		return
+	}
+
+	if resp, err = getSince(lastModified); err != nil {
+		failTest(results, "Last-Modified", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		failTest(results, "Last-Modified", fmt.Sprintf("Expected 304 for unchanged value, got %d", resp.StatusCode))
+		return
+	}
+
+	// A write in a later second makes the conditional request succeed again
+	time.Sleep(1100 * time.Millisecond)
+	if resp, err = doJSON("PUT", "/put", map[string]interface{}{"key": "modified:1", "value": "v2"}); err != nil {
+		failTest(results, "Last-Modified", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	if resp, err = getSince(lastModified); err != nil {
+		failTest(results, "Last-Modified", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Last-Modified") == lastModified {
+		failTest(results, "Last-Modified", fmt.Sprintf("Expected 200 with a newer Last-Modified after update, got %d", resp.StatusCode))
+		return
+	}
+
+	fmt.Printf("✅ Last-Modified Passed - %s\n", lastModified)
+	passTest(results)
+}
+
+func testAsyncBulkPut(results *TestResults) {
+	fmt.Println("\n📋 Test 42: Async Bulk Put")
+
+	items := make([]map[string]interface{}, 250)
+	for i := range items {
+		items[i] = map[string]interface{}{"key": fmt.Sprintf("async:%d", i), "value": i}
+	}
+	// One conflicting item makes sure failures are reported in the job
+	items[249]["ttl"] = 60
+	items[249]["expire_at"] = 4102444800
+
+	resp, err := doJSON("POST", "/bulk/put?async=true", map[string]interface{}{"items": items})
+	if err != nil {
+		failTest(results, "Async Bulk Put", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	type job struct {
+		ID         string `json:"id"`
+		Status     string `json:"status"`
+		Total      int    `json:"total"`
+		Processed  int    `json:"processed"`
+		Successful int    `json:"successful"`
+		Failed     int    `json:"failed"`
+	}
+	var submitted job
+	if err := json.NewDecoder(resp.Body).Decode(&submitted); err != nil {
+		failTest(results, "Async Bulk Put", err.Error())
+		return
+	}
+	if resp.StatusCode != http.StatusAccepted || submitted.ID == "" || submitted.Total != 250 {
+		failTest(results, "Async Bulk Put", fmt.Sprintf("Expected 202 with a job for 250 items, got %d %+v", resp.StatusCode, submitted))
+		return
+	}
+
+	var polled job
+	for i := 0; i < 50 && polled.Status != "completed"; i++ {
+		time.Sleep(20 * time.Millisecond)
+		resp, err := http.Get(baseURL + "/bulk/jobs/" + submitted.ID)
+		if err != nil {
+			failTest(results, "Async Bulk Put", err.Error())
+			return
+		}
+		err = json.NewDecoder(resp.Body).Decode(&polled)
+		resp.Body.Close()
+		if err != nil {
+			failTest(results, "Async Bulk Put", err.Error())
+			return
+		}
+	}
+
+	if polled.Status != "completed" || polled.Processed != 250 || polled.Successful != 249 || polled.Failed != 1 {
+		failTest(results, "Async Bulk Put", fmt.Sprintf("Unexpected final job state: %+v", polled))
+		return
+	}
+
+	resp, err = http.Get(baseURL + "/get/async:123")
+	if err != nil {
+		failTest(results, "Async Bulk Put", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		failTest(results, "Async Bulk Put", fmt.Sprintf("Expected async:123 to be stored, got %d", resp.StatusCode))
+		return
+	}
+
+	fmt.Printf("✅ Async Bulk Put Passed - Job %s: %d/%d\n", polled.ID, polled.Successful, polled.Total)
+	passTest(results)
+}
+
+func testPersistentPut(results *TestResults) {
+	fmt.Println("\n📋 Test 43: Persistent Put")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "persistent:1", "value": "forever", "persistent": true})
+	if err != nil {
+		failTest(results, "Persistent Put", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	resp, err = doJSON("POST", "/bulk/put", map[string]interface{}{
+		"items": []map[string]interface{}{
+			{"key": "persistent:2", "value": "forever", "persistent": true},
+			{"key": "persistent:3", "value": "default"},
+		},
+	})
+	if err != nil {
+		failTest(results, "Persistent Put", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	// The test server has a 30m default TTL, which persistent keys must not inherit
+	resp, err = doJSON("POST", "/bulk/ttl", map[string]interface{}{"keys": []string{"persistent:1", "persistent:2", "persistent:3"}})
+	if err != nil {
+		failTest(results, "Persistent Put", err.Error())
+		return
+	}
+	var body struct {
+		TTLs map[string]int64 `json:"ttls"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if err != nil {
+		failTest(results, "Persistent Put", err.Error())
+		return
+	}
+	if body.TTLs["persistent:1"] != -1 || body.TTLs["persistent:2"] != -1 || body.TTLs["persistent:3"] <= 0 {
+		failTest(results, "Persistent Put", fmt.Sprintf("Expected -1, -1 and a positive ttl, got %v", body.TTLs))
+		return
+	}
+
+	resp, err = doJSON("PUT", "/put", map[string]interface{}{"key": "persistent:4", "value": "v", "persistent": true, "ttl": 60})
+	if err != nil {
+		failTest(results, "Persistent Put", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		failTest(results, "Persistent Put", fmt.Sprintf("Expected 400 for persistent with ttl, got %d", resp.StatusCode))
+		return
+	}
+
+	fmt.Printf("✅ Persistent Put Passed - TTLs: %v\n", body.TTLs)
+	passTest(results)
+}
+
+func testRequestBodyLimit(results *TestResults) {
+	fmt.Println("\n📋 Test 44: Request Body Limit")
+
+	// 2000 items of ~1KB exceed the 1MB bulk limit the test server is configured with
+	padding := strings.Repeat("x", 1024)
+	items := make([]map[string]interface{}, 2000)
+	for i := range items {
+		items[i] = map[string]interface{}{"key": fmt.Sprintf("oversized:%d", i), "value": padding}
+	}
+
+	resp, err := doJSON("POST", "/bulk/put", map[string]interface{}{"items": items})
+	if err != nil {
+		failTest(results, "Request Body Limit", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusRequestEntityTooLarge || body.Code != "REQUEST_TOO_LARGE" {
+		failTest(results, "Request Body Limit", fmt.Sprintf("Expected 413 REQUEST_TOO_LARGE, got %d %q", resp.StatusCode, body.Code))
+		return
+	}
+
+	check, err := http.Get(baseURL + "/get/oversized:0")
+	if err != nil {
+		failTest(results, "Request Body Limit", err.Error())
+		return
+	}
+	check.Body.Close()
+	if check.StatusCode != http.StatusNotFound {
+		failTest(results, "Request Body Limit", fmt.Sprintf("Expected nothing stored from the rejected batch, got %d", check.StatusCode))
+		return
+	}
+
+	fmt.Println("✅ Request Body Limit Passed")
+	passTest(results)
+}
+
+func testScanCursor(results *TestResults) {
+	fmt.Println("\n📋 Test 45: Scan Cursor")
+
+	for i := 0; i < 25; i++ {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": fmt.Sprintf("scan:%d", i), "value": i})
+		if err != nil {
+			failTest(results, "Scan Cursor", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	// Scan skips expired keys, so compare against the live key count
+	resp, err := http.Get(baseURL + "/keys/count")
+	if err != nil {
+		failTest(results, "Scan Cursor", err.Error())
+		return
+	}
+	var live struct {
+		Count int `json:"count"`
+	}
+	json.NewDecoder(resp.Body).Decode(&live)
+	resp.Body.Close()
+
+	seen := make(map[string]int)
+	cursor, pages := "0", 0
+	for {
+		resp, err := http.Get(baseURL + "/scan?count=50&cursor=" + cursor)
+		if err != nil {
+			failTest(results, "Scan Cursor", err.Error())
+			return
+		}
+		var page struct {
+			Keys   []string `json:"keys"`
+			Cursor string   `json:"cursor"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			failTest(results, "Scan Cursor", err.Error())
+			return
+		}
+		if len(page.Keys) > 50 {
+			failTest(results, "Scan Cursor", fmt.Sprintf("Page exceeded count: %d keys", len(page.Keys)))
+			return
+		}
+
+		pages++
+		for _, key := range page.Keys {
+			seen[key]++
+		}
+		if cursor = page.Cursor; cursor == "0" || pages > 1000 {
+			break
+		}
+	}
+
+	for key, times := range seen {
+		if times != 1 {
+			failTest(results, "Scan Cursor", fmt.Sprintf("Key %s returned %d times", key, times))
+			return
+		}
+	}
+	for i := 0; i < 25; i++ {
+		if seen[fmt.Sprintf("scan:%d", i)] != 1 {
+			failTest(results, "Scan Cursor", fmt.Sprintf("scan:%d was never returned", i))
+			return
+		}
+	}
+	if len(seen) != live.Count {
+		failTest(results, "Scan Cursor", fmt.Sprintf("Scanned %d keys but %d are live", len(seen), live.Count))
+		return
+	}
+
+	fmt.Printf("✅ Scan Cursor Passed - %d keys in %d pages\n", len(seen), pages)
+	passTest(results)
+}
+
+func testNextEviction(results *TestResults) {
+	fmt.Println("\n📋 Test 46: Next Eviction")
+
+	type candidate struct {
+		Key   string `json:"key"`
+		Found bool   `json:"found"`
+	}
+	nextEviction := func() (candidate, error) {
+		var next candidate
+		resp, err := http.Get(baseURL + "/next-eviction")
+		if err != nil {
+			return next, err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&next)
+		return next, err
+	}
+
+	first, err := nextEviction()
+	if err != nil {
+		failTest(results, "Next Eviction", err.Error())
+		return
+	}
+	if !first.Found || first.Key == "" {
+		failTest(results, "Next Eviction", fmt.Sprintf("Expected a candidate in a non-empty cache, got %+v", first))
+		return
+	}
+
+	// Reading the candidate makes it most recently used, so another key becomes the victim
+	resp, err := http.Get(baseURL + "/get/" + url.PathEscape(first.Key))
+	if err != nil {
+		failTest(results, "Next Eviction", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	second, err := nextEviction()
+	if err != nil {
+		failTest(results, "Next Eviction", err.Error())
+		return
+	}
+	if resp.StatusCode == http.StatusOK && second.Key == first.Key {
+		failTest(results, "Next Eviction", fmt.Sprintf("Candidate %s did not change after being read", first.Key))
+		return
+	}
+
+	fmt.Printf("✅ Next Eviction Passed - %s, then %s\n", first.Key, second.Key)
+	passTest(results)
+}
+
+func testExpirePersist(results *TestResults) {
+	fmt.Println("\n📋 Test 47: Expire and Persist")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "expire:1", "value": "v", "ttl": 60})
+	if err != nil {
+		failTest(results, "Expire and Persist", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	keyTTL := func() (int64, error) {
+		var info struct {
+			TTL int64 `json:"ttl"`
+		}
+		resp, err := http.Get(baseURL + "/info/expire:1")
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&info)
+		return info.TTL, err
+	}
+
+	resp, err = doJSON("POST", "/expire/expire:1", map[string]interface{}{"ttl": 5000})
+	if err != nil {
+		failTest(results, "Expire and Persist", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if ttl, err := keyTTL(); err != nil || ttl < 4990 || ttl > 5000 {
+		failTest(results, "Expire and Persist", fmt.Sprintf("Expected a TTL near 5000 after expire, got %d (%v)", ttl, err))
+		return
+	}
+
+	resp, err = doJSON("POST", "/persist/expire:1", nil)
+	if err != nil {
+		failTest(results, "Expire and Persist", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if ttl, err := keyTTL(); err != nil || ttl != -1 {
+		failTest(results, "Expire and Persist", fmt.Sprintf("Expected TTL -1 after persist, got %d (%v)", ttl, err))
+		return
+	}
+
+	resp, err = doJSON("POST", "/expire/expire:missing", map[string]interface{}{"ttl": 10})
+	if err != nil {
+		failTest(results, "Expire and Persist", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		failTest(results, "Expire and Persist", fmt.Sprintf("Expected 404 for a missing key, got %d", resp.StatusCode))
+		return
+	}
+
+	fmt.Printf("✅ Expire and Persist Passed - Status: %d\n", resp.StatusCode)
+	passTest(results)
+}
+
+func testBulkExists(results *TestResults) {
+	fmt.Println("\n📋 Test 48: Bulk Exists")
+
+	items := []map[string]interface{}{
+		{"key": "exists:live", "value": "v"},
+		{"key": "exists:expired", "value": "v", "expire_at": time.Now().Add(-time.Minute).Unix()},
+	}
+	for _, item := range items {
+		resp, err := doJSON("PUT", "/put", item)
+		if err != nil {
+			failTest(results, "Bulk Exists", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := doJSON("POST", "/bulk/exists", map[string]interface{}{
+		"keys": []string{"exists:live", "exists:expired", "exists:missing"},
+	})
+	if err != nil {
+		failTest(results, "Bulk Exists", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Exists   map[string]bool `json:"exists"`
+		Found    int             `json:"found"`
+		NotFound int             `json:"not_found"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		failTest(results, "Bulk Exists", err.Error())
+		return
+	}
+
+	if !body.Exists["exists:live"] || body.Exists["exists:expired"] || body.Exists["exists:missing"] {
+		failTest(results, "Bulk Exists", fmt.Sprintf("Unexpected presence map %v", body.Exists))
+		return
+	}
+	if body.Found != 1 || body.NotFound != 2 {
+		failTest(results, "Bulk Exists", fmt.Sprintf("Expected 1 found and 2 not found, got %d and %d", body.Found, body.NotFound))
+		return
+	}
+
+	fmt.Printf("✅ Bulk Exists Passed - Status: %d\n", resp.StatusCode)
+	passTest(results)
+}
+
+func testCleanupControl(results *TestResults) {
+	fmt.Println("\n📋 Test 49: Cleanup Control")
+
+	type cleanupStatus struct {
+		Paused     bool  `json:"paused"`
+		Runs       int64 `json:"runs"`
+		LastReaped int   `json:"last_reaped"`
+	}
+	call := func(method, path string) (cleanupStatus, error) {
+		var status cleanupStatus
+		req, err := http.NewRequest(method, baseURL+path, nil)
+		if err != nil {
+			return status, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return status, err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&status)
+		return status, err
+	}
+
+	paused, err := call("POST", "/cleanup/pause")
+	if err != nil || !paused.Paused {
+		failTest(results, "Cleanup Control", fmt.Sprintf("Expected cleanup to be paused, got %+v (%v)", paused, err))
+		return
+	}
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{
+		"key": "cleanup:expired", "value": "v", "expire_at": time.Now().Add(-time.Minute).Unix(),
+	})
+	if err != nil {
+		failTest(results, "Cleanup Control", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	// A forced run sweeps even while paused
+	run, err := call("POST", "/cleanup/run")
+	if err != nil {
+		failTest(results, "Cleanup Control", err.Error())
+		return
+	}
+	if run.Runs <= paused.Runs || run.LastReaped < 1 || !run.Paused {
+		failTest(results, "Cleanup Control", fmt.Sprintf("Expected a forced run reaping the expired key while paused, got %+v", run))
+		return
+	}
+
+	resumed, err := call("POST", "/cleanup/resume")
+	if err != nil || resumed.Paused {
+		failTest(results, "Cleanup Control", fmt.Sprintf("Expected cleanup to be resumed, got %+v (%v)", resumed, err))
+		return
+	}
+
+	fmt.Printf("✅ Cleanup Control Passed - %d runs, last reaped %d\n", run.Runs, run.LastReaped)
+	passTest(results)
+}
+
+func testResponseEnvelope(results *TestResults) {
+	fmt.Println("\n📋 Test 50: Response Envelope")
+
+	type envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   *struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	get := func(path string) (int, envelope, error) {
+		var body envelope
+		req, _ := http.NewRequest("GET", baseURL+path, nil)
+		req.Header.Set("X-Response-Envelope", "true")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0, body, err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body, err
+	}
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "envelope:1", "value": "wrapped"})
+	if err != nil {
+		failTest(results, "Response Envelope", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	status, body, err := get("/get/envelope:1")
+	if err != nil {
+		failTest(results, "Response Envelope", err.Error())
+		return
+	}
+	var data struct {
+		Value string `json:"value"`
+	}
+	json.Unmarshal(body.Data, &data)
+	if status != http.StatusOK || !body.Success || body.Error != nil || data.Value != "wrapped" {
+		failTest(results, "Response Envelope", fmt.Sprintf("Unexpected success envelope: %d %+v", status, body))
+		return
+	}
+
+	status, body, err = get("/info/envelope:missing")
+	if err != nil {
+		failTest(results, "Response Envelope", err.Error())
+		return
+	}
+	if status != http.StatusNotFound || body.Success || body.Data != nil || body.Error == nil || body.Error.Code != "KEY_NOT_FOUND" {
+		failTest(results, "Response Envelope", fmt.Sprintf("Unexpected error envelope: %d %+v", status, body))
+		return
+	}
+
+	fmt.Printf("✅ Response Envelope Passed - Status: %d\n", status)
+	passTest(results)
+}
+
+func testMaxIdle(results *TestResults) {
+	fmt.Println("\n📋 Test 51: Max Idle")
+
+	for _, key := range []string{"idle:unread", "idle:read"} {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": "v", "ttl": 3600, "max_idle": 2})
+		if err != nil {
+			failTest(results, "Max Idle", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	get := func(key string) (int, error) {
+		resp, err := http.Get(baseURL + "/get/" + key)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// Reading idle:read halfway through resets its idle timer; idle:unread is left alone
+	time.Sleep(1200 * time.Millisecond)
+	if status, err := get("idle:read"); err != nil || status != http.StatusOK {
+		failTest(results, "Max Idle", fmt.Sprintf("Expected idle:read to be live, got %d (%v)", status, err))
+		return
+	}
+	time.Sleep(1200 * time.Millisecond)
+
+	if status, err := get("idle:unread"); err != nil || status != http.StatusNotFound {
+		failTest(results, "Max Idle", fmt.Sprintf("Expected idle:unread to have expired despite its TTL, got %d (%v)", status, err))
+		return
+	}
+	status, err := get("idle:read")
+	if err != nil || status != http.StatusOK {
+		failTest(results, "Max Idle", fmt.Sprintf("Expected the read key to stay live, got %d (%v)", status, err))
+		return
+	}
+
+	fmt.Printf("✅ Max Idle Passed - Status: %d\n", status)
+	passTest(results)
+}
+
+func testRequestID(results *TestResults) {
+	fmt.Println("\n📋 Test 52: Request ID")
+
+	requestID := func(id string) (string, error) {
+		req, _ := http.NewRequest("GET", baseURL+"/health", nil)
+		if id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		return resp.Header.Get("X-Request-ID"), nil
+	}
+
+	echoed, err := requestID("trace-abc-123")
+	if err != nil {
+		failTest(results, "Request ID", err.Error())
+		return
+	}
+	if echoed != "trace-abc-123" {
+		failTest(results, "Request ID", fmt.Sprintf("Expected provided ID to be echoed, got '%s'", echoed))
+		return
+	}
+
+	generated, err := requestID("")
+	if err != nil {
+		failTest(results, "Request ID", err.Error())
+		return
+	}
+	if len(generated) != 32 {
+		failTest(results, "Request ID", fmt.Sprintf("Expected a generated 32-character ID, got '%s'", generated))
+		return
+	}
+
+	fmt.Printf("✅ Request ID Passed - Generated: %s\n", generated)
+	passTest(results)
+}
+
+func testSpecialCharacterKeys(results *TestResults) {
+	fmt.Println("\n📋 Test 53: Special Character Keys")
+
+	key := "foo/bar:baz?q=1 +x"
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": "special"})
+	if err != nil {
+		failTest(results, "Special Character Keys", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	for _, path := range []string{"/get?key=" + url.QueryEscape(key), "/get/" + url.PathEscape(key)} {
+		resp, err := http.Get(baseURL + path)
+		if err != nil {
+			failTest(results, "Special Character Keys", err.Error())
+			return
+		}
+		var body struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || body.Key != key || body.Value != "special" {
+			failTest(results, "Special Character Keys", fmt.Sprintf("%s: status %d, key '%s', value '%s'", path, resp.StatusCode, body.Key, body.Value))
+			return
+		}
+	}
+
+	fmt.Printf("✅ Special Character Keys Passed - Key: %s\n", key)
+	passTest(results)
+}
+
+func testBulkGetDefault(results *TestResults) {
+	fmt.Println("\n📋 Test 54: Bulk Get Default")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "bulkdefault:present", "value": "here"})
+	if err != nil {
+		failTest(results, "Bulk Get Default", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	type bulkGet struct {
+		Results map[string]struct {
+			Value interface{} `json:"value"`
+			Found bool        `json:"found"`
+		} `json:"results"`
+		Found    int `json:"found"`
+		NotFound int `json:"not_found"`
+	}
+	bulk := func(body map[string]interface{}) (bulkGet, error) {
+		var result bulkGet
+		resp, err := doJSON("POST", "/bulk/get", body)
+		if err != nil {
+			return result, err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		return result, err
+	}
+	keys := []string{"bulkdefault:present", "bulkdefault:missing"}
+
+	plain, err := bulk(map[string]interface{}{"keys": keys})
+	if err != nil {
+		failTest(results, "Bulk Get Default", err.Error())
+		return
+	}
+	if plain.Found != 1 || plain.NotFound != 1 || plain.Results["bulkdefault:missing"].Value != nil {
+		failTest(results, "Bulk Get Default", fmt.Sprintf("Unexpected result without default: %+v", plain))
+		return
+	}
+
+	withDefault, err := bulk(map[string]interface{}{"keys": keys, "default": "fallback"})
+	if err != nil {
+		failTest(results, "Bulk Get Default", err.Error())
+		return
+	}
+	missing := withDefault.Results["bulkdefault:missing"]
+	present := withDefault.Results["bulkdefault:present"]
+	if withDefault.Found != 1 || withDefault.NotFound != 1 || missing.Found || missing.Value != "fallback" || present.Value != "here" {
+		failTest(results, "Bulk Get Default", fmt.Sprintf("Unexpected result with default: %+v", withDefault))
+		return
+	}
+
+	fmt.Printf("✅ Bulk Get Default Passed - Missing: %v\n", missing.Value)
+	passTest(results)
+}
+
+func testValueSizeStats(results *TestResults) {
+	fmt.Println("\n📋 Test 55: Value Size Stats")
+
+	type valueSizes struct {
+		Writes       int64   `json:"writes"`
+		MinBytes     int64   `json:"min_bytes"`
+		MaxBytes     int64   `json:"max_bytes"`
+		AvgBytes     float64 `json:"avg_bytes"`
+		CurrentBytes int64   `json:"current_bytes"`
+	}
+	sizes := func() (valueSizes, error) {
+		var stats struct {
+			ValueSizes *valueSizes `json:"value_sizes"`
+		}
+		resp, err := http.Get(baseURL + "/stats?detailed=true")
+		if err != nil {
+			return valueSizes{}, err
+		}
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			return valueSizes{}, err
+		}
+		if stats.ValueSizes == nil {
+			return valueSizes{}, fmt.Errorf("detailed stats have no value_sizes")
+		}
+		return *stats.ValueSizes, nil
+	}
+
+	before, err := sizes()
+	if err != nil {
+		failTest(results, "Value Size Stats", err.Error())
+		return
+	}
+
+	// A 998-character string is 1000 bytes of JSON with its quotes
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "valuesize:1", "value": strings.Repeat("v", 998)})
+	if err != nil {
+		failTest(results, "Value Size Stats", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	after, err := sizes()
+	if err != nil {
+		failTest(results, "Value Size Stats", err.Error())
+		return
+	}
+	if after.Writes != before.Writes+1 || after.CurrentBytes != before.CurrentBytes+1000 || after.MaxBytes < 1000 || after.MinBytes > 1000 {
+		failTest(results, "Value Size Stats", fmt.Sprintf("Unexpected sizes after put: before %+v, after %+v", before, after))
+		return
+	}
+
+	req, _ := http.NewRequest("DELETE", baseURL+"/delete/valuesize:1", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		failTest(results, "Value Size Stats", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	deleted, err := sizes()
+	if err != nil {
+		failTest(results, "Value Size Stats", err.Error())
+		return
+	}
+	if deleted.CurrentBytes != before.CurrentBytes || deleted.Writes != after.Writes {
+		failTest(results, "Value Size Stats", fmt.Sprintf("Unexpected sizes after delete: %+v", deleted))
+		return
+	}
+
+	fmt.Printf("✅ Value Size Stats Passed - Avg: %.1f bytes\n", deleted.AvgBytes)
+	passTest(results)
+}
+
+func testBulkTouch(results *TestResults) {
+	fmt.Println("\n📋 Test 56: Bulk Touch")
+
+	for _, key := range []string{"touch:1", "touch:2"} {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": "session", "ttl": 10})
+		if err != nil {
+			failTest(results, "Bulk Touch", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := doJSON("POST", "/bulk/touch", map[string]interface{}{
+		"keys": []string{"touch:1", "touch:2", "touch:missing"},
+		"ttl":  3600,
+	})
+	if err != nil {
+		failTest(results, "Bulk Touch", err.Error())
+		return
+	}
+	var touch struct {
+		Touched  map[string]bool `json:"touched"`
+		Updated  int             `json:"updated"`
+		NotFound int             `json:"not_found"`
+	}
+	json.NewDecoder(resp.Body).Decode(&touch)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || touch.Updated != 2 || touch.NotFound != 1 ||
+		!touch.Touched["touch:1"] || !touch.Touched["touch:2"] || touch.Touched["touch:missing"] {
+		failTest(results, "Bulk Touch", fmt.Sprintf("Unexpected touch result: %d %+v", resp.StatusCode, touch))
+		return
+	}
+
+	resp, err = doJSON("POST", "/bulk/ttl", map[string]interface{}{"keys": []string{"touch:1", "touch:2"}})
+	if err != nil {
+		failTest(results, "Bulk Touch", err.Error())
+		return
+	}
+	var ttls struct {
+		TTLs map[string]int64 `json:"ttls"`
+	}
+	json.NewDecoder(resp.Body).Decode(&ttls)
+	resp.Body.Close()
+	if ttls.TTLs["touch:1"] < 3500 || ttls.TTLs["touch:2"] < 3500 {
+		failTest(results, "Bulk Touch", fmt.Sprintf("TTLs not extended: %+v", ttls.TTLs))
+		return
+	}
+
+	fmt.Printf("✅ Bulk Touch Passed - Updated: %d, Not found: %d\n", touch.Updated, touch.NotFound)
+	passTest(results)
+}
+
+func testExpiringSoon(results *TestResults) {
+	fmt.Println("\n📋 Test 57: Expiring Soon")
+
+	puts := []map[string]interface{}{
+		{"key": "expiring:late", "value": 3, "ttl": 900},
+		{"key": "expiring:soon", "value": 1, "ttl": 300},
+		{"key": "expiring:never", "value": 0, "persistent": true},
+		{"key": "expiring:mid", "value": 2, "ttl": 600},
+	}
+	for _, put := range puts {
+		resp, err := doJSON("PUT", "/put", put)
+		if err != nil {
+			failTest(results, "Expiring Soon", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(baseURL + "/expiring?n=1000")
+	if err != nil {
+		failTest(results, "Expiring Soon", err.Error())
+		return
+	}
+	var expiring struct {
+		Entries []struct {
+			Key string `json:"key"`
+			TTL *int64 `json:"ttl"`
+		} `json:"entries"`
+		Count int `json:"count"`
+	}
+	json.NewDecoder(resp.Body).Decode(&expiring)
+	resp.Body.Close()
+
+	// Other tests leave keys behind, so only check the relative order of ours
+	var order []string
+	var previous int64 = -1
+	for _, entry := range expiring.Entries {
+		if entry.TTL == nil || *entry.TTL < previous {
+			failTest(results, "Expiring Soon", fmt.Sprintf("Entries not sorted by TTL at %s", entry.Key))
+			return
+		}
+		previous = *entry.TTL
+		if strings.HasPrefix(entry.Key, "expiring:") {
+			order = append(order, entry.Key)
+		}
+	}
+	if strings.Join(order, ",") != "expiring:soon,expiring:mid,expiring:late" || expiring.Count != len(expiring.Entries) {
+		failTest(results, "Expiring Soon", fmt.Sprintf("Unexpected order %v (count %d)", order, expiring.Count))
+		return
+	}
+
+	fmt.Printf("✅ Expiring Soon Passed - Order: %v\n", order)
+	passTest(results)
+}
+
+func testWorkerHealth(results *TestResults) {
+	fmt.Println("\n📋 Test 58: Worker Health")
+
+	cleanup := func(method, path string) (string, error) {
+		req, _ := http.NewRequest(method, baseURL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+
+		resp, err = http.Get(baseURL + "/debug/workers")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		var health struct {
+			Goroutines int `json:"goroutines"`
+			Workers    []struct {
+				Name   string `json:"name"`
+				Status string `json:"status"`
+				Alive  bool   `json:"alive"`
+			} `json:"workers"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+			return "", err
+		}
+		if health.Goroutines <= 0 || len(health.Workers) != 1 || health.Workers[0].Name != "cleanup" || !health.Workers[0].Alive {
+			return "", fmt.Errorf("unexpected worker health: %+v", health)
+		}
+		return health.Workers[0].Status, nil
+	}
+
+	paused, err := cleanup("POST", "/cleanup/pause")
+	if err != nil {
+		failTest(results, "Worker Health", err.Error())
+		return
+	}
+	resumed, err := cleanup("POST", "/cleanup/resume")
+	if err != nil {
+		failTest(results, "Worker Health", err.Error())
+		return
+	}
+	if paused != "paused" || resumed != "running" {
+		failTest(results, "Worker Health", fmt.Sprintf("Expected paused then running, got %s then %s", paused, resumed))
+		return
+	}
+
+	fmt.Printf("✅ Worker Health Passed - Status: %s\n", resumed)
+	passTest(results)
+}
+
+func testImportStrategies(results *TestResults) {
+	fmt.Println("\n📋 Test 59: Import Strategies")
+
+	older := time.Now().Add(-time.Hour).Unix()
+	newer := time.Now().Add(time.Hour).Unix()
+
+	type importCounts struct {
+		Imported    int `json:"imported"`
+		Skipped     int `json:"skipped"`
+		Overwritten int `json:"overwritten"`
+	}
+	cases := []struct {
+		strategy string
+		want     importCounts
+		values   map[string]string // expected value of each key afterwards
+	}{
+		{"overwrite", importCounts{Imported: 3, Overwritten: 2},
+			map[string]string{"old": "imported", "new": "imported", "fresh": "imported"}},
+		{"skip-existing", importCounts{Imported: 1, Skipped: 2},
+			map[string]string{"old": "existing", "new": "existing", "fresh": "imported"}},
+		{"keep-newer", importCounts{Imported: 2, Skipped: 1, Overwritten: 1},
+			map[string]string{"old": "existing", "new": "imported", "fresh": "imported"}},
+	}
+
+	for _, tc := range cases {
+		prefix := "import:" + tc.strategy + ":"
+		for _, key := range []string{"old", "new"} {
+			resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": prefix + key, "value": "existing"})
+			if err != nil {
+				failTest(results, "Import Strategies", err.Error())
+				return
+			}
+			resp.Body.Close()
+		}
+
+		resp, err := doJSON("POST", "/import?strategy="+tc.strategy, map[string]interface{}{
+			"entries": []map[string]interface{}{
+				{"key": prefix + "old", "value": "imported", "created_at": older},
+				{"key": prefix + "new", "value": "imported", "created_at": newer},
+				{"key": prefix + "fresh", "value": "imported"},
+			},
+		})
+		if err != nil {
+			failTest(results, "Import Strategies", err.Error())
+			return
+		}
+		var counts importCounts
+		json.NewDecoder(resp.Body).Decode(&counts)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || counts != tc.want {
+			failTest(results, "Import Strategies", fmt.Sprintf("%s: got %d %+v, want %+v", tc.strategy, resp.StatusCode, counts, tc.want))
+			return
+		}
+
+		for key, want := range tc.values {
+			resp, err := http.Get(baseURL + "/get/" + prefix + key)
+			if err != nil {
+				failTest(results, "Import Strategies", err.Error())
+				return
+			}
+			var got struct {
+				Value string `json:"value"`
+			}
+			json.NewDecoder(resp.Body).Decode(&got)
+			resp.Body.Close()
+			if got.Value != want {
+				failTest(results, "Import Strategies", fmt.Sprintf("%s: %s holds %q, want %q", tc.strategy, key, got.Value, want))
+				return
+			}
+		}
+	}
+
+	resp, err := doJSON("POST", "/import?strategy=merge", map[string]interface{}{
+		"entries": []map[string]interface{}{{"key": "import:invalid", "value": 1}},
+	})
+	if err != nil {
+		failTest(results, "Import Strategies", err.Error())
+		return
+	}
+	var errResp struct {
+		Code string `json:"code"`
+	}
+	json.NewDecoder(resp.Body).Decode(&errResp)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || errResp.Code != "INVALID_STRATEGY" {
+		failTest(results, "Import Strategies", fmt.Sprintf("Unknown strategy: %d %s", resp.StatusCode, errResp.Code))
+		return
+	}
+
+	fmt.Println("✅ Import Strategies Passed - overwrite, skip-existing and keep-newer")
+	passTest(results)
+}
+
+func testReturnEvicted(results *TestResults) {
+	fmt.Println("\n📋 Test 60: Return Evicted")
+
+	// Fill the cache with fresh keys so the next new key has to evict one
+	for i := 0; i < 1000; i++ {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": fmt.Sprintf("evicted:fill:%d", i), "value": i})
+		if err != nil {
+			failTest(results, "Return Evicted", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(baseURL + "/next-eviction")
+	if err != nil {
+		failTest(results, "Return Evicted", err.Error())
+		return
+	}
+	var next struct {
+		Key string `json:"key"`
+	}
+	json.NewDecoder(resp.Body).Decode(&next)
+	resp.Body.Close()
+
+	type putResult struct {
+		Evicted []struct {
+			Key   string      `json:"key"`
+			Value interface{} `json:"value"`
+		} `json:"evicted"`
+	}
+
+	resp, err = doJSON("PUT", "/put?return_evicted=true", map[string]interface{}{"key": "evicted:trigger", "value": "new"})
+	if err != nil {
+		failTest(results, "Return Evicted", err.Error())
+		return
+	}
+	var put putResult
+	json.NewDecoder(resp.Body).Decode(&put)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || len(put.Evicted) != 1 || put.Evicted[0].Key != next.Key || put.Evicted[0].Value == nil {
+		failTest(results, "Return Evicted", fmt.Sprintf("Expected %q to be evicted, got %d %+v", next.Key, resp.StatusCode, put.Evicted))
+		return
+	}
+
+	// Updating an existing key evicts nothing and reports null
+	resp, err = doJSON("PUT", "/put?return_evicted=true", map[string]interface{}{"key": "evicted:trigger", "value": "updated"})
+	if err != nil {
+		failTest(results, "Return Evicted", err.Error())
+		return
+	}
+	var raw map[string]json.RawMessage
+	json.NewDecoder(resp.Body).Decode(&raw)
+	resp.Body.Close()
+	if evicted, ok := raw["evicted"]; !ok || string(evicted) != "null" {
+		failTest(results, "Return Evicted", fmt.Sprintf("Expected evicted to be null, got %s", raw["evicted"]))
+		return
+	}
+
+	fmt.Printf("✅ Return Evicted Passed - Evicted: %s\n", put.Evicted[0].Key)
+	passTest(results)
+}
+
+func testCompareKeys(results *TestResults) {
+	fmt.Println("\n📋 Test 61: Compare Keys")
+
+	puts := map[string]interface{}{
+		"compare:eu": map[string]interface{}{"timeout": 30, "hosts": []string{"a", "b"}},
+		"compare:us": map[string]interface{}{"timeout": 30, "hosts": []string{"a", "b"}},
+		"compare:ap": map[string]interface{}{"timeout": 45, "hosts": []string{"a", "b"}},
+	}
+	for key, value := range puts {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": value})
+		if err != nil {
+			failTest(results, "Compare Keys", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	compare := func(a, b string) (int, bool, string, error) {
+		resp, err := http.Get(baseURL + "/compare?a=" + url.QueryEscape(a) + "&b=" + url.QueryEscape(b))
+		if err != nil {
+			return 0, false, "", err
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Equal bool   `json:"equal"`
+			Code  string `json:"code"`
+			A     struct {
+				Value map[string]interface{} `json:"value"`
+			} `json:"a"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode == http.StatusOK && body.A.Value["timeout"] != float64(30) {
+			return resp.StatusCode, body.Equal, body.Code, fmt.Errorf("value of a not returned: %+v", body.A.Value)
+		}
+		return resp.StatusCode, body.Equal, body.Code, nil
+	}
+
+	status, equal, _, err := compare("compare:eu", "compare:us")
+	if err != nil || status != http.StatusOK || !equal {
+		failTest(results, "Compare Keys", fmt.Sprintf("Equal values: %d %v %v", status, equal, err))
+		return
+	}
+	status, equal, _, err = compare("compare:eu", "compare:ap")
+	if err != nil || status != http.StatusOK || equal {
+		failTest(results, "Compare Keys", fmt.Sprintf("Differing values: %d %v %v", status, equal, err))
+		return
+	}
+	status, _, code, err := compare("compare:eu", "compare:missing")
+	if err != nil || status != http.StatusNotFound || code != "KEY_NOT_FOUND" {
+		failTest(results, "Compare Keys", fmt.Sprintf("Missing key: %d %s %v", status, code, err))
+		return
+	}
+
+	fmt.Println("✅ Compare Keys Passed - equal, differing and missing")
+	passTest(results)
+}
+
+func testRawValue(results *TestResults) {
+	fmt.Println("\n📋 Test 62: Raw Value")
+
+	// Key order, 1.50 and an integer beyond float64 precision would not survive decoding
+	value := `{"zeta":1,"alpha":1.50,"big":12345678901234567890}`
+	req, _ := http.NewRequest("PUT", baseURL+"/put?raw_value=true", strings.NewReader(`{"key": "raw:config", "value": `+value+`}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		failTest(results, "Raw Value", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		failTest(results, "Raw Value", fmt.Sprintf("Put returned %d", resp.StatusCode))
+		return
+	}
+
+	resp, err = http.Get(baseURL + "/get/raw:config")
+	if err != nil {
+		failTest(results, "Raw Value", err.Error())
+		return
+	}
+	var got struct {
+		Value json.RawMessage `json:"value"`
+	}
+	json.NewDecoder(resp.Body).Decode(&got)
+	resp.Body.Close()
+	if string(got.Value) != value {
+		failTest(results, "Raw Value", fmt.Sprintf("Expected %s, got %s", value, got.Value))
+		return
+	}
+
+	// A path lookup still works on the raw value
+	resp, err = http.Get(baseURL + "/get/raw:config?path=$.alpha")
+	if err != nil {
+		failTest(results, "Raw Value", err.Error())
+		return
+	}
+	var lookup struct {
+		Value float64 `json:"value"`
+	}
+	json.NewDecoder(resp.Body).Decode(&lookup)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || lookup.Value != 1.5 {
+		failTest(results, "Raw Value", fmt.Sprintf("Path lookup: %d %v", resp.StatusCode, lookup.Value))
+		return
+	}
+
+	fmt.Printf("✅ Raw Value Passed - Round-tripped: %s\n", got.Value)
+	passTest(results)
+}
+
+func testEvictionRate(results *TestResults) {
+	fmt.Println("\n📋 Test 63: Eviction Rate")
+
+	resp, err := http.Get(baseURL + "/stats")
+	if err != nil {
+		failTest(results, "Eviction Rate", err.Error())
+		return
+	}
+	var before struct {
+		MaxSize   int   `json:"max_size"`
+		Evictions int64 `json:"evictions"`
+	}
+	json.NewDecoder(resp.Body).Decode(&before)
+	resp.Body.Close()
+
+	// Once the cache is full every new key evicts one
+	for i := 0; i < before.MaxSize+200; i++ {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": fmt.Sprintf("rate:%d", i), "value": i})
+		if err != nil {
+			failTest(results, "Eviction Rate", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err = http.Get(baseURL + "/stats")
+	if err != nil {
+		failTest(results, "Eviction Rate", err.Error())
+		return
+	}
+	var after struct {
+		Evictions    int64   `json:"evictions"`
+		EvictionRate float64 `json:"eviction_rate"`
+		Thrashing    bool    `json:"thrashing"`
+	}
+	json.NewDecoder(resp.Body).Decode(&after)
+	resp.Body.Close()
+
+	// At least 200 evictions happened within the 10 second window; no threshold is configured
+	if after.Evictions-before.Evictions < 200 || after.EvictionRate < 20 || after.Thrashing {
+		failTest(results, "Eviction Rate", fmt.Sprintf("Unexpected stats: %+v", after))
+		return
+	}
+
+	fmt.Printf("✅ Eviction Rate Passed - %.1f evictions/s\n", after.EvictionRate)
+	passTest(results)
+}
+
+func testDeleteByHierarchy(results *TestResults) {
+	fmt.Println("\n📋 Test 64: Delete By Hierarchy")
+
+	keys := []string{
+		"hier:eu:users:1", "hier:us:users:2", "hier:eu:orders:1",
+		"hier:eu:users", "hier:eu:users:1:avatar",
+	}
+	for _, key := range keys {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": key})
+		if err != nil {
+			failTest(results, "Delete By Hierarchy", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	deleteBy := func(pattern string) (int, error) {
+		req, _ := http.NewRequest("DELETE", baseURL+"/hierarchy?p="+url.QueryEscape(pattern), nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Deleted int `json:"deleted"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return body.Deleted, nil
+	}
+	exists := func(key string) bool {
+		resp, err := http.Get(baseURL + "/get/" + key)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}
+
+	// One wildcard: only four-segment keys with "orders" third
+	deleted, err := deleteBy("hier:*:orders:1")
+	if err != nil || deleted != 1 || exists("hier:eu:orders:1") {
+		failTest(results, "Delete By Hierarchy", fmt.Sprintf("Single wildcard deleted %d: %v", deleted, err))
+		return
+	}
+
+	// Two wildcards: a:*:c:* leaves keys with fewer or more segments alone
+	deleted, err = deleteBy("hier:*:users:*")
+	if err != nil || deleted != 2 || exists("hier:eu:users:1") || exists("hier:us:users:2") {
+		failTest(results, "Delete By Hierarchy", fmt.Sprintf("Multiple wildcards deleted %d: %v", deleted, err))
+		return
+	}
+	if !exists("hier:eu:users") || !exists("hier:eu:users:1:avatar") {
+		failTest(results, "Delete By Hierarchy", "Keys with a different segment count were deleted")
+		return
+	}
+
+	fmt.Println("✅ Delete By Hierarchy Passed - single and multiple wildcards")
+	passTest(results)
+}
+
+func testWarmup(results *TestResults) {
+	fmt.Println("\n📋 Test 65: Warmup")
+
+	type warmupCounts struct {
+		Loaded  int `json:"loaded"`
+		Skipped int `json:"skipped"`
+		Failed  int `json:"failed"`
+	}
+	warmup := func(query string, keys ...string) (warmupCounts, error) {
+		items := make([]map[string]interface{}, len(keys))
+		for i, key := range keys {
+			items[i] = map[string]interface{}{"key": key, "value": "warm"}
+		}
+		resp, err := doJSON("POST", "/warmup"+query, map[string]interface{}{"items": items})
+		if err != nil {
+			return warmupCounts{}, err
+		}
+		defer resp.Body.Close()
+		var counts warmupCounts
+		json.NewDecoder(resp.Body).Decode(&counts)
+		if resp.StatusCode != http.StatusOK {
+			return counts, fmt.Errorf("warmup returned %d", resp.StatusCode)
+		}
+		return counts, nil
+	}
+	valueOf := func(key string) string {
+		resp, err := http.Get(baseURL + "/get/" + key)
+		if err != nil {
+			return ""
+		}
+		defer resp.Body.Close()
+		var got struct {
+			Value string `json:"value"`
+		}
+		json.NewDecoder(resp.Body).Decode(&got)
+		return got.Value
+	}
+
+	// Keys not yet in the cache are all loaded
+	counts, err := warmup("", "warmup:empty:1", "warmup:empty:2")
+	if err != nil || counts != (warmupCounts{Loaded: 2}) {
+		failTest(results, "Warmup", fmt.Sprintf("empty cache: got %+v, %v", counts, err))
+		return
+	}
+
+	// With skip_existing a present key keeps its value
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "warmup:partial:1", "value": "existing"})
+	if err != nil {
+		failTest(results, "Warmup", err.Error())
+		return
+	}
+	resp.Body.Close()
+	counts, err = warmup("?skip_existing=true", "warmup:partial:1", "warmup:partial:2")
+	if err != nil || counts != (warmupCounts{Loaded: 1, Skipped: 1}) {
+		failTest(results, "Warmup", fmt.Sprintf("skip_existing: got %+v, %v", counts, err))
+		return
+	}
+	if got := valueOf("warmup:partial:1"); got != "existing" {
+		failTest(results, "Warmup", fmt.Sprintf("skipped key holds %q, want \"existing\"", got))
+		return
+	}
+	if got := valueOf("warmup:partial:2"); got != "warm" {
+		failTest(results, "Warmup", fmt.Sprintf("loaded key holds %q, want \"warm\"", got))
+		return
+	}
+
+	// Without it present keys are overwritten
+	counts, err = warmup("", "warmup:partial:1")
+	if err != nil || counts != (warmupCounts{Loaded: 1}) || valueOf("warmup:partial:1") != "warm" {
+		failTest(results, "Warmup", fmt.Sprintf("overwrite: got %+v, %v", counts, err))
+		return
+	}
+
+	fmt.Println("✅ Warmup Passed - empty, skip_existing and overwrite")
+	passTest(results)
+}
+
+func testExportCSV(results *TestResults) {
+	fmt.Println("\n📋 Test 66: CSV Export")
+
+	items := []map[string]interface{}{
+		{"key": "csv:plain", "value": "hello, world"},
+		{"key": "csv:object", "value": map[string]interface{}{"n": 1}},
+		{"key": "csv:number", "value": 42, "ttl": 3600},
+		{"key": "csv:expired", "value": "gone", "expire_at": time.Now().Add(-time.Minute).Unix()},
+	}
+	for _, item := range items {
+		resp, err := doJSON("PUT", "/put", item)
+		if err != nil {
+			failTest(results, "CSV Export", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(baseURL + "/export.csv")
+	if err != nil {
+		failTest(results, "CSV Export", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/csv") {
+		failTest(results, "CSV Export", fmt.Sprintf("got %d %s", resp.StatusCode, resp.Header.Get("Content-Type")))
+		return
+	}
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil || len(rows) == 0 {
+		failTest(results, "CSV Export", fmt.Sprintf("parsing CSV: %v", err))
+		return
+	}
+
+	if header := strings.Join(rows[0], ","); header != "key,created_at,accessed_at,ttl,value" {
+		failTest(results, "CSV Export", "unexpected header "+header)
+		return
+	}
+
+	values := make(map[string][]string)
+	for _, row := range rows[1:] {
+		if strings.HasPrefix(row[0], "csv:") {
+			values[row[0]] = row
+		}
+	}
+	if len(values) != 3 {
+		failTest(results, "CSV Export", fmt.Sprintf("got %d csv: rows, want 3 (expired key skipped)", len(values)))
+		return
+	}
+	if values["csv:plain"][4] != "hello, world" || values["csv:object"][4] != `{"n":1}` || values["csv:number"][4] != "42" {
+		failTest(results, "CSV Export", fmt.Sprintf("unexpected values %v", values))
+		return
+	}
+	if ttl := values["csv:number"][3]; ttl != "3600" && ttl != "3599" {
+		failTest(results, "CSV Export", "unexpected ttl "+ttl)
+		return
+	}
+
+	fmt.Printf("✅ CSV Export Passed - %d rows\n", len(rows)-1)
+	passTest(results)
+}
+
+func testSetDefaultTTL(results *TestResults) {
+	fmt.Println("\n📋 Test 67: Default TTL")
+
+	setDefault := func(ttl int) (*http.Response, error) {
+		return doJSON("POST", "/config/default-ttl", map[string]interface{}{"ttl": ttl})
+	}
+	ttlOf := func(key string) int64 {
+		resp, err := http.Get(baseURL + "/info/" + key)
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		var info struct {
+			TTL int64 `json:"ttl"`
+		}
+		json.NewDecoder(resp.Body).Decode(&info)
+		return info.TTL
+	}
+
+	// Remember the configured default to restore it afterwards
+	resp, err := http.Get(baseURL + "/config")
+	if err != nil {
+		failTest(results, "Default TTL", err.Error())
+		return
+	}
+	var config struct {
+		DefaultTTL string `json:"default_ttl"`
+	}
+	json.NewDecoder(resp.Body).Decode(&config)
+	resp.Body.Close()
+	original, err := time.ParseDuration(config.DefaultTTL)
+	if err != nil {
+		failTest(results, "Default TTL", "unreadable default_ttl "+config.DefaultTTL)
+		return
+	}
+	defer func() {
+		if resp, err := setDefault(int(original / time.Second)); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	resp, err = doJSON("PUT", "/put", map[string]interface{}{"key": "defaultttl:before", "value": 1})
+	if err != nil {
+		failTest(results, "Default TTL", err.Error())
+		return
+	}
+	resp.Body.Close()
+	before := ttlOf("defaultttl:before")
+
+	resp, err = setDefault(120)
+	if err != nil {
+		failTest(results, "Default TTL", err.Error())
+		return
+	}
+	var set struct {
+		DefaultTTL int64 `json:"default_ttl"`
+	}
+	json.NewDecoder(resp.Body).Decode(&set)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || set.DefaultTTL != 120 {
+		failTest(results, "Default TTL", fmt.Sprintf("set returned %d %+v", resp.StatusCode, set))
+		return
+	}
+
+	resp, err = doJSON("PUT", "/put", map[string]interface{}{"key": "defaultttl:after", "value": 1})
+	if err != nil {
+		failTest(results, "Default TTL", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if ttl := ttlOf("defaultttl:after"); ttl < 118 || ttl > 120 {
+		failTest(results, "Default TTL", fmt.Sprintf("new key has ttl %d, want about 120", ttl))
+		return
+	}
+	if ttl := ttlOf("defaultttl:before"); ttl < before-2 || ttl > before {
+		failTest(results, "Default TTL", fmt.Sprintf("existing key ttl changed from %d to %d", before, ttl))
+		return
+	}
+
+	resp, err = setDefault(-1)
+	if err != nil {
+		failTest(results, "Default TTL", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		failTest(results, "Default TTL", fmt.Sprintf("negative ttl returned %d, want 400", resp.StatusCode))
+		return
+	}
+
+	fmt.Printf("✅ Default TTL Passed - existing key %ds, new key 120s\n", before)
+	passTest(results)
+}
+
+func testBulkItemStatus(results *TestResults) {
+	fmt.Println("\n📋 Test 68: Bulk Item Status")
+
+	resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": "itemstatus:existing", "value": 1})
+	if err != nil {
+		failTest(results, "Bulk Item Status", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	resp, err = doJSON("POST", "/bulk/put", map[string]interface{}{
+		"items": []map[string]interface{}{
+			{"key": "itemstatus:new", "value": 1},
+			{"key": "itemstatus:existing", "value": 2},
+			{"key": "", "value": 3},
+			{"key": "itemstatus:conflict", "value": 4, "ttl": 60, "expire_at": time.Now().Add(time.Hour).Unix()},
+		},
+	})
+	if err != nil {
+		failTest(results, "Bulk Item Status", err.Error())
+		return
+	}
+	var put struct {
+		Successful int `json:"successful"`
+		Failed     int `json:"failed"`
+		Results    []struct {
+			Key    string `json:"key"`
+			Status int    `json:"status"`
+			Code   string `json:"code"`
+			Error  string `json:"error"`
+		} `json:"results"`
+	}
+	json.NewDecoder(resp.Body).Decode(&put)
+	resp.Body.Close()
+	if put.Successful != 2 || put.Failed != 2 || len(put.Results) != 4 {
+		failTest(results, "Bulk Item Status", fmt.Sprintf("got %+v", put))
+		return
+	}
+	want := []struct {
+		status int
+		code   string
+	}{{201, ""}, {200, ""}, {400, "MISSING_KEY"}, {400, "CONFLICTING_EXPIRATION"}}
+	for i, w := range want {
+		got := put.Results[i]
+		if got.Status != w.status || got.Code != w.code || (w.code != "") != (got.Error != "") {
+			failTest(results, "Bulk Item Status", fmt.Sprintf("item %d: got %+v, want %d %s", i, got, w.status, w.code))
+			return
+		}
+	}
+
+	resp, err = doJSON("POST", "/bulk/get", map[string]interface{}{
+		"keys": []string{"itemstatus:new", "itemstatus:missing"},
+	})
+	if err != nil {
+		failTest(results, "Bulk Item Status", err.Error())
+		return
+	}
+	var get struct {
+		Results map[string]struct {
+			Status int `json:"status"`
+		} `json:"results"`
+	}
+	json.NewDecoder(resp.Body).Decode(&get)
+	resp.Body.Close()
+	if get.Results["itemstatus:new"].Status != 200 || get.Results["itemstatus:missing"].Status != 404 {
+		failTest(results, "Bulk Item Status", fmt.Sprintf("bulk get statuses %+v", get.Results))
+		return
+	}
+
+	fmt.Println("✅ Bulk Item Status Passed - 201, 200, 400 MISSING_KEY, 400 CONFLICTING_EXPIRATION")
+	passTest(results)
+}
+
+func testMaxTTL(results *TestResults) {
+	fmt.Println("\n📋 Test 69: Max TTL")
+
+	ttlOf := func(key string) int64 {
+		resp, err := http.Get(baseURL + "/info/" + key)
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		var info struct {
+			TTL int64 `json:"ttl"`
+		}
+		json.NewDecoder(resp.Body).Decode(&info)
+		return info.TTL
+	}
+
+	resp, err := http.Get(baseURL + "/config")
+	if err != nil {
+		failTest(results, "Max TTL", err.Error())
+		return
+	}
+	var config struct {
+		MaxTTL     string `json:"max_ttl"`
+		MaxTTLMode string `json:"max_ttl_mode"`
+	}
+	json.NewDecoder(resp.Body).Decode(&config)
+	resp.Body.Close()
+	maxTTL, err := time.ParseDuration(config.MaxTTL)
+	if err != nil {
+		failTest(results, "Max TTL", "unreadable max_ttl "+config.MaxTTL)
+		return
+	}
+
+	// A TTL within the cap (or with no cap) is stored as given
+	within := 60
+	resp, err = doJSON("PUT", "/put", map[string]interface{}{"key": "maxttl:within", "value": 1, "ttl": within})
+	if err != nil {
+		failTest(results, "Max TTL", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if ttl := ttlOf("maxttl:within"); ttl < int64(within-2) || ttl > int64(within) {
+		failTest(results, "Max TTL", fmt.Sprintf("within-cap ttl %d, want %d", ttl, within))
+		return
+	}
+
+	if maxTTL <= 0 {
+		fmt.Println("✅ Max TTL Passed - no cap configured, TTL stored as given")
+		passTest(results)
+		return
+	}
+
+	// Ten years is over any sensible cap
+	over := 10 * 365 * 24 * 3600
+	resp, err = doJSON("PUT", "/put", map[string]interface{}{"key": "maxttl:over", "value": 1, "ttl": over})
+	if err != nil {
+		failTest(results, "Max TTL", err.Error())
+		return
+	}
+	var put struct {
+		Code string `json:"code"`
+		TTL  int64  `json:"ttl"`
+	}
+	json.NewDecoder(resp.Body).Decode(&put)
+	resp.Body.Close()
+
+	capSeconds := int64(maxTTL / time.Second)
+	if config.MaxTTLMode == "reject" {
+		if resp.StatusCode != http.StatusBadRequest || put.Code != "TTL_TOO_LONG" {
+			failTest(results, "Max TTL", fmt.Sprintf("over-cap put returned %d %s, want 400 TTL_TOO_LONG", resp.StatusCode, put.Code))
+			return
+		}
+		fmt.Println("✅ Max TTL Passed - over-cap TTL rejected with TTL_TOO_LONG")
+		passTest(results)
+		return
+	}
+	if put.TTL != capSeconds {
+		failTest(results, "Max TTL", fmt.Sprintf("put reported ttl %d, want %d", put.TTL, capSeconds))
+		return
+	}
+	if ttl := ttlOf("maxttl:over"); ttl < capSeconds-2 || ttl > capSeconds {
+		failTest(results, "Max TTL", fmt.Sprintf("over-cap ttl %d, want %d", ttl, capSeconds))
+		return
+	}
+
+	resp, err = doJSON("POST", "/expire/maxttl:within", map[string]interface{}{"ttl": over})
+	if err != nil {
+		failTest(results, "Max TTL", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if ttl := ttlOf("maxttl:within"); ttl < capSeconds-2 || ttl > capSeconds {
+		failTest(results, "Max TTL", fmt.Sprintf("expire ttl %d, want %d", ttl, capSeconds))
+		return
+	}
+
+	fmt.Println("✅ Max TTL Passed - within-cap TTL kept, over-cap put and expire clamped")
+	passTest(results)
+}
+
+func testSample(results *TestResults) {
+	fmt.Println("\n📋 Test 70: Random Sample")
+
+	req, err := http.NewRequest("DELETE", baseURL+"/clear", nil)
+	if err != nil {
+		failTest(results, "Random Sample", err.Error())
+		return
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		failTest(results, "Random Sample", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	stored := map[string]bool{}
+	for i := 1; i <= 5; i++ {
+		key := fmt.Sprintf("sample:%d", i)
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": i})
+		if err != nil {
+			failTest(results, "Random Sample", err.Error())
+			return
+		}
+		resp.Body.Close()
+		stored[key] = true
+	}
+
+	sample := func(n int) ([]string, error) {
+		resp, err := http.Get(fmt.Sprintf("%s/sample?n=%d", baseURL, n))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		var body struct {
+			Entries []struct {
+				Key string `json:"key"`
+			} `json:"entries"`
+			Count int `json:"count"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if body.Count != len(body.Entries) {
+			return nil, fmt.Errorf("count %d but %d entries", body.Count, len(body.Entries))
+		}
+		keys := make([]string, 0, len(body.Entries))
+		for _, entry := range body.Entries {
+			keys = append(keys, entry.Key)
+		}
+		return keys, nil
+	}
+	nextEviction := func() string {
+		resp, err := http.Get(baseURL + "/next-eviction")
+		if err != nil {
+			return ""
+		}
+		defer resp.Body.Close()
+		var next struct {
+			Key string `json:"key"`
+		}
+		json.NewDecoder(resp.Body).Decode(&next)
+		return next.Key
+	}
+
+	before := nextEviction()
+	for _, n := range []int{3, 50} {
+		keys, err := sample(n)
+		if err != nil {
+			failTest(results, "Random Sample", err.Error())
+			return
+		}
+		want := min(n, len(stored))
+		if len(keys) != want {
+			failTest(results, "Random Sample", fmt.Sprintf("n=%d returned %d entries, want %d", n, len(keys), want))
+			return
+		}
+		seen := map[string]bool{}
+		for _, key := range keys {
+			if !stored[key] || seen[key] {
+				failTest(results, "Random Sample", fmt.Sprintf("n=%d returned unexpected or repeated key %q in %v", n, key, keys))
+				return
+			}
+			seen[key] = true
+		}
+	}
+	if after := nextEviction(); after != before {
+		failTest(results, "Random Sample", fmt.Sprintf("sampling changed the next eviction from %s to %s", before, after))
+		return
+	}
+
+	fmt.Println("✅ Random Sample Passed - distinct entries, capped at the live count, LRU order untouched")
+	passTest(results)
+}
+
+func testLRUOrder(results *TestResults) {
+	fmt.Println("\n📋 Test 71: LRU Order")
+
+	type order struct {
+		Keys  []string `json:"keys"`
+		Count int      `json:"count"`
+		Total int      `json:"total"`
+		Code  string   `json:"code"`
+	}
+	lruOrder := func(limit int) (order, int, error) {
+		var body order
+		resp, err := http.Get(fmt.Sprintf("%s/debug/lru-order?limit=%d", baseURL, limit))
+		if err != nil {
+			return body, 0, err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		return body, resp.StatusCode, err
+	}
+
+	first, status, err := lruOrder(10)
+	if err != nil {
+		failTest(results, "LRU Order", err.Error())
+		return
+	}
+	if status == http.StatusForbidden {
+		if first.Code != "DEBUG_DISABLED" {
+			failTest(results, "LRU Order", "403 without DEBUG_DISABLED, got code "+first.Code)
+			return
+		}
+		fmt.Println("✅ LRU Order Passed - DEBUG is off, endpoint refused with 403 DEBUG_DISABLED")
+		passTest(results)
+		return
+	}
+
+	req, err := http.NewRequest("DELETE", baseURL+"/clear", nil)
+	if err != nil {
+		failTest(results, "LRU Order", err.Error())
+		return
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		failTest(results, "LRU Order", err.Error())
+		return
+	}
+	resp.Body.Close()
+	for _, key := range []string{"lru:a", "lru:b", "lru:c"} {
+		resp, err := doJSON("PUT", "/put", map[string]interface{}{"key": key, "value": key})
+		if err != nil {
+			failTest(results, "LRU Order", err.Error())
+			return
+		}
+		resp.Body.Close()
+	}
+
+	expect := func(limit int, want []string, total int) bool {
+		got, status, err := lruOrder(limit)
+		if err != nil || status != http.StatusOK {
+			failTest(results, "LRU Order", fmt.Sprintf("status %d, err %v", status, err))
+			return false
+		}
+		if fmt.Sprint(got.Keys) != fmt.Sprint(want) || got.Count != len(want) || got.Total != total {
+			failTest(results, "LRU Order", fmt.Sprintf("limit %d: got %+v, want keys %v and total %d", limit, got, want, total))
+			return false
+		}
+		return true
+	}
+
+	if !expect(10, []string{"lru:c", "lru:b", "lru:a"}, 3) {
+		return
+	}
+
+	// Reading the least recently used key moves it to the head
+	resp, err = http.Get(baseURL + "/get/lru:a")
+	if err != nil {
+		failTest(results, "LRU Order", err.Error())
+		return
+	}
+	resp.Body.Close()
+	if !expect(10, []string{"lru:a", "lru:c", "lru:b"}, 3) || !expect(2, []string{"lru:a", "lru:c"}, 3) {
+		return
+	}
+
+	fmt.Println("✅ LRU Order Passed - most recent first, a get moves the key to the head, limit respected")
+	passTest(results)
+}
+
+// testReplicationWait serves a stub peer and checks X-Wait-Replication against it: acked
+// while it answers, 0 acks after the wait timeout while it hangs
+func testReplicationWait(results *TestResults) {
+	fmt.Println("\n📋 Test 72: Replication Wait")
+
+	var mutex sync.Mutex
+	var received []string
+	hang := false
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/cache/", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		received = append(received, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-Replicated"))
+		hanging := hang
+		mutex.Unlock()
+		if hanging {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	listener, err := net.Listen("tcp", replicationStubAddr)
+	if err != nil {
+		failTest(results, "Replication Wait", "cannot serve the stub peer: "+err.Error())
+		return
+	}
+	stub := &http.Server{Handler: mux}
+	go stub.Serve(listener)
+	defer func() {
+		close(release)
+		stub.Close()
+	}()
+
+	waitedPut := func(key string) (string, time.Duration, error) {
+		jsonData, _ := json.Marshal(map[string]interface{}{"key": key, "value": 1})
+		req, err := http.NewRequest("PUT", baseURL+"/put", bytes.NewBuffer(jsonData))
+		if err != nil {
+			return "", 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Wait-Replication", "true")
+		start := time.Now()
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", 0, err
+		}
+		resp.Body.Close()
+		return resp.Header.Get("X-Replication-Acks"), time.Since(start), nil
+	}
+
+	acks, _, err := waitedPut("replication:up")
+	if err != nil {
+		failTest(results, "Replication Wait", err.Error())
+		return
+	}
+	if acks == "" {
+		fmt.Println("✅ Replication Wait Passed - no peers configured, X-Wait-Replication ignored")
+		passTest(results)
+		return
+	}
+	if acks != "1/1" {
+		failTest(results, "Replication Wait", fmt.Sprintf("acks %q with the stub peer up, want 1/1 (is %s the only peer?)", acks, replicationStubAddr))
+		return
+	}
+	mutex.Lock()
+	forwarded := strings.Join(received, "\n")
+	hang = true
+	mutex.Unlock()
+	if !strings.Contains(forwarded, "PUT /api/cache/put true") {
+		failTest(results, "Replication Wait", "stub peer did not receive the put marked X-Replicated, got:\n"+forwarded)
+		return
+	}
+
+	// A peer that never answers leaves the write unacknowledged once the wait times out
+	acks, elapsed, err := waitedPut("replication:down")
+	if err != nil {
+		failTest(results, "Replication Wait", err.Error())
+		return
+	}
+	if acks != "0/1" || elapsed < 500*time.Millisecond {
+		failTest(results, "Replication Wait", fmt.Sprintf("acks %q after %v with the stub peer hanging, want 0/1 after the wait timeout", acks, elapsed))
+		return
+	}
+
+	fmt.Printf("✅ Replication Wait Passed - 1/1 acks from the stub peer, 0/1 after %v while it hung\n", elapsed.Round(time.Millisecond))
+	passTest(results)
+}
+
+// doJSON sends a request with a JSON body to the cache API
+func doJSON(method, path string, body interface{}) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return http.DefaultClient.Do(req)
+}
+
 func passTest(results *TestResults) {
 	results.TotalTests++
 	results.PassedTests++
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/hex"
 	"time"
 
 	"github.com/Vinodbagra/cache-thread/internal/constants"
@@ -14,9 +15,118 @@ type Config struct {
 	Environment string `mapstructure:"ENVIRONMENT"`
 	Debug       bool   `mapstructure:"DEBUG"`
 
+	// HTTP Server Configuration
+	ServerReadTimeout    time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerWriteTimeout   time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	ServerIdleTimeout    time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	ServerMaxHeaderBytes int           `mapstructure:"SERVER_MAX_HEADER_BYTES"`
+
+	// Largest accepted request body, for single and bulk operations (negative disables the limit)
+	ServerMaxBodyBytes     int64 `mapstructure:"SERVER_MAX_BODY_BYTES"`
+	ServerMaxBulkBodyBytes int64 `mapstructure:"SERVER_MAX_BULK_BODY_BYTES"`
+
+	// Cache route responses of at least this many bytes are gzipped for clients that accept it (negative disables)
+	ServerGzipMinSize int `mapstructure:"SERVER_GZIP_MIN_SIZE"`
+
+	// Wrap every cache route JSON response as {"success", "data", "error"}
+	ServerResponseEnvelope bool `mapstructure:"SERVER_RESPONSE_ENVELOPE"`
+
+	// Bytes of stack logged with a panic recovered in a cache route (negative logs none)
+	ServerPanicStackBytes int `mapstructure:"SERVER_PANIC_STACK_BYTES"`
+
+	// On shutdown, how long open SSE streams get to send their final event and end
+	ServerStreamGracePeriod time.Duration `mapstructure:"SERVER_STREAM_GRACE_PERIOD"`
+
 	// Cache Configuration
 	CacheMaxSize int           `mapstructure:"CACHE_MAX_SIZE"`
 	CacheTTL     time.Duration `mapstructure:"CACHE_TTL"`
+
+	// Longest TTL a write may set (0 disables the cap), and whether longer ones are
+	// clamped to it ("clamp", the default) or rejected ("reject")
+	CacheMaxTTL     time.Duration `mapstructure:"CACHE_MAX_TTL"`
+	CacheMaxTTLMode string        `mapstructure:"CACHE_MAX_TTL_MODE"`
+
+	// Batch eviction: at the high watermark (default CACHE_MAX_SIZE) evict down to the low watermark
+	CacheHighWatermark int `mapstructure:"CACHE_HIGH_WATERMARK"`
+	CacheLowWatermark  int `mapstructure:"CACHE_LOW_WATERMARK"`
+
+	// Which entry a full cache evicts: "lru" (default), "lru-k", keeping the last
+	// CACHE_LRU_K (default 2) access times per entry, or "clock", approximating LRU
+	// without reordering entries on every access
+	CacheEvictionPolicy string `mapstructure:"CACHE_EVICTION_POLICY"`
+	CacheLRUK           int    `mapstructure:"CACHE_LRU_K"`
+
+	// Backpressure when expired entries outpace the cleanup worker
+	CacheBackpressureThreshold int    `mapstructure:"CACHE_BACKPRESSURE_THRESHOLD"`
+	CacheBackpressureMode      string `mapstructure:"CACHE_BACKPRESSURE_MODE"`
+
+	// Evictions per second, averaged over 10 seconds, above which the cache counts as
+	// thrashing (0 disables), and whether to only warn or also reject new keys then
+	CacheThrashThreshold float64 `mapstructure:"CACHE_THRASH_THRESHOLD"`
+	CacheThrashMode      string  `mapstructure:"CACHE_THRASH_MODE"`
+
+	// Hex-encoded AES key (16, 24 or 32 bytes) for encrypting values at rest
+	CacheEncryptionKey string `mapstructure:"CACHE_ENCRYPTION_KEY"`
+
+	// Number of recent mutating operations kept in the operation log
+	CacheOpLogSize int `mapstructure:"CACHE_OPLOG_SIZE"`
+
+	// Keys longer than this many bytes are stored as their SHA-256 digest (0 disables)
+	CacheKeyHashThreshold int `mapstructure:"CACHE_KEY_HASH_THRESHOLD"`
+
+	// CORS: comma-separated allowlists. An empty origin list disables CORS; "*" allows any origin.
+	CORSAllowedOrigins string `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowedMethods string `mapstructure:"CORS_ALLOWED_METHODS"`
+	CORSAllowedHeaders string `mapstructure:"CORS_ALLOWED_HEADERS"`
+
+	// Comma-separated cache API base URLs of peers that successful writes are forwarded to,
+	// e.g. "http://10.0.0.2:8080/api/cache" (empty disables replication)
+	CacheReplicationPeers string `mapstructure:"CACHE_REPLICATION_PEERS"`
+
+	// Longest a write sent with X-Wait-Replication waits for a quorum of peers (default 2s)
+	CacheReplicationWaitTimeout time.Duration `mapstructure:"CACHE_REPLICATION_WAIT_TIMEOUT"`
+
+	// Read-only replicas answer reads and reject every write
+	CacheReadOnly bool `mapstructure:"CACHE_READ_ONLY"`
+
+	// Environment variables named with this prefix are stored at startup, e.g.
+	// CACHE_SEED_foo=bar as key "foo" with CACHE_SEED_ (empty disables seeding)
+	CacheSeedPrefix string `mapstructure:"CACHE_SEED_PREFIX"`
+
+	// Expired entries are still served, flagged stale, for this long past expiration (0 disables)
+	CacheStaleWhileRevalidate time.Duration `mapstructure:"CACHE_STALE_WHILE_REVALIDATE"`
+
+	// Rebuild the key map once this fraction of its peak size has been deleted (0 disables)
+	CacheCompactThreshold float64 `mapstructure:"CACHE_COMPACT_THRESHOLD"`
+
+	// Every Get hit extends a key's expiration by the step, up to max remaining TTL (step 0 disables)
+	CacheTTLPromotionStep time.Duration `mapstructure:"CACHE_TTL_PROMOTION_STEP"`
+	CacheTTLPromotionMax  time.Duration `mapstructure:"CACHE_TTL_PROMOTION_MAX"`
+
+	// The first background cleanup is delayed by a random duration up to this long (0 disables)
+	CacheCleanupJitter time.Duration `mapstructure:"CACHE_CLEANUP_JITTER"`
+
+	// Don't run the background cleanup worker; expired keys linger until accessed or evicted
+	CacheCleanupDisabled bool `mapstructure:"CACHE_CLEANUP_DISABLED"`
+
+	// Serve get hits under the read lock and apply their LRU moves in batches
+	CacheBatchPromotions bool `mapstructure:"CACHE_BATCH_PROMOTIONS"`
+
+	// Entries kept in a lock-striped hot tier in front of the cache for gets (0 disables it)
+	CacheHotTierSize int `mapstructure:"CACHE_HOT_TIER_SIZE"`
+
+	// Checksum every value on write and verify it on get, removing values changed in memory
+	CacheChecksumValues bool `mapstructure:"CACHE_CHECKSUM_VALUES"`
+
+	// Interval between frames on the /stats/stream SSE endpoint
+	CacheStatsStreamInterval time.Duration `mapstructure:"CACHE_STATS_STREAM_INTERVAL"`
+
+	// Write-behind persistence: snapshot file (empty disables; the log is written beside it
+	// with a .log suffix), how often mutations are appended to the log, and how often the
+	// log is folded into a fresh snapshot
+	CachePersistPath            string        `mapstructure:"CACHE_PERSIST_PATH"`
+	CachePersistInterval        time.Duration `mapstructure:"CACHE_PERSIST_INTERVAL"`
+	CachePersistCompactInterval time.Duration `mapstructure:"CACHE_PERSIST_COMPACT_INTERVAL"`
 }
 
 func InitializeAppConfig() error {
@@ -27,6 +137,9 @@ func InitializeAppConfig() error {
 	viper.AddConfigPath("/")
 	viper.AllowEmptyEnv(true)
 	viper.AutomaticEnv()
+	// AutomaticEnv only overrides keys present in the config file; the seed prefix is
+	// usually set beside the seed variables in the environment, so bind it explicitly
+	viper.BindEnv("CACHE_SEED_PREFIX")
 	err := viper.ReadInConfig()
 	if err != nil {
 		return constants.ErrLoadConfig
@@ -42,6 +155,43 @@ func InitializeAppConfig() error {
 		return constants.ErrEmptyVar
 	}
 
+	// Set default server values if not provided
+	if AppConfig.ServerReadTimeout <= 0 {
+		AppConfig.ServerReadTimeout = 10 * time.Second
+	}
+	if AppConfig.ServerWriteTimeout <= 0 {
+		AppConfig.ServerWriteTimeout = 10 * time.Second
+	}
+	if AppConfig.ServerIdleTimeout <= 0 {
+		AppConfig.ServerIdleTimeout = 60 * time.Second
+	}
+	if AppConfig.ServerMaxHeaderBytes <= 0 {
+		AppConfig.ServerMaxHeaderBytes = 1 << 20
+	}
+	if AppConfig.ServerMaxBodyBytes == 0 {
+		AppConfig.ServerMaxBodyBytes = 1 << 20
+	}
+	if AppConfig.ServerMaxBulkBodyBytes == 0 {
+		AppConfig.ServerMaxBulkBodyBytes = 10 << 20
+	}
+	if AppConfig.ServerGzipMinSize == 0 {
+		AppConfig.ServerGzipMinSize = 1024
+	}
+	if AppConfig.ServerPanicStackBytes == 0 {
+		AppConfig.ServerPanicStackBytes = 8 << 10
+	}
+	if AppConfig.ServerStreamGracePeriod <= 0 {
+		AppConfig.ServerStreamGracePeriod = 2 * time.Second
+	}
+
+	// Set default CORS values if not provided
+	if AppConfig.CORSAllowedMethods == "" {
+		AppConfig.CORSAllowedMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	}
+	if AppConfig.CORSAllowedHeaders == "" {
+		AppConfig.CORSAllowedHeaders = "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization"
+	}
+
 	// Set default cache values if not provided
 	if AppConfig.CacheMaxSize == 0 {
 		AppConfig.CacheMaxSize = 1000 // Default max size
@@ -49,6 +199,88 @@ func InitializeAppConfig() error {
 	if AppConfig.CacheTTL == 0 {
 		AppConfig.CacheTTL = 30 * time.Minute // Default TTL
 	}
+	if AppConfig.CacheStatsStreamInterval <= 0 {
+		AppConfig.CacheStatsStreamInterval = 5 * time.Second
+	}
+	if AppConfig.CachePersistInterval == 0 {
+		AppConfig.CachePersistInterval = time.Second
+	}
+	if AppConfig.CachePersistCompactInterval == 0 {
+		AppConfig.CachePersistCompactInterval = 5 * time.Minute
+	}
+	if AppConfig.CacheReplicationWaitTimeout == 0 {
+		AppConfig.CacheReplicationWaitTimeout = 2 * time.Second
+	}
+	if AppConfig.CachePersistInterval < 0 || AppConfig.CachePersistCompactInterval < 0 || AppConfig.CacheReplicationWaitTimeout < 0 {
+		return constants.ErrInvalidVar
+	}
+	if AppConfig.CacheOpLogSize == 0 {
+		AppConfig.CacheOpLogSize = 1000 // Default operation log size
+	}
+	highWatermark := AppConfig.CacheHighWatermark
+	if highWatermark == 0 {
+		highWatermark = AppConfig.CacheMaxSize
+	}
+	if highWatermark > AppConfig.CacheMaxSize || AppConfig.CacheLowWatermark > highWatermark {
+		return constants.ErrInvalidVar
+	}
+	if AppConfig.CacheTTLPromotionStep > 0 && AppConfig.CacheTTLPromotionMax < AppConfig.CacheTTLPromotionStep {
+		return constants.ErrInvalidVar
+	}
+	if AppConfig.CacheMaxTTL < 0 {
+		return constants.ErrInvalidVar
+	}
+	if AppConfig.CacheMaxTTL > 0 && (AppConfig.CacheTTL > AppConfig.CacheMaxTTL || AppConfig.CacheTTLPromotionMax > AppConfig.CacheMaxTTL) {
+		return constants.ErrInvalidVar
+	}
+	switch AppConfig.CacheMaxTTLMode {
+	case "":
+		AppConfig.CacheMaxTTLMode = constants.MaxTTLModeClamp
+	case constants.MaxTTLModeClamp, constants.MaxTTLModeReject:
+	default:
+		return constants.ErrInvalidVar
+	}
+	switch AppConfig.CacheEvictionPolicy {
+	case "":
+		AppConfig.CacheEvictionPolicy = constants.EvictionPolicyLRU
+	case constants.EvictionPolicyLRU, constants.EvictionPolicyLRUK, constants.EvictionPolicyClock:
+	default:
+		return constants.ErrInvalidVar
+	}
+	if AppConfig.CacheLRUK < 0 {
+		return constants.ErrInvalidVar
+	}
+	if AppConfig.CacheHotTierSize < 0 {
+		return constants.ErrInvalidVar
+	}
+	switch AppConfig.CacheBackpressureMode {
+	case "":
+		AppConfig.CacheBackpressureMode = constants.BackpressureModeCleanup
+	case constants.BackpressureModeCleanup, constants.BackpressureModeReject:
+	default:
+		return constants.ErrInvalidVar
+	}
+	if AppConfig.CacheThrashThreshold < 0 {
+		return constants.ErrInvalidVar
+	}
+	switch AppConfig.CacheThrashMode {
+	case "":
+		AppConfig.CacheThrashMode = constants.ThrashModeWarn
+	case constants.ThrashModeWarn, constants.ThrashModeReject:
+	default:
+		return constants.ErrInvalidVar
+	}
+	if AppConfig.CacheEncryptionKey != "" {
+		key, err := hex.DecodeString(AppConfig.CacheEncryptionKey)
+		if err != nil {
+			return constants.ErrInvalidVar
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return constants.ErrInvalidVar
+		}
+	}
 
 	// Database validation (only if environment requires it)
 	switch AppConfig.Environment {
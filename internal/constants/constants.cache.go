@@ -0,0 +1,42 @@
+package constants
+
+const (
+	// Put behaviour when expired entries outpace the cleanup worker
+	BackpressureModeCleanup = "cleanup" // reap expired entries inline before inserting
+	BackpressureModeReject  = "reject"  // fail the Put with ErrCacheBusy
+)
+
+const (
+	// What the cache does while evictions outpace the thrash threshold
+	ThrashModeWarn   = "warn"   // report it in stats and the log only
+	ThrashModeReject = "reject" // also fail Puts of new keys with ErrThrashing
+)
+
+const (
+	// What a write asking for a TTL longer than the configured maximum gets
+	MaxTTLModeClamp  = "clamp"  // store it with the maximum TTL instead
+	MaxTTLModeReject = "reject" // fail the write with ErrTTLTooLong
+)
+
+const (
+	// Buckets of the remaining-TTL histogram in detailed stats
+	TTLBucketUnderMinute = "<1m"
+	TTLBucketUnderTenMin = "1-10m"
+	TTLBucketUnderHour   = "10m-1h"
+	TTLBucketOverHour    = ">1h"
+	TTLBucketNone        = "none" // entries without an expiration
+)
+
+const (
+	// Which entry a full cache evicts to make room
+	EvictionPolicyLRU   = "lru"   // the least recently used
+	EvictionPolicyLRUK  = "lru-k" // the one whose K-th most recent access is oldest
+	EvictionPolicyClock = "clock" // approximately the least recently used, by second chance
+)
+
+const (
+	// How an import resolves keys that already hold a live value
+	ImportStrategyOverwrite    = "overwrite"     // replace the existing value
+	ImportStrategySkipExisting = "skip-existing" // keep the existing value
+	ImportStrategyKeepNewer    = "keep-newer"    // keep whichever has the later created_at
+)
@@ -7,9 +7,27 @@ var (
 	ErrUnexpected = errors.New("unexpected error")
 
 	// entity
+	ErrCacheBusy   = errors.New("cache busy: expired entries are not being reaped fast enough")
+	ErrThrashing   = errors.New("cache is thrashing: new keys are rejected until evictions slow down")
+	ErrDraining    = errors.New("cache is draining and no longer accepts writes")
+	ErrReadOnly    = errors.New("cache is read-only and does not accept writes")
+	ErrKeyNotFound = errors.New("key not found")
+	ErrKeyExists   = errors.New("key already exists")
+	ErrKeyEmpty    = errors.New("key cannot be empty")
+
+	ErrPatternEmpty   = errors.New("pattern cannot be empty")
+	ErrInvalidPattern = errors.New("invalid pattern")
+	ErrUnserializable = errors.New("value cannot be serialized")
+
+	ErrConflictingExpiration = errors.New("conflicting expiration")
+	ErrAlreadyFrozen         = errors.New("cache is already frozen")
+	ErrInvalidStrategy       = errors.New("unknown import strategy")
+	ErrNegativeTTL           = errors.New("ttl cannot be negative")
+	ErrTTLTooLong            = errors.New("ttl exceeds the maximum allowed")
 
 	// config
 	ErrLoadConfig  = errors.New("failed to load config file")
 	ErrParseConfig = errors.New("failed to parse env to config struct")
 	ErrEmptyVar    = errors.New("required variabel environment is empty")
+	ErrInvalidVar  = errors.New("variabel environment has an invalid value")
 )
\ No newline at end of file
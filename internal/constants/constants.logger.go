@@ -9,6 +9,17 @@ const (
 	LoggerCategoryMigration = "migration"
 	LoggerCategoryCORS      = "cors"
 	LoggerCategorySeeder    = "seeder"
+	LoggerCategoryCache     = "cache"
+
+	LoggerCategoryReplication = "replication"
+
+	// LoggerRequestID is the log field, and gin context key, carrying the request's correlation ID
+	LoggerRequestID = "request_id"
+
+	// Fields logged with a recovered panic: the operation, key and stack it happened in
+	LoggerOperation = "op"
+	LoggerKey       = "key"
+	LoggerStack     = "stack"
 
 	LoggerFile = "file"
 )
\ No newline at end of file
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter holds back the whole response so a middleware can rewrite it once the
+// handler is done. A Flush (as done by SSE endpoints) writes out what was buffered and
+// switches to pass-through for the rest of the response, so streams are not held back.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	buffer      bytes.Buffer
+	status      int
+	passthrough bool
+}
+
+// newBufferedWriter starts buffering the response about to be written to w
+func newBufferedWriter(w gin.ResponseWriter) bufferedWriter {
+	return bufferedWriter{ResponseWriter: w, status: w.Status()}
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+// WriteHeaderNow is deferred until the buffered body is written out
+func (w *bufferedWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.buffer.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.buffer.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends what has been buffered as is and stops buffering
+func (w *bufferedWriter) Flush() {
+	if !w.passthrough {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.Write(w.buffer.Bytes())
+		w.buffer.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *bufferedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
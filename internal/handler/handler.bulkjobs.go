@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Finished jobs can be polled for this long before they are forgotten
+	bulkJobTTL     = time.Hour
+	bulkJobMaxJobs = 1000
+
+	// Async bulk puts are applied in chunks of this many items so progress can be reported
+	bulkJobChunkSize = 100
+)
+
+// Bulk job states
+const (
+	BulkJobRunning   = "running"
+	BulkJobCompleted = "completed"
+)
+
+// bulkJob tracks an async bulk put; the registry stores pointers, so fields are guarded by mutex
+type bulkJob struct {
+	mutex  sync.Mutex
+	status models.BulkJobResponse
+}
+
+// errTooManyBulkJobs is returned when bulkJobMaxJobs jobs are still running
+var errTooManyBulkJobs = errors.New("too many bulk jobs running")
+
+// bulkJobStore holds async bulk put jobs by ID. Running jobs are never dropped; finished
+// ones are pruned bulkJobTTL after completion, or earlier, oldest first, to make room.
+type bulkJobStore struct {
+	mutex sync.Mutex
+	jobs  map[string]*bulkJob
+}
+
+// newBulkJobStore creates an empty store for async bulk put jobs
+func newBulkJobStore() *bulkJobStore {
+	return &bulkJobStore{jobs: make(map[string]*bulkJob)}
+}
+
+// get returns the job with id, unless it finished over bulkJobTTL ago
+func (s *bulkJobStore) get(id string) (*bulkJob, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, found := s.jobs[id]
+	if !found || job.expired(time.Now()) {
+		return nil, false
+	}
+	return job, true
+}
+
+// add registers job, pruning finished jobs to stay within bulkJobMaxJobs. It fails with
+// errTooManyBulkJobs when every job held is still running.
+func (s *bulkJobStore) add(job *bulkJob) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	var oldestID string
+	var oldest time.Time
+	for id, held := range s.jobs {
+		completedAt := held.completedAt()
+		switch {
+		case completedAt == nil:
+		case held.expired(now):
+			delete(s.jobs, id)
+		case oldestID == "" || completedAt.Before(oldest):
+			oldestID, oldest = id, *completedAt
+		}
+	}
+	if len(s.jobs) >= bulkJobMaxJobs {
+		if oldestID == "" {
+			return errTooManyBulkJobs
+		}
+		delete(s.jobs, oldestID)
+	}
+
+	s.jobs[job.status.ID] = job
+	return nil
+}
+
+// completedAt returns when the job finished, or nil while it is running
+func (j *bulkJob) completedAt() *time.Time {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.status.CompletedAt
+}
+
+// expired reports whether the job finished over bulkJobTTL before now
+func (j *bulkJob) expired(now time.Time) bool {
+	completedAt := j.completedAt()
+	return completedAt != nil && now.Sub(*completedAt) >= bulkJobTTL
+}
+
+// snapshot returns a copy of the job's current status
+func (j *bulkJob) snapshot() models.BulkJobResponse {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	status := j.status
+	status.Errors = append([]string(nil), j.status.Errors...)
+	return status
+}
+
+// startBulkJob registers a job for req and applies it in the background. Unless the request
+// was itself replicated, each applied chunk is forwarded to the peers; waiting for them is
+// not supported, as the response goes out before anything is applied.
+func (ch *CacheHandler) startBulkJob(c *gin.Context, req models.BulkPutRequest) (models.BulkJobResponse, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return models.BulkJobResponse{}, err
+	}
+
+	job := &bulkJob{status: models.BulkJobResponse{
+		ID:          hex.EncodeToString(id),
+		Status:      BulkJobRunning,
+		Total:       len(req.Items),
+		SubmittedAt: time.Now(),
+	}}
+	if err := ch.bulkJobs.add(job); err != nil {
+		return models.BulkJobResponse{}, err
+	}
+
+	forward := c.GetHeader(ReplicatedHeader) != "true"
+	go ch.runBulkJob(job, req, c.GetString(constants.LoggerRequestID), forward)
+
+	return job.snapshot(), nil
+}
+
+// runBulkJob applies the items chunk by chunk, updating the job's progress after each and,
+// with forward, replicating every chunk that stored anything as a synchronous bulk put
+func (ch *CacheHandler) runBulkJob(job *bulkJob, req models.BulkPutRequest, requestID string, forward bool) {
+	for start := 0; start < len(req.Items); start += bulkJobChunkSize {
+		end := min(start+bulkJobChunkSize, len(req.Items))
+		chunk := models.BulkPutRequest{Items: req.Items[start:end], TTL: req.TTL}
+		result := ch.cacheService.BulkPut(chunk.Items, chunk.TTL)
+		if forward && result.Successful > 0 {
+			ch.options.Replicator.enqueue(requestID, http.MethodPost, "/bulk/put", chunk, false)
+		}
+
+		job.mutex.Lock()
+		job.status.Processed = end
+		job.status.Successful += result.Successful
+		job.status.Failed += result.Failed
+		job.status.Errors = append(job.status.Errors, result.Errors...)
+		job.mutex.Unlock()
+	}
+
+	job.mutex.Lock()
+	completedAt := time.Now()
+	job.status.Status = BulkJobCompleted
+	job.status.CompletedAt = &completedAt
+	job.mutex.Unlock()
+}
+
+// GetBulkJob handles requests for the progress of an async bulk put
+// @Summary Get async bulk put job
+// @Description Report progress and, once completed, final counts of a bulk put submitted with async=true
+// @Tags cache
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.BulkJobResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/cache/bulk/jobs/{id} [get]
+func (ch *CacheHandler) GetBulkJob(c *gin.Context) {
+	id := pathParam(c, "id")
+	job, found := ch.bulkJobs.get(id)
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Job not found",
+			Code:    "JOB_NOT_FOUND",
+			Message: "No bulk job with id '" + id + "' (finished jobs are kept for an hour)",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job.snapshot())
+}
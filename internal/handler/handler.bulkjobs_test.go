@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestJob returns a job with id, completed at completedAt or running when it is zero
+func newTestJob(id string, completedAt time.Time) *bulkJob {
+	job := &bulkJob{status: models.BulkJobResponse{ID: id, Status: BulkJobRunning}}
+	if !completedAt.IsZero() {
+		job.status.Status = BulkJobCompleted
+		job.status.CompletedAt = &completedAt
+	}
+	return job
+}
+
+func TestBulkJobStoreKeepsRunningJobs(t *testing.T) {
+	s := newBulkJobStore()
+	for i := 0; i < bulkJobMaxJobs; i++ {
+		if err := s.add(newTestJob(fmt.Sprintf("run%d", i), time.Time{})); err != nil {
+			t.Fatalf("adding job %d: %v", i, err)
+		}
+	}
+	if err := s.add(newTestJob("extra", time.Time{})); !errors.Is(err, errTooManyBulkJobs) {
+		t.Fatalf("add past the cap of running jobs = %v, want errTooManyBulkJobs", err)
+	}
+	for i := 0; i < bulkJobMaxJobs; i++ {
+		if _, found := s.get(fmt.Sprintf("run%d", i)); !found {
+			t.Fatalf("running job run%d dropped", i)
+		}
+	}
+
+	// Once one finishes, it makes room for the next
+	completedAt := time.Now()
+	s.jobs["run0"].status.CompletedAt = &completedAt
+	if err := s.add(newTestJob("extra", time.Time{})); err != nil {
+		t.Fatalf("add after a job finished: %v", err)
+	}
+	if _, found := s.get("run0"); found {
+		t.Fatal("finished job kept although the store was full")
+	}
+}
+
+func TestBulkJobStorePrunesFinishedJobs(t *testing.T) {
+	s := newBulkJobStore()
+	s.add(newTestJob("old", time.Now().Add(-bulkJobTTL-time.Minute)))
+	s.add(newTestJob("recent", time.Now().Add(-time.Minute)))
+	s.add(newTestJob("running", time.Time{}))
+
+	if _, found := s.get("old"); found {
+		t.Fatal("job finished past the TTL still served")
+	}
+	s.add(newTestJob("next", time.Time{}))
+	if _, exists := s.jobs["old"]; exists {
+		t.Fatal("job finished past the TTL not pruned")
+	}
+	for _, id := range []string{"recent", "running", "next"} {
+		if _, found := s.get(id); !found {
+			t.Fatalf("job %s missing", id)
+		}
+	}
+}
+
+func TestAsyncBulkPutCompletes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cs := service.NewCacheService(1000, 0)
+	defer cs.Close()
+	ch := NewCacheHandler(cs, HandlerOptions{})
+	r := gin.New()
+	r.POST("/bulk/put", ch.BulkPut)
+	r.GET("/bulk/jobs/:id", ch.GetBulkJob)
+
+	items := make([]string, 250)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"key":"k%d","value":%d}`, i, i)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/bulk/put?async=true", strings.NewReader(`{"items":[`+strings.Join(items, ",")+`]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("async bulk put returned %d: %s", w.Code, w.Body)
+	}
+	var job models.BulkJobResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != BulkJobCompleted {
+		if time.Now().After(deadline) {
+			t.Fatalf("job still %s after 5s: %+v", job.Status, job)
+		}
+		time.Sleep(10 * time.Millisecond)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bulk/jobs/"+job.ID, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("polling job returned %d: %s", w.Code, w.Body)
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if job.Processed != 250 || job.Successful != 250 || job.Failed != 0 || job.CompletedAt == nil {
+		t.Fatalf("completed job %+v, want all 250 items stored", job)
+	}
+	if entry, found := cs.Get("k249"); !found || entry.GetValue() != 249.0 {
+		t.Fatalf("k249 = %v, %v; want 249", entry, found)
+	}
+}
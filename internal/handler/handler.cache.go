@@ -1,27 +1,975 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
+	"github.com/Vinodbagra/cache-thread/internal/constants"
 	"github.com/Vinodbagra/cache-thread/internal/models"
 	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/Vinodbagra/cache-thread/pkg/jsonpath"
+	"github.com/Vinodbagra/cache-thread/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
 
+// maxScanCount caps the page size of /scan and /expiring, and the size of /sample and /debug/lru-order
+const maxScanCount = 1000
 
+// HandlerOptions holds HTTP-level settings for the cache handlers
+type HandlerOptions struct {
+	StatsStreamInterval time.Duration // default interval between /stats/stream frames
+	GzipMinSize         int           // smallest response body worth gzipping (0 disables compression)
+	Replicator          *Replicator   // forwards successful writes to peers (nil disables replication)
+	MaxBodyBytes        int64         // largest accepted request body (0 disables the limit)
+	MaxBulkBodyBytes    int64         // largest accepted body on bulk routes (0 disables the limit)
+	ResponseEnvelope    bool          // wrap every JSON response in models.Envelope
+	PanicStackBytes     int           // stack logged with a recovered panic, truncated to this size (0 logs none)
+	Debug               bool          // serve debug endpoints that expose cache internals
+	HotTierSize         int           // entries kept in a hot tier serving gets and bulk gets (0 disables it)
+}
+
+// cacheReader serves gets and bulk gets: the cache itself, or a hot tier in front of it
+type cacheReader interface {
+	Get(key string) (*models.CacheEntry, bool)
+	BulkGet(keys []string, defaultValue interface{}) models.BulkGetResponse
+}
 
 type CacheHandler struct {
 	cacheService *service.CacheService
+	reads        cacheReader
+	idempotency  *idempotencyStore // recently seen Idempotency-Key responses
+	bulkJobs     *bulkJobStore     // async bulk put jobs by ID
+	options      HandlerOptions
+	httpStats    *httpStats     // per-route request metrics recorded by RecordHTTPStats
+	streams      *streamTracker // open SSE streams, ended by CloseStreams on shutdown
+}
+
+func NewCacheHandler(cacheService *service.CacheService, options HandlerOptions) *CacheHandler {
+	if options.StatsStreamInterval <= 0 {
+		options.StatsStreamInterval = 5 * time.Second
+	}
+
+	var reads cacheReader = cacheService
+	if options.HotTierSize > 0 {
+		reads = service.NewTieredCacheService(cacheService, options.HotTierSize)
+	}
+
+	return &CacheHandler{
+		cacheService: cacheService,
+		reads:        reads,
+		idempotency:  newIdempotencyStore(),
+		bulkJobs:     newBulkJobStore(),
+		options:      options,
+		httpStats:    &httpStats{},
+		streams:      newStreamTracker(),
+	}
+}
+
+func (ch *CacheHandler) Put(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	// A retry carrying a known Idempotency-Key gets the original response without re-executing
+	if ch.replayIdempotent(c) {
+		return
+	}
+
+	// With raw_value the value is kept as the JSON text the client sent: pointing Value at a
+	// json.RawMessage makes the decoder fill it in instead of building an interface{} tree
+	rawValue, _ := strconv.ParseBool(c.Query("raw_value"))
+	var raw json.RawMessage
+	var req models.PutRequest
+	if rawValue {
+		req.Value = &raw
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+	if rawValue {
+		if len(raw) == 0 || string(raw) == "null" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid request body",
+				Code:    "INVALID_REQUEST",
+				Message: "value is required",
+			})
+			return
+		}
+		req.Value = raw
+	}
+
+	returnEvicted, _ := strconv.ParseBool(c.Query("return_evicted"))
+	var created bool
+	var evicted []models.GetResponse
+	var err error
+	if returnEvicted {
+		created, evicted, err = ch.cacheService.PutItemEvicting(req, nil)
+	} else {
+		created, err = ch.cacheService.PutItem(req, nil)
+	}
+	if err != nil {
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Failed to store key-value pair",
+			Code:  "PUT_FAILED",
+		})
+		return
+	}
+
+	response := gin.H{
+		"message": "Key-value pair stored successfully",
+		"key":     req.Key,
+		"ttl":     req.TTL,
+	}
+	if req.TTL != nil && *req.TTL > 0 {
+		// Report the TTL actually stored when it was clamped to the maximum
+		if ttl, err := ch.cacheService.CapTTL(time.Duration(*req.TTL) * time.Second); err == nil {
+			response["ttl"] = int(ttl / time.Second)
+		}
+	}
+	if returnEvicted {
+		response["evicted"] = evicted
+	}
+	if req.ExpireAt != nil {
+		response["expire_at"] = req.ExpireAt.UTC().Format(time.RFC3339)
+	}
+
+	// 201 for a new key, 200 when an existing key was overwritten
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	ch.recordIdempotent(c, status, response)
+	ch.replicate(c, http.MethodPut, "/put"+queryString(c), req)
+	c.JSON(status, response)
+}
+
+// Get handles GET requests to retrieve values by key
+// @Summary Get value by key
+// @Description Retrieve a value from cache by key
+// @Tags cache
+// @Produce json,application/msgpack
+// @Param key path string false "Cache key; slashes and special characters must be percent-encoded"
+// @Param key query string false "Cache key, used when the path does not carry one"
+// @Param path query string false "JSONPath selecting part of the value, e.g. $.user.name"
+// @Param If-Modified-Since header string false "Return 304 if the value has not been written since this HTTP date"
+// @Success 200 {object} models.GetResponse
+// @Success 304 "Not modified since If-Modified-Since"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/cache/get/{key} [get]
+// @Router /api/v1/cache/get [get]
+func (ch *CacheHandler) Get(c *gin.Context) {
+	key := keyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Key parameter is required",
+			Code:    "MISSING_KEY",
+			Message: "Please provide a valid key parameter",
+		})
+		return
+	}
+
+	entry, found := ch.reads.Get(key)
+	if !found {
+		respondValue(c, http.StatusNotFound, key, models.GetResponse{
+			Key:   key,
+			Found: false,
+		})
+		return
+	}
+
+	// HTTP dates have second precision, so compare at that granularity
+	modifiedAt := entry.ModifiedAt.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", modifiedAt.Format(http.TimeFormat))
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !modifiedAt.After(since) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	response := entry.ToResponse()
+	response.Key = key // report the client key even if it is stored hashed
+
+	// A value stored with raw_value is written back as is, unless it has to be walked or
+	// re-encoded as MessagePack
+	if raw, ok := response.Value.(json.RawMessage); ok && (c.Query("path") != "" || wantsMsgPack(c)) {
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			unserializable(c, key, err)
+			return
+		}
+		response.Value = decoded
+	}
+
+	// Optionally return only the part of the value selected by a JSONPath expression
+	if path := c.Query("path"); path != "" {
+		value, err := jsonpath.Lookup(response.Value, path)
+		if errors.Is(err, jsonpath.ErrInvalidPath) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid path",
+				Code:    "INVALID_PATH",
+				Message: err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Error:   "Path not found",
+				Code:    "PATH_NOT_FOUND",
+				Message: fmt.Sprintf("path '%s' did not match the value of key '%s'", path, key),
+			})
+			return
+		}
+		response.Value = value
+		response.Path = path
+	}
+
+	respondValue(c, http.StatusOK, key, response)
+}
+
+// GetDefault handles requests returning a key's value, storing the given default if it is missing
+// @Summary Get value or store default
+// @Description Return the existing value, or atomically store the default and return it
+// @Tags cache
+// @Accept json
+// @Produce json
+// @Param key path string false "Cache key; slashes and special characters must be percent-encoded"
+// @Param key query string false "Cache key, used when the path does not carry one"
+// @Param request body models.GetDefaultRequest true "Default value and optional TTL"
+// @Success 200 {object} models.GetDefaultResponse
+// @Success 201 {object} models.GetDefaultResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/getdefault/{key} [post]
+// @Router /api/v1/cache/getdefault [post]
+func (ch *CacheHandler) GetDefault(c *gin.Context) {
+	key := keyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Key parameter is required",
+			Code:    "MISSING_KEY",
+			Message: "Please provide a valid key parameter",
+		})
+		return
+	}
+
+	var req models.GetDefaultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var ttl *time.Duration
+	if req.TTL != nil && *req.TTL > 0 {
+		duration := time.Duration(*req.TTL) * time.Second
+		ttl = &duration
+	}
+
+	entry, created, err := ch.cacheService.GetOrPut(key, req.Default, ttl)
+	if err != nil {
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Failed to get or store default",
+			Code:  "GET_DEFAULT_FAILED",
+		})
+		return
+	}
+
+	response := models.GetDefaultResponse{GetResponse: entry.ToResponse(), Created: created}
+	response.Key = key // report the client key even if it is stored hashed
+
+	// 201 when the default was stored, 200 when an existing value was returned
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		// Peers store the default outright, so they converge on this node's value
+		ch.replicate(c, http.MethodPut, "/put", models.PutRequest{Key: key, Value: req.Default, TTL: req.TTL})
+	}
+	respondValue(c, status, key, response)
+}
+
+// GetInfo handles GET requests for per-key access statistics
+// @Summary Get key info
+// @Description Retrieve creation time, last access, access count and remaining TTL without affecting LRU order
+// @Tags cache
+// @Produce json
+// @Param key path string false "Cache key; slashes and special characters must be percent-encoded"
+// @Param key query string false "Cache key, used when the path does not carry one"
+// @Success 200 {object} models.KeyInfoResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/cache/info/{key} [get]
+// @Router /api/v1/cache/info [get]
+func (ch *CacheHandler) GetInfo(c *gin.Context) {
+	key := keyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Key parameter is required",
+			Code:    "MISSING_KEY",
+			Message: "Please provide a valid key parameter",
+		})
+		return
+	}
+
+	info, found := ch.cacheService.GetInfo(key)
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Key not found",
+			Code:    "KEY_NOT_FOUND",
+			Message: "No entry exists for key " + key,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// Compare handles requests diffing the values of two keys
+// @Summary Compare two keys
+// @Description Report whether the values of keys a and b are deeply equal, with both values, without affecting LRU order
+// @Tags cache
+// @Produce json
+// @Param a query string true "First key"
+// @Param b query string true "Second key"
+// @Success 200 {object} models.CompareResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/cache/compare [get]
+func (ch *CacheHandler) Compare(c *gin.Context) {
+	a, b := c.Query("a"), c.Query("b")
+	if a == "" || b == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Keys are required",
+			Code:    "MISSING_KEY",
+			Message: "Please provide both the a and b query parameters",
+		})
+		return
+	}
+
+	comparison, err := ch.cacheService.Compare(a, b)
+	if err != nil {
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Failed to compare keys",
+			Code:  "COMPARE_FAILED",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// Delete handles DELETE requests to remove keys
+// @Summary Delete key from cache
+// @Description Remove a key-value pair from cache; with a body {"expected": ...} only if the value still matches
+// @Tags cache
+// @Accept json
+// @Produce json
+// @Param key path string false "Cache key; slashes and special characters must be percent-encoded"
+// @Param key query string false "Cache key, used when the path does not carry one"
+// @Param request body models.DeleteIfRequest false "Expected value for a conditional delete"
+// @Success 200 {object} models.DeleteResponse
+// @Failure 404 {object} models.DeleteResponse
+// @Failure 409 {object} models.DeleteResponse
+// @Router /api/v1/cache/delete/{key} [delete]
+// @Router /api/v1/cache/delete [delete]
+func (ch *CacheHandler) Delete(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	key := keyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Key parameter is required",
+			Code:    "MISSING_KEY",
+			Message: "Please provide a valid key parameter",
+		})
+		return
+	}
+
+	var deleted, found bool
+	if c.Request.ContentLength != 0 {
+		// A body makes this a conditional delete
+		var req models.DeleteIfRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.Expected == nil {
+			message := "expected value is required"
+			if err != nil {
+				message = err.Error()
+			}
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid request body",
+				Code:    "INVALID_REQUEST",
+				Message: message,
+			})
+			return
+		}
+		deleted, found = ch.cacheService.DeleteIf(key, req.Expected)
+	} else {
+		deleted, found = ch.cacheService.Delete(key)
+	}
+
+	response := models.DeleteResponse{
+		Key:     key,
+		Deleted: deleted,
+		Found:   found,
+	}
+
+	switch {
+	case deleted:
+		// Peers just drop the key; the expected-value check already passed here
+		ch.replicate(c, http.MethodDelete, "/delete/"+url.PathEscape(key), nil)
+		c.JSON(http.StatusOK, response)
+	case found:
+		// The key exists but no longer holds the expected value
+		c.JSON(http.StatusConflict, response)
+	default:
+		c.JSON(http.StatusNotFound, response)
+	}
+}
+
+// DeleteByPattern handles DELETE requests removing every key matching a glob pattern
+// @Summary Delete keys by pattern
+// @Description Remove every key matching a glob pattern such as user:*:temp
+// @Tags cache
+// @Produce json
+// @Param p query string true "Glob pattern"
+// @Success 200 {object} models.DeletePatternResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/pattern [delete]
+func (ch *CacheHandler) DeleteByPattern(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	pattern := c.Query("p")
+	deleted, err := ch.cacheService.DeleteByPattern(pattern)
+	if err != nil {
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Failed to delete by pattern",
+			Code:  "DELETE_PATTERN_FAILED",
+		})
+		return
+	}
+
+	if deleted > 0 {
+		ch.replicate(c, http.MethodDelete, "/pattern"+queryString(c), nil)
+	}
+	c.JSON(http.StatusOK, models.DeletePatternResponse{
+		Pattern: pattern,
+		Deleted: deleted,
+	})
+}
+
+// DeleteByHierarchy handles DELETE requests removing every key matching a segment pattern
+// @Summary Delete keys by hierarchy
+// @Description Remove every key whose ":"-separated segments match the pattern, where a "*" segment matches any one segment, e.g. a:*:c:*
+// @Tags cache
+// @Produce json
+// @Param p query string true "Segment pattern"
+// @Success 200 {object} models.DeletePatternResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/hierarchy [delete]
+func (ch *CacheHandler) DeleteByHierarchy(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	pattern := c.Query("p")
+	deleted, err := ch.cacheService.DeleteByHierarchy(pattern)
+	if err != nil {
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Failed to delete by hierarchy",
+			Code:  "DELETE_HIERARCHY_FAILED",
+		})
+		return
+	}
+
+	if deleted > 0 {
+		ch.replicate(c, http.MethodDelete, "/hierarchy"+queryString(c), nil)
+	}
+	c.JSON(http.StatusOK, models.DeletePatternResponse{
+		Pattern: pattern,
+		Deleted: deleted,
+	})
+}
+
+// Rename handles POST requests to move a value to a new key
+// @Summary Rename key
+// @Description Atomically move an entry to a new key, keeping its value, TTL and access metadata
+// @Tags cache
+// @Accept json
+// @Produce json
+// @Param request body models.RenameRequest true "Rename request"
+// @Success 200 {object} models.RenameResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/cache/rename [post]
+func (ch *CacheHandler) Rename(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	var req models.RenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err := ch.cacheService.Rename(req.From, req.To, req.Overwrite)
+	switch {
+	case errors.Is(err, constants.ErrKeyNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Key not found",
+			Code:    "KEY_NOT_FOUND",
+			Message: "No entry exists for key " + req.From,
+		})
+		return
+	case errors.Is(err, constants.ErrKeyExists):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "Target key already exists",
+			Code:    "KEY_EXISTS",
+			Message: "Set overwrite to replace key " + req.To,
+		})
+		return
+	case err != nil:
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Failed to rename key",
+			Code:  "RENAME_FAILED",
+		})
+		return
+	}
+
+	ch.replicate(c, http.MethodPost, "/rename", req)
+	c.JSON(http.StatusOK, models.RenameResponse{
+		From:    req.From,
+		To:      req.To,
+		Renamed: true,
+	})
+}
+
+// Expire handles POST requests setting a new TTL on an existing key
+// @Summary Set key TTL
+// @Description Set an existing key to expire ttl seconds from now without rewriting its value
+// @Tags cache
+// @Accept json
+// @Produce json
+// @Param key path string false "Cache key; slashes and special characters must be percent-encoded"
+// @Param key query string false "Cache key, used when the path does not carry one"
+// @Param request body models.ExpireRequest true "New TTL in seconds"
+// @Success 200 {object} models.ExpireResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/cache/expire/{key} [post]
+// @Router /api/v1/cache/expire [post]
+func (ch *CacheHandler) Expire(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	key := keyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Key parameter is required",
+			Code:    "MISSING_KEY",
+			Message: "Please provide a valid key parameter",
+		})
+		return
+	}
+
+	var req models.ExpireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+	if *req.TTL <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid TTL",
+			Code:    "INVALID_TTL",
+			Message: "ttl must be a positive number of seconds; use /persist to remove the expiration",
+		})
+		return
+	}
+
+	// Over the maximum TTL the key gets the maximum instead, or the request fails in reject mode
+	ttl, err := ch.cacheService.CapTTL(time.Duration(*req.TTL) * time.Second)
+	if err != nil {
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Invalid TTL",
+			Code:  "INVALID_TTL",
+		})
+		return
+	}
+
+	if !ch.cacheService.Expire(key, ttl) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Key not found",
+			Code:    "KEY_NOT_FOUND",
+			Message: "No entry exists for key " + key,
+		})
+		return
+	}
+
+	ch.replicate(c, http.MethodPost, "/expire/"+url.PathEscape(key), req)
+	c.JSON(http.StatusOK, models.ExpireResponse{Key: key, TTL: int64(ttl / time.Second)})
+}
+
+// Persist handles POST requests removing the expiration of an existing key
+// @Summary Persist key
+// @Description Remove an existing key's expiration so it stays until evicted or deleted
+// @Tags cache
+// @Produce json
+// @Param key path string false "Cache key; slashes and special characters must be percent-encoded"
+// @Param key query string false "Cache key, used when the path does not carry one"
+// @Success 200 {object} models.ExpireResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/cache/persist/{key} [post]
+// @Router /api/v1/cache/persist [post]
+func (ch *CacheHandler) Persist(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	key := keyParam(c)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Key parameter is required",
+			Code:    "MISSING_KEY",
+			Message: "Please provide a valid key parameter",
+		})
+		return
+	}
+
+	if !ch.cacheService.Persist(key) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "Key not found",
+			Code:    "KEY_NOT_FOUND",
+			Message: "No entry exists for key " + key,
+		})
+		return
+	}
+
+	ch.replicate(c, http.MethodPost, "/persist/"+url.PathEscape(key), nil)
+	c.JSON(http.StatusOK, models.ExpireResponse{Key: key, TTL: -1})
+}
+
+// Clear handles DELETE requests to clear entire cache
+// @Summary Clear entire cache
+// @Description Remove all key-value pairs from cache, or only those with (expiring) or without (persistent) a TTL
+// @Tags cache
+// @Produce json
+// @Param only query string false "Restrict to 'expiring' or 'persistent' keys"
+// @Success 200 {object} models.ClearResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/clear [delete]
+func (ch *CacheHandler) Clear(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	var itemsCleared int
+	switch only := c.Query("only"); only {
+	case "":
+		itemsCleared = ch.cacheService.Clear()
+	case "expiring":
+		itemsCleared = ch.cacheService.ClearWhere(func(entry *models.CacheEntry) bool {
+			return entry.Expiration != 0
+		})
+	case "persistent":
+		itemsCleared = ch.cacheService.ClearWhere(func(entry *models.CacheEntry) bool {
+			return entry.Expiration == 0
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid only parameter",
+			Code:    "INVALID_REQUEST",
+			Message: "only must be 'expiring' or 'persistent', got '" + only + "'",
+		})
+		return
+	}
+
+	response := models.ClearResponse{
+		ItemsCleared: itemsCleared,
+		Message:      "Cache cleared successfully",
+	}
+	ch.replicate(c, http.MethodDelete, "/clear"+queryString(c), nil)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetStats handles GET requests for cache statistics
+// @Summary Get cache statistics
+// @Description Retrieve current cache performance statistics; detailed=true adds a TTL histogram (scans all entries) and value size aggregates
+// @Tags cache
+// @Produce json
+// @Param detailed query bool false "Include the TTL distribution and value sizes"
+// @Success 200 {object} models.CacheStats
+// @Router /api/v1/cache/stats [get]
+func (ch *CacheHandler) GetStats(c *gin.Context) {
+	stats := ch.cacheService.GetStats()
+	if detailed, _ := strconv.ParseBool(c.Query("detailed")); detailed {
+		stats.TTLBuckets = ch.cacheService.TTLHistogram()
+		valueSizes := ch.cacheService.ValueSizes()
+		stats.ValueSizes = &valueSizes
+	}
+	c.JSON(http.StatusOK, stats)
 }
 
-func NewCacheHandler(cacheService *service.CacheService) *CacheHandler {
-	return &CacheHandler{cacheService: cacheService}
+// StreamStats pushes cache statistics over Server-Sent Events until the client disconnects
+// or the server shuts down
+// @Summary Stream cache statistics
+// @Description Emit a CacheStats JSON object as an SSE "stats" event every interval, and a final "close" event on shutdown
+// @Tags cache
+// @Produce text/event-stream
+// @Param interval query int false "Seconds between frames (defaults to the configured interval)"
+// @Success 200 {object} models.CacheStats
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/cache/stats/stream [get]
+func (ch *CacheHandler) StreamStats(c *gin.Context) {
+	closing, ok := ch.streams.begin()
+	if !ok {
+		rejectStream(c)
+		return
+	}
+	defer ch.streams.end()
+
+	interval := ch.options.StatsStreamInterval
+	if seconds, err := strconv.Atoi(c.Query("interval")); err == nil && seconds > 0 {
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	// The stream outlives the server's write timeout
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.SSEvent("stats", ch.cacheService.GetStats())
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+			c.SSEvent("stats", ch.cacheService.GetStats())
+			return true
+		case <-closing:
+			c.SSEvent(streamCloseEvent, gin.H{"reason": "server shutting down"})
+			return false
+		}
+	})
+}
+
+// rejectStream answers a stream opened while the server is shutting down
+func rejectStream(c *gin.Context) {
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Error:   "Service is draining",
+		Code:    "DRAINING",
+		Message: "the server is shutting down and accepts no new streams",
+	})
+}
+
+// Publish handles POST requests delivering a message to a channel's subscribers
+// @Summary Publish a message
+// @Description Deliver a message to every current subscriber of a channel; nothing is stored
+// @Tags cache
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel name"
+// @Param request body models.PublishRequest true "Message to publish"
+// @Success 200 {object} models.PublishResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/publish/{channel} [post]
+func (ch *CacheHandler) Publish(c *gin.Context) {
+	var req models.PublishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	channel := pathParam(c, "channel")
+	c.JSON(http.StatusOK, models.PublishResponse{
+		Channel:     channel,
+		Subscribers: ch.cacheService.Publish(channel, req.Message),
+	})
+}
+
+// Subscribe streams messages published to a channel over Server-Sent Events until the client disconnects
+// @Summary Subscribe to a channel
+// @Description Emit a "subscribed" event once registered, then a "message" event per published message
+// @Tags cache
+// @Produce text/event-stream
+// @Param channel path string true "Channel name"
+// @Router /api/v1/cache/subscribe/{channel} [get]
+func (ch *CacheHandler) Subscribe(c *gin.Context) {
+	closing, ok := ch.streams.begin()
+	if !ok {
+		rejectStream(c)
+		return
+	}
+	defer ch.streams.end()
+
+	channel := pathParam(c, "channel")
+	sub := ch.cacheService.Subscribe(channel)
+	defer ch.cacheService.Unsubscribe(sub)
+
+	// The stream outlives the server's write timeout
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	// Tell the client it is registered so it can start publishing
+	c.SSEvent("subscribed", gin.H{"channel": channel})
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case message, ok := <-sub.Messages:
+			if !ok {
+				return false
+			}
+			c.SSEvent("message", message)
+			return true
+		case <-closing:
+			c.SSEvent(streamCloseEvent, gin.H{"reason": "server shutting down"})
+			return false
+		}
+	})
 }
 
-func (ch *CacheHandler) Put(c *gin.Context) {
-	var req models.PutRequest
+// GetSize handles GET requests for the current item count
+// @Summary Get cache size
+// @Description Retrieve the current and maximum number of entries without computing full statistics
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.SizeResponse
+// @Router /api/v1/cache/size [get]
+func (ch *CacheHandler) GetSize(c *gin.Context) {
+	response := models.SizeResponse{
+		CurrentSize: ch.cacheService.Size(),
+		MaxSize:     ch.cacheService.GetConfiguration().MaxSize,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// BulkPut handles bulk PUT operations
+// @Summary Bulk store key-value pairs
+// @Description Store multiple key-value pairs in a single request
+// @Tags cache
+// @Accept json
+// @Produce json
+// @Param request body models.BulkPutRequest true "Bulk put request"
+// @Param async query bool false "Apply the batch in the background and return a job to poll"
+// @Success 200 {object} models.BulkPutResponse
+// @Success 202 {object} models.BulkJobResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Router /api/v1/cache/bulk/put [post]
+func (ch *CacheHandler) BulkPut(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	var req models.BulkPutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "No items provided",
+			Code:    "EMPTY_REQUEST",
+			Message: "At least one item must be provided",
+		})
+		return
+	}
+
+	// With async=true the batch is applied in the background and polled via /bulk/jobs/:id
+	if async, _ := strconv.ParseBool(c.Query("async")); async {
+		job, err := ch.startBulkJob(c, req)
+		if errors.Is(err, errTooManyBulkJobs) {
+			c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+				Error:   "Too many bulk jobs",
+				Code:    "TOO_MANY_JOBS",
+				Message: fmt.Sprintf("%d async bulk puts are already running; retry once some finish", bulkJobMaxJobs),
+			})
+			return
+		}
+		if err != nil {
+			logger.ErrorF("starting bulk job: %v", requestFields(c, constants.LoggerCategoryHTTP), err)
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Failed to start bulk job",
+				Code:    "JOB_FAILED",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	response := ch.cacheService.BulkPut(req.Items, req.TTL)
+	if response.Successful > 0 {
+		ch.replicate(c, http.MethodPost, "/bulk/put", req)
+	}
+	annotateItems(response.Results)
+	c.JSON(http.StatusOK, response)
+}
+
+// Warmup handles loading a warmup set at runtime
+// @Summary Warm up the cache
+// @Description Load a set of key-value pairs, in the same shape as a bulk put, optionally leaving keys that already hold a live value untouched
+// @Tags cache
+// @Accept json
+// @Produce json
+// @Param request body models.BulkPutRequest true "Items to load"
+// @Param skip_existing query bool false "Skip keys that already hold a live value"
+// @Success 200 {object} models.WarmupResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/warmup [post]
+func (ch *CacheHandler) Warmup(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	var req models.BulkPutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Invalid request body",
@@ -31,140 +979,193 @@ func (ch *CacheHandler) Put(c *gin.Context) {
 		return
 	}
 
-	var ttl *time.Duration
-	if req.TTL != nil && *req.TTL > 0 {
-		duration := time.Duration(*req.TTL) * time.Second
-		ttl = &duration
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "No items provided",
+			Code:    "EMPTY_REQUEST",
+			Message: "At least one item must be provided",
+		})
+		return
+	}
+
+	skipExisting, _ := strconv.ParseBool(c.Query("skip_existing"))
+	response := ch.cacheService.Warmup(req.Items, req.TTL, skipExisting)
+	if response.Loaded > 0 {
+		ch.replicate(c, http.MethodPost, "/warmup"+queryString(c), req)
 	}
+	c.JSON(http.StatusOK, response)
+}
 
-	if err := ch.cacheService.Put(req.Key, req.Value, ttl); err != nil {
+// BulkGet handles bulk GET operations
+// @Summary Bulk get values by keys
+// @Description Retrieve multiple values from cache by keys; missing keys get the optional default as their value
+// @Tags cache
+// @Accept json
+// @Produce json,application/msgpack
+// @Param request body models.BulkGetRequest true "Bulk get request"
+// @Success 200 {object} models.BulkGetResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/bulk/get [post]
+func (ch *CacheHandler) BulkGet(c *gin.Context) {
+	var req models.BulkGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:   "Failed to store key-value pair",
-			Code:    "PUT_FAILED",
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
 			Message: err.Error(),
 		})
 		return
 	}
 
-	response := gin.H{
-		"message": "Key-value pair stored successfully",
-		"key":     req.Key,
-		"ttl":     req.TTL,
+	if len(req.Keys) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "No keys provided",
+			Code:    "EMPTY_REQUEST",
+			Message: "At least one key must be provided",
+		})
+		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	response := ch.reads.BulkGet(req.Keys, req.Default)
+	for key, result := range response.Results {
+		result.Status = http.StatusOK
+		if !result.Found {
+			result.Status = http.StatusNotFound
+		}
+		response.Results[key] = result
+	}
+	if err := respond(c, http.StatusOK, response); err != nil {
+		// Name the first offending key so the caller knows which value is broken
+		for key, result := range response.Results {
+			if _, keyErr := json.Marshal(result.Value); keyErr != nil {
+				unserializable(c, key, keyErr)
+				return
+			}
+		}
+		unserializable(c, "", err)
+	}
 }
 
-// Get handles GET requests to retrieve values by key
-// @Summary Get value by key
-// @Description Retrieve a value from cache by key
+// BulkGetTTL handles bulk TTL lookups
+// @Summary Bulk get remaining TTLs
+// @Description Retrieve remaining TTLs in seconds for multiple keys (-1 no expiration, -2 missing) without affecting LRU order or hit counts
 // @Tags cache
+// @Accept json
 // @Produce json
-// @Param key path string true "Cache key"
-// @Success 200 {object} models.GetResponse
-// @Failure 404 {object} models.ErrorResponse
-// @Router /api/v1/cache/get/{key} [get]
-func (ch *CacheHandler) Get(c *gin.Context) {
-	key := c.Param("key")
-	if key == "" {
+// @Param request body models.BulkGetRequest true "Keys to look up"
+// @Success 200 {object} models.BulkTTLResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/bulk/ttl [post]
+func (ch *CacheHandler) BulkGetTTL(c *gin.Context) {
+	var req models.BulkGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:   "Key parameter is required",
-			Code:    "MISSING_KEY",
-			Message: "Please provide a valid key parameter",
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	entry, found := ch.cacheService.Get(key)
-	if !found {
-		c.JSON(http.StatusNotFound, models.GetResponse{
-			Key:   key,
-			Found: false,
+	if len(req.Keys) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "No keys provided",
+			Code:    "EMPTY_REQUEST",
+			Message: "At least one key must be provided",
 		})
 		return
 	}
 
-	response := entry.ToResponse()
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, models.BulkTTLResponse{TTLs: ch.cacheService.BulkGetTTL(req.Keys)})
 }
 
-// Delete handles DELETE requests to remove keys
-// @Summary Delete key from cache
-// @Description Remove a key-value pair from cache
+// BulkTouch handles requests extending the TTL of multiple keys at once
+// @Summary Bulk touch keys
+// @Description Set every live key to expire ttl seconds from now (the default TTL when omitted) and count it as an access
 // @Tags cache
+// @Accept json
 // @Produce json
-// @Param key path string true "Cache key"
-// @Success 200 {object} models.DeleteResponse
-// @Failure 404 {object} models.DeleteResponse
-// @Router /api/v1/cache/delete/{key} [delete]
-func (ch *CacheHandler) Delete(c *gin.Context) {
-	key := c.Param("key")
-	if key == "" {
+// @Param request body models.BulkTouchRequest true "Keys to touch and optional TTL"
+// @Success 200 {object} models.BulkTouchResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/bulk/touch [post]
+func (ch *CacheHandler) BulkTouch(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	var req models.BulkTouchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:   "Key parameter is required",
-			Code:    "MISSING_KEY",
-			Message: "Please provide a valid key parameter",
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
 		})
 		return
 	}
 
-	deleted, found := ch.cacheService.Delete(key)
-	
-	response := models.DeleteResponse{
-		Key:     key,
-		Deleted: deleted,
-		Found:   found,
+	if len(req.Keys) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "No keys provided",
+			Code:    "EMPTY_REQUEST",
+			Message: "At least one key must be provided",
+		})
+		return
 	}
 
-	if found {
-		c.JSON(http.StatusOK, response)
-	} else {
-		c.JSON(http.StatusNotFound, response)
+	var ttl *time.Duration
+	if req.TTL != nil {
+		if *req.TTL <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "Invalid TTL",
+				Code:    "INVALID_TTL",
+				Message: "ttl must be a positive number of seconds",
+			})
+			return
+		}
+		duration, err := ch.cacheService.CapTTL(time.Duration(*req.TTL) * time.Second)
+		if err != nil {
+			respondServiceError(c, err, models.ErrorResponse{
+				Error: "Invalid TTL",
+				Code:  "INVALID_TTL",
+			})
+			return
+		}
+		ttl = &duration
 	}
-}
 
-// Clear handles DELETE requests to clear entire cache
-// @Summary Clear entire cache
-// @Description Remove all key-value pairs from cache
-// @Tags cache
-// @Produce json
-// @Success 200 {object} models.ClearResponse
-// @Router /api/v1/cache/clear [delete]
-func (ch *CacheHandler) Clear(c *gin.Context) {
-	itemsCleared := ch.cacheService.Clear()
-	
-	response := models.ClearResponse{
-		ItemsCleared: itemsCleared,
-		Message:      "Cache cleared successfully",
+	response := models.BulkTouchResponse{Touched: ch.cacheService.BulkTouch(req.Keys, ttl)}
+	for _, touched := range response.Touched {
+		if touched {
+			response.Updated++
+		} else {
+			response.NotFound++
+		}
 	}
 
+	if response.Updated > 0 {
+		ch.replicate(c, http.MethodPost, "/bulk/touch", req)
+	}
 	c.JSON(http.StatusOK, response)
 }
 
-// GetStats handles GET requests for cache statistics
-// @Summary Get cache statistics
-// @Description Retrieve current cache performance statistics
-// @Tags cache
-// @Produce json
-// @Success 200 {object} models.CacheStats
-// @Router /api/v1/cache/stats [get]
-func (ch *CacheHandler) GetStats(c *gin.Context) {
-	stats := ch.cacheService.GetStats()
-	c.JSON(http.StatusOK, stats)
-}
-
-// BulkPut handles bulk PUT operations
-// @Summary Bulk store key-value pairs
-// @Description Store multiple key-value pairs in a single request
+// Import handles loading a snapshot of entries into the cache
+// @Summary Import entries
+// @Description Store a snapshot of entries, resolving keys that already hold a live value by strategy: overwrite (default), skip-existing, or keep-newer by created_at
 // @Tags cache
 // @Accept json
 // @Produce json
-// @Param request body models.BulkPutRequest true "Bulk put request"
-// @Success 200 {object} models.BulkPutResponse
+// @Param request body models.ImportRequest true "Entries to import"
+// @Param strategy query string false "overwrite, skip-existing or keep-newer"
+// @Success 200 {object} models.ImportResponse
 // @Failure 400 {object} models.ErrorResponse
-// @Router /api/v1/cache/bulk/put [post]
-func (ch *CacheHandler) BulkPut(c *gin.Context) {
-	var req models.BulkPutRequest
+// @Router /api/v1/cache/import [post]
+func (ch *CacheHandler) Import(c *gin.Context) {
+	if ch.rejectWrite(c) {
+		return
+	}
+
+	var req models.ImportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "Invalid request body",
@@ -174,30 +1175,41 @@ func (ch *CacheHandler) BulkPut(c *gin.Context) {
 		return
 	}
 
-	if len(req.Items) == 0 {
+	if len(req.Entries) == 0 {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:   "No items provided",
+			Error:   "No entries provided",
 			Code:    "EMPTY_REQUEST",
-			Message: "At least one item must be provided",
+			Message: "At least one entry must be provided",
 		})
 		return
 	}
 
-	response := ch.cacheService.BulkPut(req.Items)
+	response, err := ch.cacheService.Import(req.Entries, c.Query("strategy"))
+	if err != nil {
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Failed to import entries",
+			Code:  "IMPORT_FAILED",
+		})
+		return
+	}
+
+	if response.Imported > 0 {
+		ch.replicate(c, http.MethodPost, "/import"+queryString(c), req)
+	}
 	c.JSON(http.StatusOK, response)
 }
 
-// BulkGet handles bulk GET operations
-// @Summary Bulk get values by keys
-// @Description Retrieve multiple values from cache by keys
+// BulkExists handles bulk presence checks
+// @Summary Bulk check key existence
+// @Description Report which of multiple keys hold a live value without fetching values, affecting LRU order or counting hits
 // @Tags cache
 // @Accept json
 // @Produce json
-// @Param request body models.BulkGetRequest true "Bulk get request"
-// @Success 200 {object} models.BulkGetResponse
+// @Param request body models.BulkGetRequest true "Keys to check"
+// @Success 200 {object} models.BulkExistsResponse
 // @Failure 400 {object} models.ErrorResponse
-// @Router /api/v1/cache/bulk/get [post]
-func (ch *CacheHandler) BulkGet(c *gin.Context) {
+// @Router /api/v1/cache/bulk/exists [post]
+func (ch *CacheHandler) BulkExists(c *gin.Context) {
 	var req models.BulkGetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -217,7 +1229,15 @@ func (ch *CacheHandler) BulkGet(c *gin.Context) {
 		return
 	}
 
-	response := ch.cacheService.BulkGet(req.Keys)
+	response := models.BulkExistsResponse{Exists: ch.cacheService.BulkExists(req.Keys)}
+	for _, exists := range response.Exists {
+		if exists {
+			response.Found++
+		} else {
+			response.NotFound++
+		}
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -230,7 +1250,7 @@ func (ch *CacheHandler) BulkGet(c *gin.Context) {
 // @Router /api/v1/health [get]
 func (ch *CacheHandler) GetHealth(c *gin.Context) {
 	config := ch.cacheService.GetConfiguration()
-	
+
 	response := models.HealthResponse{
 		Status:    "healthy",
 		Timestamp: time.Now(),
@@ -241,6 +1261,59 @@ func (ch *CacheHandler) GetHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetDiagnostics handles requests running the internal consistency checks
+// @Summary Run diagnostics
+// @Description Verify the LRU list against the key map: sentinels, links, cycles, orphaned nodes
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.DiagnosticsResponse
+// @Failure 500 {object} models.DiagnosticsResponse
+// @Router /api/v1/cache/diagnostics [get]
+func (ch *CacheHandler) GetDiagnostics(c *gin.Context) {
+	response := models.DiagnosticsResponse{
+		Healthy: true,
+		Checks:  ch.cacheService.Verify(),
+	}
+	for _, check := range response.Checks {
+		if !check.Passed {
+			response.Healthy = false
+		}
+	}
+
+	status := http.StatusOK
+	if !response.Healthy {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, response)
+}
+
+// GetOpLog handles requests for the recent mutating operations
+// @Summary Get operation log
+// @Description Retrieve the most recent Put/Delete/Clear operations, newest first
+// @Tags cache
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/cache/oplog [get]
+func (ch *CacheHandler) GetOpLog(c *gin.Context) {
+	entries := ch.cacheService.OpLog()
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// Ping handles liveness probes from load balancers
+// @Summary Liveness probe
+// @Description Return pong without touching the cache
+// @Tags health
+// @Produce plain
+// @Success 200 {string} string "pong"
+// @Router /api/v1/cache/ping [get]
+func (ch *CacheHandler) Ping(c *gin.Context) {
+	c.String(http.StatusOK, "pong")
+}
+
 // GetKeys handles requests to list all keys (for debugging)
 // @Summary List all keys
 // @Description Get list of all keys in cache (for debugging purposes)
@@ -256,23 +1329,151 @@ func (ch *CacheHandler) GetKeys(c *gin.Context) {
 		limit = 100
 	}
 
-	allKeys := ch.cacheService.ListKeys()
-	
-	// Apply limit
-	if len(allKeys) > limit {
-		allKeys = allKeys[:limit]
-	}
+	keys := ch.cacheService.ListKeysLimit(limit)
+	totalKeys := ch.cacheService.Size()
 
 	response := gin.H{
-		"keys":       allKeys,
-		"count":      len(allKeys),
-		"limited":    len(ch.cacheService.ListKeys()) > limit,
-		"total_keys": len(ch.cacheService.ListKeys()),
+		"keys":       keys,
+		"count":      len(keys),
+		"limited":    totalKeys > limit,
+		"total_keys": totalKeys,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// Scan handles cursor-based key iteration
+// @Summary Scan keys with a cursor
+// @Description Return a page of keys and the cursor for the next page, starting from cursor 0 until it returns "0". Keys present for the whole scan are returned exactly once; keys added or removed meanwhile may be missed, and renamed keys seen twice.
+// @Tags cache
+// @Produce json
+// @Param cursor query string false "Cursor from the previous page" default(0)
+// @Param count query int false "Maximum keys per page (at most 1000)" default(10)
+// @Success 200 {object} models.ScanResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/scan [get]
+func (ch *CacheHandler) Scan(c *gin.Context) {
+	cursor, err := strconv.ParseUint(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid cursor",
+			Code:    "INVALID_CURSOR",
+			Message: "cursor must be a value returned by a previous scan",
+		})
+		return
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", "10"))
+	if err != nil || count <= 0 {
+		count = 10
+	}
+	if count > maxScanCount {
+		count = maxScanCount
+	}
+
+	keys, next := ch.cacheService.Scan(cursor, count)
+	c.JSON(http.StatusOK, models.ScanResponse{
+		Keys:   keys,
+		Count:  len(keys),
+		Cursor: strconv.FormatUint(next, 10),
+	})
+}
+
+// GetExpiring handles requests for the entries closest to expiring
+// @Summary List keys expiring soon
+// @Description Return up to n live entries with an expiration or idle limit, soonest to expire first, each with its remaining TTL. Scans every entry but does not affect LRU order or hit counts.
+// @Tags cache
+// @Produce json,application/msgpack
+// @Param n query int false "Maximum entries to return (at most 1000)" default(10)
+// @Success 200 {object} models.ExpiringResponse
+// @Router /api/v1/cache/expiring [get]
+func (ch *CacheHandler) GetExpiring(c *gin.Context) {
+	n, err := strconv.Atoi(c.DefaultQuery("n", "10"))
+	if err != nil || n <= 0 {
+		n = 10
+	}
+	if n > maxScanCount {
+		n = maxScanCount
+	}
+
+	entries := ch.cacheService.ExpiringSoon(n)
+	response := models.ExpiringResponse{Entries: entries, Count: len(entries)}
+	if err := respond(c, http.StatusOK, response); err != nil {
+		for _, entry := range entries {
+			if _, keyErr := json.Marshal(entry.Value); keyErr != nil {
+				unserializable(c, entry.Key, keyErr)
+				return
+			}
+		}
+		unserializable(c, "", err)
+	}
+}
+
+// GetSample handles requests for a random sample of entries
+// @Summary Sample entries
+// @Description Return up to n distinct live entries chosen uniformly at random, each with its remaining TTL, or every live entry when there are fewer. Scans every entry but does not affect LRU order or hit counts.
+// @Tags cache
+// @Produce json,application/msgpack
+// @Param n query int false "Maximum entries to return (at most 1000)" default(10)
+// @Success 200 {object} models.SampleResponse
+// @Router /api/v1/cache/sample [get]
+func (ch *CacheHandler) GetSample(c *gin.Context) {
+	n, err := strconv.Atoi(c.DefaultQuery("n", "10"))
+	if err != nil || n <= 0 {
+		n = 10
+	}
+	if n > maxScanCount {
+		n = maxScanCount
+	}
+
+	entries := ch.cacheService.Sample(n)
+	response := models.SampleResponse{Entries: entries, Count: len(entries)}
+	if err := respond(c, http.StatusOK, response); err != nil {
+		for _, entry := range entries {
+			if _, keyErr := json.Marshal(entry.Value); keyErr != nil {
+				unserializable(c, entry.Key, keyErr)
+				return
+			}
+		}
+		unserializable(c, "", err)
+	}
+}
+
+// GetNextEviction handles requests for the next eviction candidate
+// @Summary Get next eviction candidate
+// @Description Report the key the next capacity-triggered eviction would remove (the least recently used one under LRU) without removing it
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.NextEvictionResponse
+// @Failure 404 {object} models.NextEvictionResponse
+// @Router /api/v1/cache/next-eviction [get]
+func (ch *CacheHandler) GetNextEviction(c *gin.Context) {
+	key, found := ch.cacheService.NextEvictionCandidate()
+	if !found {
+		c.JSON(http.StatusNotFound, models.NextEvictionResponse{Found: false})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NextEvictionResponse{Key: key, Found: true})
+}
+
+// CountKeys handles requests counting keys with a given prefix
+// @Summary Count keys
+// @Description Count non-expired keys starting with a prefix without listing them; an empty prefix counts all
+// @Tags cache
+// @Produce json
+// @Param prefix query string false "Key prefix"
+// @Success 200 {object} models.KeyCountResponse
+// @Router /api/v1/cache/keys/count [get]
+func (ch *CacheHandler) CountKeys(c *gin.Context) {
+	prefix := c.Query("prefix")
+
+	c.JSON(http.StatusOK, models.KeyCountResponse{
+		Prefix: prefix,
+		Count:  ch.cacheService.CountKeys(prefix),
+	})
+}
+
 // GetConfiguration handles requests for cache configuration
 // @Summary Get cache configuration
 // @Description Retrieve current cache configuration settings
@@ -282,16 +1483,146 @@ func (ch *CacheHandler) GetKeys(c *gin.Context) {
 // @Router /api/v1/cache/config [get]
 func (ch *CacheHandler) GetConfiguration(c *gin.Context) {
 	config := ch.cacheService.GetConfiguration()
-	
+
 	// Convert to a more readable format
 	response := gin.H{
 		"max_size":         config.MaxSize,
 		"default_ttl":      config.DefaultTTL.String(),
+		"max_ttl":          config.MaxTTL.String(),
+		"max_ttl_mode":     config.MaxTTLMode,
 		"cleanup_interval": config.CleanupInterval.String(),
+		"cleanup_disabled": config.CleanupDisabled,
+		"eviction_policy":  config.EvictionPolicy,
 		"start_time":       config.StartTime,
 		"uptime":           time.Since(config.StartTime).String(),
+		"read_only":        config.ReadOnly,
+		"high_watermark":   config.HighWatermark,
+		"low_watermark":    config.LowWatermark,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// SetDefaultTTL handles changing the default TTL at runtime
+// @Summary Set default TTL
+// @Description Change the TTL applied to later puts that omit one; 0 makes them never expire. Existing keys keep their expiration. The change applies to this instance only and lasts until restart.
+// @Tags cache
+// @Accept json
+// @Produce json
+// @Param request body models.DefaultTTLRequest true "New default TTL in seconds"
+// @Success 200 {object} models.DefaultTTLResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /api/v1/cache/config/default-ttl [post]
+func (ch *CacheHandler) SetDefaultTTL(c *gin.Context) {
+	var req models.DefaultTTLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	ttl, err := ch.cacheService.SetDefaultTTL(time.Duration(*req.TTL) * time.Second)
+	if err != nil {
+		respondServiceError(c, err, models.ErrorResponse{
+			Error: "Failed to set default TTL",
+			Code:  "INVALID_TTL",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DefaultTTLResponse{DefaultTTL: int64(ttl / time.Second)})
+}
+
+// serviceErrors maps the service's sentinel errors to their HTTP status and error response
+var serviceErrors = []struct {
+	err    error
+	status int
+	title  string
+	code   string
+}{
+	{constants.ErrReadOnly, http.StatusMethodNotAllowed, "Cache is read-only", "READ_ONLY"},
+	{constants.ErrDraining, http.StatusServiceUnavailable, "Service is draining", "DRAINING"},
+	{constants.ErrCacheBusy, http.StatusServiceUnavailable, "Cache is busy", "CACHE_BUSY"},
+	{constants.ErrThrashing, http.StatusServiceUnavailable, "Cache is thrashing", "CACHE_THRASHING"},
+	{constants.ErrKeyEmpty, http.StatusBadRequest, "Key is required", "MISSING_KEY"},
+	{constants.ErrKeyNotFound, http.StatusNotFound, "Key not found", "KEY_NOT_FOUND"},
+	{constants.ErrKeyExists, http.StatusConflict, "Key already exists", "KEY_EXISTS"},
+	{constants.ErrConflictingExpiration, http.StatusBadRequest, "Conflicting expiration", "CONFLICTING_EXPIRATION"},
+	{constants.ErrPatternEmpty, http.StatusBadRequest, "Invalid pattern", "INVALID_PATTERN"},
+	{constants.ErrInvalidPattern, http.StatusBadRequest, "Invalid pattern", "INVALID_PATTERN"},
+	{constants.ErrInvalidStrategy, http.StatusBadRequest, "Invalid strategy", "INVALID_STRATEGY"},
+	{constants.ErrNegativeTTL, http.StatusBadRequest, "Invalid TTL", "INVALID_TTL"},
+	{constants.ErrTTLTooLong, http.StatusBadRequest, "TTL too long", "TTL_TOO_LONG"},
+	{constants.ErrUnserializable, http.StatusBadRequest, "Invalid value", "INVALID_VALUE"},
+}
+
+// respondServiceError responds with the status and code mapped to err via errors.Is,
+// or with 400 and the fallback's error and code when err has no mapping
+func respondServiceError(c *gin.Context, err error, fallback models.ErrorResponse) {
+	if status, title, code, ok := serviceErrorStatus(err); ok {
+		c.JSON(status, models.ErrorResponse{
+			Error:   title,
+			Code:    code,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	fallback.Message = err.Error()
+	c.JSON(http.StatusBadRequest, fallback)
+}
+
+// serviceErrorStatus looks up the status, title and code mapped to err via errors.Is
+func serviceErrorStatus(err error) (int, string, string, bool) {
+	for _, mapping := range serviceErrors {
+		if errors.Is(err, mapping.err) {
+			return mapping.status, mapping.title, mapping.code, true
+		}
+	}
+	return 0, "", "", false
+}
+
+// annotateItems sets each bulk put item's status and error code to what a single put of
+// the item would have answered
+func annotateItems(results []models.ItemResult) {
+	for i := range results {
+		item := &results[i]
+		switch {
+		case item.Err != nil:
+			item.Status, item.Code = http.StatusBadRequest, "PUT_FAILED"
+			if status, _, code, ok := serviceErrorStatus(item.Err); ok {
+				item.Status, item.Code = status, code
+			}
+		case item.Created:
+			item.Status = http.StatusCreated
+		default:
+			item.Status = http.StatusOK
+		}
+	}
+}
+
+// rejectWrite responds with 405 on a read-only replica and 503 while draining,
+// and reports whether the write was rejected
+func (ch *CacheHandler) rejectWrite(c *gin.Context) bool {
+	if ch.cacheService.IsReadOnly() {
+		c.JSON(http.StatusMethodNotAllowed, models.ErrorResponse{
+			Error:   "Cache is read-only",
+			Code:    "READ_ONLY",
+			Message: constants.ErrReadOnly.Error(),
+		})
+		return true
+	}
+	if !ch.cacheService.IsDraining() {
+		return false
+	}
+
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Error:   "Service is draining",
+		Code:    "DRAINING",
+		Message: constants.ErrDraining.Error(),
+	})
+	return true
+}
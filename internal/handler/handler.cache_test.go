@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestPutConflictingExpiration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cs := service.NewCacheService(10, 0)
+	defer cs.Close()
+	ch := NewCacheHandler(cs, HandlerOptions{})
+	r := gin.New()
+	r.PUT("/put", ch.Put)
+
+	for body, message := range map[string]string{
+		`{"key":"k","value":1,"ttl":60,"expire_at":"2099-01-01T00:00:00Z"}`:            "ttl and expire_at cannot both be set",
+		`{"key":"k","value":1,"preserve_ttl":true,"expire_at":"2099-01-01T00:00:00Z"}`: "preserve_ttl cannot be combined with expire_at",
+		`{"key":"k","value":1,"persistent":true,"ttl":60}`:                             "persistent cannot be combined with ttl or expire_at",
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/put", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var response models.ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusBadRequest || response.Code != "CONFLICTING_EXPIRATION" || !strings.Contains(response.Message, message) {
+			t.Errorf("%s: %d %+v, want 400 CONFLICTING_EXPIRATION mentioning %q", body, w.Code, response, message)
+		}
+	}
+	if _, found := cs.Get("k"); found {
+		t.Fatal("a put with conflicting expiration stored its key")
+	}
+}
+
+// checkWritesRejected fails the test unless put, delete and clear are refused with status
+// and code while a get of k, which must hold v, is still served
+func checkWritesRejected(t *testing.T, cs *service.CacheService, status int, code string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ch := NewCacheHandler(cs, HandlerOptions{})
+	r := gin.New()
+	r.PUT("/put", ch.Put)
+	r.GET("/get/:key", ch.Get)
+	r.DELETE("/delete/:key", ch.Delete)
+	r.DELETE("/clear", ch.Clear)
+
+	for _, write := range []struct{ method, path, body string }{
+		{http.MethodPut, "/put", `{"key":"k","value":"new"}`},
+		{http.MethodDelete, "/delete/k", ""},
+		{http.MethodDelete, "/clear", ""},
+	} {
+		req := httptest.NewRequest(write.method, write.path, strings.NewReader(write.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var response models.ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != status || response.Code != code {
+			t.Errorf("%s %s = %d %+v, want %d %s", write.method, write.path, w.Code, response, status, code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/get/k", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"v"`) {
+		t.Fatalf("GET /get/k = %d %s, want 200 with v", w.Code, w.Body)
+	}
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	// A replica's keys come from restoring a snapshot, which read-only mode allows
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	primary := service.NewCacheServiceWithOptions(service.CacheOptions{MaxSize: 10, DisableCleanup: true})
+	if _, err := primary.OpenWriteBehind(path, time.Hour, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	primary.Put("k", "v", nil)
+	primary.Close()
+
+	cs := service.NewCacheServiceWithOptions(service.CacheOptions{MaxSize: 10, DisableCleanup: true, ReadOnly: true})
+	defer cs.Close()
+	if n, err := cs.OpenWriteBehind(path, time.Hour, time.Hour); err != nil || n != 1 {
+		t.Fatalf("restored %d keys, %v; want 1", n, err)
+	}
+
+	checkWritesRejected(t, cs, http.StatusMethodNotAllowed, "READ_ONLY")
+	if _, err := cs.Put("k", "new", nil); !errors.Is(err, constants.ErrReadOnly) {
+		t.Fatalf("Put on a read-only cache = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestDrainRejectsWrites(t *testing.T) {
+	cs := service.NewCacheService(10, 0)
+	defer cs.Close()
+	cs.Put("k", "v", nil)
+	cs.Drain()
+
+	checkWritesRejected(t, cs, http.StatusServiceUnavailable, "DRAINING")
+	if _, err := cs.Put("k", "new", nil); !errors.Is(err, constants.ErrDraining) {
+		t.Fatalf("Put while draining = %v, want ErrDraining", err)
+	}
+	if entry, found := cs.Get("k"); !found || entry.GetValue() != "v" {
+		t.Fatalf("Get while draining = %v, %v; want v", entry, found)
+	}
+}
+
+func TestClearOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cs := service.NewCacheService(10, 0)
+	defer cs.Close()
+	ch := NewCacheHandler(cs, HandlerOptions{})
+	r := gin.New()
+	r.DELETE("/clear", ch.Clear)
+
+	ttl := time.Hour
+	for _, step := range []struct{ only, cleared, kept string }{
+		{"expiring", "ttl", "forever"},
+		{"persistent", "forever", "ttl"},
+	} {
+		cs.Put("ttl", 1, &ttl)
+		cs.Put("forever", 2, nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/clear?only="+step.only, nil))
+		var response models.ClearResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusOK || response.ItemsCleared != 1 {
+			t.Fatalf("only=%s returned %d %+v, want 200 clearing 1", step.only, w.Code, response)
+		}
+		if _, found := cs.Get(step.cleared); found {
+			t.Fatalf("only=%s kept %s", step.only, step.cleared)
+		}
+		if _, found := cs.Get(step.kept); !found {
+			t.Fatalf("only=%s cleared %s", step.only, step.kept)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/clear?only=other", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("only=other returned %d, want 400", w.Code)
+	}
+}
+
+func TestGetUnserializableValue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cs := service.NewCacheService(10, 0)
+	defer cs.Close()
+	ch := NewCacheHandler(cs, HandlerOptions{})
+	r := gin.New()
+	r.GET("/get/:key", ch.Get)
+
+	// Values arriving over HTTP are JSON already; only a direct service write stores one like this
+	if _, err := cs.Put("k", make(chan int), nil); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/get/k", nil))
+
+	var response models.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response %q is not a JSON error: %v", w.Body, err)
+	}
+	if w.Code != http.StatusInternalServerError || response.Code != "UNSERIALIZABLE_VALUE" || !strings.Contains(response.Message, "key 'k'") {
+		t.Fatalf("get of an unserializable value = %d %+v, want 500 UNSERIALIZABLE_VALUE naming k", w.Code, response)
+	}
+}
+
+func TestKeyedHandlersRequireKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cs := service.NewCacheService(10, 0)
+	defer cs.Close()
+	ch := NewCacheHandler(cs, HandlerOptions{})
+	r := gin.New()
+	r.GET("/get", ch.Get)
+	r.DELETE("/delete", ch.Delete)
+	r.POST("/getdefault", ch.GetDefault)
+	r.POST("/expire", ch.Expire)
+	r.POST("/persist", ch.Persist)
+
+	for _, request := range []struct{ method, path, body string }{
+		{http.MethodGet, "/get", ""},
+		{http.MethodDelete, "/delete", ""},
+		{http.MethodPost, "/getdefault", `{"default":1}`},
+		{http.MethodPost, "/expire", `{"ttl":60}`},
+		{http.MethodPost, "/persist", ""},
+	} {
+		req := httptest.NewRequest(request.method, request.path+"?key=", strings.NewReader(request.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var response models.ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusBadRequest || response.Code != "MISSING_KEY" {
+			t.Errorf("%s %s without a key = %d %+v, want 400 MISSING_KEY", request.method, request.path, w.Code, response)
+		}
+	}
+	if size := cs.Size(); size != 0 {
+		t.Fatalf("cache holds %d keys after requests without one", size)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCleanup handles requests for the background cleanup worker's status
+// @Summary Get cleanup status
+// @Description Report whether background cleanup is paused, when it last ran and how many expired entries it reaped
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.CleanupStatus
+// @Router /api/v1/cache/cleanup [get]
+func (ch *CacheHandler) GetCleanup(c *gin.Context) {
+	c.JSON(http.StatusOK, ch.cacheService.CleanupStatus())
+}
+
+// RunCleanup handles requests to sweep expired entries immediately
+// @Summary Run cleanup now
+// @Description Reap expired entries now, even while background cleanup is paused, and return the updated status
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.CleanupStatus
+// @Router /api/v1/cache/cleanup/run [post]
+func (ch *CacheHandler) RunCleanup(c *gin.Context) {
+	c.JSON(http.StatusOK, ch.cacheService.RunCleanup())
+}
+
+// PauseCleanup handles requests to stop background cleanup sweeps
+// @Summary Pause cleanup
+// @Description Stop the background worker from reaping expired entries until resumed; expired entries are still hidden from reads
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.CleanupStatus
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/cache/cleanup/pause [post]
+func (ch *CacheHandler) PauseCleanup(c *gin.Context) {
+	if ch.rejectCleanupDisabled(c) {
+		return
+	}
+	ch.cacheService.PauseCleanup()
+	c.JSON(http.StatusOK, ch.cacheService.CleanupStatus())
+}
+
+// ResumeCleanup handles requests to restart background cleanup sweeps
+// @Summary Resume cleanup
+// @Description Let the background worker reap expired entries again from its next tick
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.CleanupStatus
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/cache/cleanup/resume [post]
+func (ch *CacheHandler) ResumeCleanup(c *gin.Context) {
+	if ch.rejectCleanupDisabled(c) {
+		return
+	}
+	ch.cacheService.ResumeCleanup()
+	c.JSON(http.StatusOK, ch.cacheService.CleanupStatus())
+}
+
+// rejectCleanupDisabled answers 409 CLEANUP_DISABLED when no background worker runs,
+// since pausing or resuming it would have no effect; it reports whether it did
+func (ch *CacheHandler) rejectCleanupDisabled(c *gin.Context) bool {
+	if !ch.cacheService.CleanupStatus().Disabled {
+		return false
+	}
+	c.JSON(http.StatusConflict, models.ErrorResponse{
+		Error:   "Background cleanup disabled",
+		Code:    "CLEANUP_DISABLED",
+		Message: "The cleanup worker is disabled by CACHE_CLEANUP_DISABLED; use /cleanup/run to sweep",
+	})
+	return true
+}
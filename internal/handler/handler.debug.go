@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorkers handles requests for the health of the cache's background workers
+// @Summary Get background worker health
+// @Description Report the goroutine count and whether the cleanup worker is alive, when it last ticked and whether it has stalled (no tick for twice its interval)
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.WorkersResponse
+// @Router /api/v1/cache/debug/workers [get]
+func (ch *CacheHandler) GetWorkers(c *gin.Context) {
+	c.JSON(http.StatusOK, models.WorkersResponse{
+		Goroutines: runtime.NumGoroutine(),
+		Workers:    []models.WorkerHealth{ch.cacheService.CleanupHealth()},
+	})
+}
+
+// GetLRUOrder handles requests for the raw recency list, for debugging eviction
+// @Summary Get LRU order
+// @Description List keys from most to least recently used by walking the recency list. Only served when DEBUG is on.
+// @Tags cache
+// @Produce json
+// @Param limit query int false "Maximum keys to return (at most 1000)" default(100)
+// @Success 200 {object} models.LRUOrderResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /api/v1/cache/debug/lru-order [get]
+func (ch *CacheHandler) GetLRUOrder(c *gin.Context) {
+	if !ch.options.Debug {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "Debug endpoints are disabled",
+			Code:    "DEBUG_DISABLED",
+			Message: "set DEBUG=true to enable /debug/lru-order",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > maxScanCount {
+		limit = maxScanCount
+	}
+
+	keys, total := ch.cacheService.RecencyOrder(limit)
+	c.JSON(http.StatusOK, models.LRUOrderResponse{Keys: keys, Count: len(keys), Total: total})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// EnvelopeHeader opts a single request into the response envelope when it is not enabled server-wide
+const EnvelopeHeader = "X-Response-Envelope"
+
+// envelopeWriter buffers a JSON response so it can be wrapped in models.Envelope
+type envelopeWriter struct {
+	bufferedWriter
+}
+
+// finish writes the buffered response, wrapped when it is a JSON body. Other content
+// types (MessagePack, SSE streams that flushed) and empty bodies are written as is.
+func (w *envelopeWriter) finish() {
+	if w.passthrough {
+		return
+	}
+
+	header := w.ResponseWriter.Header()
+	body := w.buffer.Bytes()
+	if len(body) == 0 || !strings.HasPrefix(header.Get("Content-Type"), "application/json") {
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.Write(body)
+		return
+	}
+
+	envelope := models.Envelope{Success: w.status < 400}
+	if envelope.Success {
+		envelope.Data = body
+	} else {
+		envelope.Error = envelopeError(w.status, body)
+		if !bytes.Equal(envelope.Error, body) {
+			envelope.Data = body
+		}
+	}
+	wrapped, err := json.Marshal(envelope)
+	if err != nil {
+		wrapped = body
+	}
+
+	header.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(wrapped)
+}
+
+// envelopeError returns body when it is an ErrorResponse. A few endpoints report failure
+// in their regular body instead (get answers 404 with "found": false); for those an
+// ErrorResponse is derived from the status, and the caller keeps body as data.
+func envelopeError(status int, body []byte) json.RawMessage {
+	var errorResponse models.ErrorResponse
+	if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Error != "" {
+		return body
+	}
+
+	text := http.StatusText(status)
+	derived, _ := json.Marshal(models.ErrorResponse{
+		Error: text,
+		Code:  strings.ToUpper(strings.ReplaceAll(text, " ", "_")),
+	})
+	return derived
+}
+
+// EnvelopeResponses is a middleware wrapping JSON responses as {"success", "data", "error"},
+// keeping their status codes. It applies to every request when ResponseEnvelope is set,
+// and otherwise only to requests sending the X-Response-Envelope: true header.
+func (ch *CacheHandler) EnvelopeResponses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if wanted, _ := strconv.ParseBool(c.GetHeader(EnvelopeHeader)); !ch.options.ResponseEnvelope && !wanted {
+			c.Next()
+			return
+		}
+
+		writer := &envelopeWriter{newBufferedWriter(c.Writer)}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"compress/gzip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressedTypePrefixes are content types that are already compressed and gain nothing from gzip
+var compressedTypePrefixes = []string{
+	"image/", "video/", "audio/",
+	"application/gzip", "application/x-gzip", "application/zip", "application/zstd", "application/x-brotli",
+}
+
+// gzipWriter buffers the response so its size is known before choosing whether to compress.
+// A Flush (as done by SSE endpoints) switches it to uncompressed pass-through for the rest of the response.
+type gzipWriter struct {
+	bufferedWriter
+}
+
+// finish writes the buffered response, gzipped when it is large enough and worth compressing
+func (w *gzipWriter) finish(minSize int) {
+	if w.passthrough {
+		return
+	}
+
+	header := w.ResponseWriter.Header()
+	body := w.buffer.Bytes()
+	if len(body) < minSize || header.Get("Content-Encoding") != "" || isCompressedType(header.Get("Content-Type")) {
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.Write(body)
+		return
+	}
+
+	header.Set("Content-Encoding", "gzip")
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+
+	gz := gzip.NewWriter(w.ResponseWriter)
+	gz.Write(body)
+	gz.Close()
+}
+
+// isCompressedType reports whether a content type is already compressed
+func isCompressedType(contentType string) bool {
+	for _, prefix := range compressedTypePrefixes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompressResponses is a middleware that gzips responses of at least GzipMinSize bytes
+// for clients sending Accept-Encoding: gzip. It is a no-op when GzipMinSize is 0.
+func (ch *CacheHandler) CompressResponses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ch.options.GzipMinSize <= 0 || !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+
+		writer := &gzipWriter{newBufferedWriter(c.Writer)}
+		c.Writer = writer
+		defer func() {
+			writer.finish(ch.options.GzipMinSize)
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
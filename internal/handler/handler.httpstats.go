@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// routeCounters holds the lock-free counters for a single route
+type routeCounters struct {
+	count        int64
+	totalLatency int64    // nanoseconds
+	maxLatency   int64    // nanoseconds
+	statusCodes  sync.Map // status code (int) -> *int64
+}
+
+// httpStats records request counts and latencies keyed by "METHOD /route/:template"
+type httpStats struct {
+	routes sync.Map // route key -> *routeCounters
+}
+
+// record adds one finished request to the counters of its route
+func (hs *httpStats) record(route string, status int, latency time.Duration) {
+	value, _ := hs.routes.LoadOrStore(route, &routeCounters{})
+	counters := value.(*routeCounters)
+
+	atomic.AddInt64(&counters.count, 1)
+	atomic.AddInt64(&counters.totalLatency, int64(latency))
+	for {
+		current := atomic.LoadInt64(&counters.maxLatency)
+		if int64(latency) <= current || atomic.CompareAndSwapInt64(&counters.maxLatency, current, int64(latency)) {
+			break
+		}
+	}
+
+	code, _ := counters.statusCodes.LoadOrStore(status, new(int64))
+	atomic.AddInt64(code.(*int64), 1)
+}
+
+// snapshot returns the current counters sorted by route
+func (hs *httpStats) snapshot() []models.HTTPRouteStats {
+	stats := []models.HTTPRouteStats{}
+
+	hs.routes.Range(func(key, value interface{}) bool {
+		counters := value.(*routeCounters)
+		count := atomic.LoadInt64(&counters.count)
+
+		route := models.HTTPRouteStats{
+			Route:       key.(string),
+			Count:       count,
+			StatusCodes: make(map[string]int64),
+			MaxLatency:  time.Duration(atomic.LoadInt64(&counters.maxLatency)).String(),
+		}
+		if count > 0 {
+			route.AvgLatency = time.Duration(atomic.LoadInt64(&counters.totalLatency) / count).String()
+		}
+		counters.statusCodes.Range(func(code, n interface{}) bool {
+			route.StatusCodes[strconv.Itoa(code.(int))] = atomic.LoadInt64(n.(*int64))
+			return true
+		})
+
+		stats = append(stats, route)
+		return true
+	})
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Route < stats[j].Route })
+	return stats
+}
+
+// RecordHTTPStats is a middleware that counts requests, status codes and latency per route template
+func (ch *CacheHandler) RecordHTTPStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		ch.httpStats.record(c.Request.Method+" "+c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// GetHTTPStats handles requests for per-route HTTP metrics
+// @Summary Get HTTP statistics
+// @Description Retrieve request counts, status codes and latencies per route
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.HTTPStatsResponse
+// @Router /api/v1/cache/http-stats [get]
+func (ch *CacheHandler) GetHTTPStats(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HTTPStatsResponse{
+		Routes: ch.httpStats.snapshot(),
+	})
+}
+
+// GetLatency handles requests for cache operation latency percentiles
+// @Summary Get operation latency percentiles
+// @Description Retrieve p50/p95/p99 latencies of Get and Put, estimated from a bounded sample
+// @Tags cache
+// @Produce json
+// @Success 200 {object} models.LatencyResponse
+// @Router /api/v1/cache/latency [get]
+func (ch *CacheHandler) GetLatency(c *gin.Context) {
+	c.JSON(http.StatusOK, ch.cacheService.Latency())
+}
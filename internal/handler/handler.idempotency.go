@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyHeader carries a client-chosen key identifying a retryable write
+	IdempotencyHeader = "Idempotency-Key"
+
+	idempotencyTTL     = 10 * time.Minute
+	idempotencyMaxKeys = 10000
+)
+
+// idempotentResponse is the recorded outcome of a write, replayed for repeats
+type idempotentResponse struct {
+	Status int
+	Body   gin.H
+}
+
+// idempotencyRecord is a response recorded under an idempotency key, kept until expires
+type idempotencyRecord struct {
+	key      string
+	response idempotentResponse
+	expires  time.Time
+}
+
+// idempotencyStore remembers recent responses by idempotency key. Every record lives for
+// the same TTL, so recording order is expiry order: records are queued as they are made
+// and pruned from the front, expired ones and, past idempotencyMaxKeys, the oldest.
+type idempotencyStore struct {
+	mutex   sync.Mutex
+	records map[string]idempotencyRecord
+	order   []idempotencyRecord // oldest first; a key recorded again leaves a stale record behind
+}
+
+// newIdempotencyStore creates an empty store for recently seen idempotency keys
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{records: make(map[string]idempotencyRecord)}
+}
+
+// get returns the response recorded under key, unless it has expired
+func (s *idempotencyStore) get(key string) (idempotentResponse, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	record, found := s.records[key]
+	if !found || !time.Now().Before(record.expires) {
+		return idempotentResponse{}, false
+	}
+	return record.response, true
+}
+
+// record stores response under key for idempotencyTTL, pruning old records first
+func (s *idempotencyStore) record(key string, response idempotentResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for len(s.order) > 0 && (!now.Before(s.order[0].expires) || len(s.records) >= idempotencyMaxKeys) {
+		oldest := s.order[0]
+		s.order[0] = idempotencyRecord{}
+		s.order = s.order[1:]
+		// Only drop the key if it was not recorded again since
+		if current, found := s.records[oldest.key]; found && current.expires.Equal(oldest.expires) {
+			delete(s.records, oldest.key)
+		}
+	}
+
+	record := idempotencyRecord{key: key, response: response, expires: now.Add(idempotencyTTL)}
+	s.records[key] = record
+	s.order = append(s.order, record)
+}
+
+// replayIdempotent writes the recorded response when the request repeats a known
+// idempotency key and reports whether it did so
+func (ch *CacheHandler) replayIdempotent(c *gin.Context) bool {
+	key := c.GetHeader(IdempotencyHeader)
+	if key == "" {
+		return false
+	}
+
+	recorded, found := ch.idempotency.get(key)
+	if !found {
+		return false
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.JSON(recorded.Status, recorded.Body)
+	return true
+}
+
+// recordIdempotent remembers a successful response under the request's idempotency key
+func (ch *CacheHandler) recordIdempotent(c *gin.Context, status int, body gin.H) {
+	key := c.GetHeader(IdempotencyHeader)
+	if key == "" || status >= http.StatusMultipleChoices {
+		return
+	}
+
+	ch.idempotency.record(key, idempotentResponse{Status: status, Body: body})
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotentPutAppliedOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cs := service.NewCacheService(10, 0)
+	defer cs.Close()
+	ch := NewCacheHandler(cs, HandlerOptions{})
+	r := gin.New()
+	r.PUT("/put", ch.Put)
+
+	put := func(value int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/put", strings.NewReader(fmt.Sprintf(`{"key":"k","value":%d}`, value)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(IdempotencyHeader, "retry-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := put(1)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first put returned %d, want 201", first.Code)
+	}
+	retry := put(2)
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry returned %d, replayed %q; want the original 201 replayed", retry.Code, retry.Header().Get("Idempotent-Replayed"))
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Fatalf("retry body %s, want the original %s", retry.Body, first.Body)
+	}
+	if entry, found := cs.Get("k"); !found || entry.GetValue() != 1.0 {
+		t.Fatalf("k = %v, %v; want 1 from the first put only", entry, found)
+	}
+}
+
+func TestIdempotencyStorePrunes(t *testing.T) {
+	s := newIdempotencyStore()
+	for i := 0; i < idempotencyMaxKeys+10; i++ {
+		s.record(fmt.Sprintf("k%d", i), idempotentResponse{Status: http.StatusOK})
+	}
+	if len(s.records) != idempotencyMaxKeys {
+		t.Fatalf("store holds %d keys, want the cap %d", len(s.records), idempotencyMaxKeys)
+	}
+	if _, found := s.get("k0"); found {
+		t.Fatal("oldest key kept past the cap")
+	}
+	if _, found := s.get(fmt.Sprintf("k%d", idempotencyMaxKeys+9)); !found {
+		t.Fatal("newest key missing")
+	}
+
+	// Recording a key again keeps it past the stale queue record of its first recording
+	s = newIdempotencyStore()
+	s.record("a", idempotentResponse{Status: http.StatusOK})
+	s.record("a", idempotentResponse{Status: http.StatusCreated})
+	s.order[0].expires = time.Now().Add(-time.Second)
+	s.record("b", idempotentResponse{Status: http.StatusOK})
+	if response, found := s.get("a"); !found || response.Status != http.StatusCreated {
+		t.Fatalf("a = %+v, %v; want the second recording", response, found)
+	}
+
+	// Expired records are pruned on the next write even below the cap
+	s.order[0].expires = time.Now().Add(-time.Second)
+	s.records["a"] = s.order[0]
+	s.record("c", idempotentResponse{Status: http.StatusOK})
+	if _, exists := s.records["a"]; exists {
+		t.Fatal("expired record not pruned")
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keyParam returns the key a single-key route operates on. Keys are taken from the path
+// (/get/:key, percent-encoded where they contain slashes or other reserved characters)
+// or, when the path carries none, from the key query parameter (/get?key=foo/bar:baz).
+// A key in the path takes precedence over one in the query.
+func keyParam(c *gin.Context) string {
+	if key := pathParam(c, "key"); key != "" {
+		return key
+	}
+	return c.Query("key")
+}
+
+// pathParam returns a decoded path parameter. The router matches on the escaped path
+// (so %2F stays inside one segment) without unescaping values, because gin would decode
+// them as query strings and turn "+" into a space; they are path-unescaped here instead.
+// When the request path needed no escaping gin matched on the decoded path already.
+func pathParam(c *gin.Context, name string) string {
+	value := c.Param(name)
+	if c.Request.URL.RawPath == "" {
+		return value
+	}
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
+}
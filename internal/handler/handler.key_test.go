@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// newKeyRouter serves put and both forms of get on a router set up like the server's,
+// matching on the escaped path without unescaping values
+func newKeyRouter(cs *service.CacheService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ch := NewCacheHandler(cs, HandlerOptions{})
+	r := gin.New()
+	r.UseRawPath = true
+	r.UnescapePathValues = false
+	r.PUT("/put", ch.Put)
+	r.GET("/get/:key", ch.Get)
+	r.GET("/get", ch.Get)
+	return r
+}
+
+func FuzzPutGetKey(f *testing.F) {
+	for _, key := range []string{"plain", "a/b", "a b+c", "100%", "?x=1&y#z", "日本/語", "%2F", "..", "a//b"} {
+		f.Add(key)
+	}
+	cs := service.NewCacheService(1000, 0)
+	defer cs.Close()
+	r := newKeyRouter(cs)
+
+	f.Fuzz(func(t *testing.T, key string) {
+		// JSON encoding replaces invalid UTF-8 with U+FFFD, so such keys cannot round-trip
+		if key == "" || !utf8.ValidString(key) || strings.ContainsRune(key, utf8.RuneError) {
+			t.Skip()
+		}
+		body, _ := json.Marshal(models.PutRequest{Key: key, Value: key})
+		req := httptest.NewRequest(http.MethodPut, "/put", strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated && w.Code != http.StatusOK {
+			t.Fatalf("put %q returned %d: %s", key, w.Code, w.Body)
+		}
+
+		for _, target := range []string{"/get?key=" + url.QueryEscape(key), "/get/" + url.PathEscape(key)} {
+			// Dot segments are resolved by clients before a request is sent; only the query form carries them
+			if strings.HasPrefix(target, "/get/") && (key == "." || key == "..") {
+				continue
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+			var response models.GetResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if w.Code != http.StatusOK || response.Key != key || response.Value != key {
+				t.Fatalf("GET %s for %q returned %d %+v", target, key, w.Code, response)
+			}
+		}
+	})
+}
+
+func TestHashedLongKeyRoundTrip(t *testing.T) {
+	cs := service.NewCacheServiceWithOptions(service.CacheOptions{MaxSize: 10, KeyHashThreshold: 32})
+	defer cs.Close()
+	r := newKeyRouter(cs)
+	long := "https://example.com/" + strings.Repeat("segment/", 10)
+
+	put := func(value string) int {
+		body, _ := json.Marshal(models.PutRequest{Key: long, Value: value})
+		req := httptest.NewRequest(http.MethodPut, "/put", strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := put("first"); code != http.StatusCreated {
+		t.Fatalf("put of a long key returned %d, want 201", code)
+	}
+	// The same long key hashes to the same entry
+	if code := put("second"); code != http.StatusOK {
+		t.Fatalf("second put of the long key returned %d, want 200 for an update", code)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/get?key="+url.QueryEscape(long), nil))
+	var response models.GetResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusOK || response.Key != long || response.Value != "second" {
+		t.Fatalf("get of the long key = %d %+v, want the original key with value second", w.Code, response)
+	}
+
+	// Key listings report the digest the entry is stored under
+	keys := cs.ListKeys()
+	if len(keys) != 1 || !strings.HasPrefix(keys[0], "sha256:") || len(keys[0]) != len("sha256:")+64 {
+		t.Fatalf("ListKeys = %v, want the long key's SHA-256 digest", keys)
+	}
+}
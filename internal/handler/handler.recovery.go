@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/Vinodbagra/cache-thread/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// RecoverPanics is a middleware turning a panic in a cache handler into a 500 INTERNAL_ERROR.
+// The panic is logged with the request ID, the operation (method and route), the key when the
+// route has one, and up to PanicStackBytes of the stack (none when PanicStackBytes is 0).
+// http.ErrAbortHandler is re-raised so deliberately aborted responses stay aborted.
+func (ch *CacheHandler) RecoverPanics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			fields := requestFields(c, constants.LoggerCategoryHTTP)
+			fields[constants.LoggerOperation] = c.Request.Method + " " + c.FullPath()
+			if key := keyParam(c); key != "" {
+				fields[constants.LoggerKey] = key
+			}
+			if ch.options.PanicStackBytes > 0 {
+				stack := debug.Stack()
+				if len(stack) > ch.options.PanicStackBytes {
+					stack = stack[:ch.options.PanicStackBytes]
+				}
+				fields[constants.LoggerStack] = string(stack)
+			}
+			logger.Error(fmt.Sprintf("panic: %v", recovered), fields)
+
+			// A handler that already started its response cannot be given a new status
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "Internal server error",
+				Code:    "INTERNAL_ERROR",
+				Message: "the request could not be completed",
+			})
+		}()
+
+		c.Next()
+	}
+}
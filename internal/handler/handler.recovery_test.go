@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/Vinodbagra/cache-thread/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoverPanicsRespondsAndLogs(t *testing.T) {
+	var logged bytes.Buffer
+	logger.SetOutput(&logged)
+	defer logger.SetOutput(os.Stdout)
+
+	gin.SetMode(gin.TestMode)
+	cs := service.NewCacheService(10, 0)
+	defer cs.Close()
+	ch := NewCacheHandler(cs, HandlerOptions{PanicStackBytes: 4096})
+	r := gin.New()
+	r.Use(ch.RecoverPanics())
+	r.GET("/get/:key", func(c *gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/get/k1", nil))
+
+	var response models.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusInternalServerError || response.Code != "INTERNAL_ERROR" {
+		t.Fatalf("panicking handler returned %d %+v, want 500 INTERNAL_ERROR", w.Code, response)
+	}
+
+	line := logged.String()
+	for _, want := range []string{"panic: boom", "GET /get/:key", "k1", "goroutine ", "TestRecoverPanicsRespondsAndLogs"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log %q does not contain %q", line, want)
+		}
+	}
+}
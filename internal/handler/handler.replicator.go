@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ReplicatedHeader marks a write forwarded by a peer; it is applied but never forwarded again
+const ReplicatedHeader = "X-Replicated"
+
+// WaitReplicationHeader set to true on a write holds the response until a quorum of peers
+// has applied the write, or the replication wait timeout passes
+const WaitReplicationHeader = "X-Wait-Replication"
+
+// ReplicationAcksHeader answers a waited write with how many peers applied it, e.g. "2/3"
+const ReplicationAcksHeader = "X-Replication-Acks"
+
+// replicationQueueSize is how many writes may wait per peer before further ones are dropped
+const replicationQueueSize = 1024
+
+// defaultReplicationWaitTimeout bounds waited writes when no timeout is configured
+const defaultReplicationWaitTimeout = 2 * time.Second
+
+// errReplicationQueueFull is the outcome of a waited write dropped on a full peer queue
+var errReplicationQueueFull = errors.New("replication queue full")
+
+// replicatedWrite is a write to repeat against a peer's cache API
+type replicatedWrite struct {
+	method    string
+	path      string // relative to the peer's base URL, e.g. "/put"
+	body      []byte
+	requestID string // correlation ID of the request that made the write
+
+	// acks receives the peer's outcome, nil on success, when the writer waits for it
+	acks chan<- error
+}
+
+// peerQueue delivers writes to one peer in order, so a slow peer never holds back the others
+type peerQueue struct {
+	baseURL string
+	writes  chan replicatedWrite
+}
+
+// Replicator asynchronously forwards successful writes to peer nodes through their HTTP API.
+// Replication is best-effort: writes are queued without blocking the request, dropped when a
+// peer's queue is full, and not retried when a peer is unreachable. A writer may still wait
+// for a quorum of peers to acknowledge its write, so it can read it back from any of them.
+type Replicator struct {
+	client      *http.Client
+	peers       []*peerQueue
+	waitTimeout time.Duration
+
+	mutex  sync.RWMutex // guards closed against concurrent enqueues
+	closed bool
+	wg     sync.WaitGroup
+
+	sent    int64
+	failed  int64
+	dropped int64
+}
+
+// NewReplicator starts a Replicator for the given peer base URLs, each pointing at a
+// peer's cache routes (e.g. "http://10.0.0.2:8080/api/cache"). waitTimeout bounds how long
+// a waited write holds its response (default 2s). It returns nil when there are no peers;
+// a nil Replicator ignores writes.
+func NewReplicator(peers []string, waitTimeout time.Duration) *Replicator {
+	if len(peers) == 0 {
+		return nil
+	}
+	if waitTimeout <= 0 {
+		waitTimeout = defaultReplicationWaitTimeout
+	}
+
+	r := &Replicator{client: &http.Client{Timeout: 5 * time.Second}, waitTimeout: waitTimeout}
+	for _, peer := range peers {
+		queue := &peerQueue{
+			baseURL: strings.TrimRight(peer, "/"),
+			writes:  make(chan replicatedWrite, replicationQueueSize),
+		}
+		r.peers = append(r.peers, queue)
+
+		r.wg.Add(1)
+		go r.deliver(queue)
+	}
+
+	return r
+}
+
+// Close stops accepting writes and waits for the queued ones to be delivered
+func (r *Replicator) Close() {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	if !r.closed {
+		r.closed = true
+		for _, queue := range r.peers {
+			close(queue.writes)
+		}
+	}
+	r.mutex.Unlock()
+
+	r.wg.Wait()
+}
+
+// Stats reports how many peer requests succeeded, failed and were dropped on a full queue
+func (r *Replicator) Stats() (sent, failed, dropped int64) {
+	if r == nil {
+		return 0, 0, 0
+	}
+	return atomic.LoadInt64(&r.sent), atomic.LoadInt64(&r.failed), atomic.LoadInt64(&r.dropped)
+}
+
+// enqueue queues a write for every peer without blocking. With wait it returns a channel
+// receiving one outcome per peer, for awaitQuorum; otherwise, or when the write could not
+// be queued at all, it returns nil.
+func (r *Replicator) enqueue(requestID, method, path string, body interface{}, wait bool) <-chan error {
+	if r == nil {
+		return nil
+	}
+
+	write := replicatedWrite{method: method, path: path, requestID: requestID}
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil
+		}
+		write.body = encoded
+	}
+	var acks chan error
+	if wait {
+		// Buffered for every peer, so delivery never blocks on a writer that gave up
+		acks = make(chan error, len(r.peers))
+		write.acks = acks
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return nil
+	}
+	for _, queue := range r.peers {
+		select {
+		case queue.writes <- write:
+		default:
+			atomic.AddInt64(&r.dropped, 1)
+			if acks != nil {
+				acks <- errReplicationQueueFull
+			}
+		}
+	}
+	return acks
+}
+
+// awaitQuorum waits until a majority of peers acknowledged a write, it can no longer get
+// one, or the wait timeout passes, and returns how many peers had acknowledged it by then.
+// Peers apply writes in order, so an acknowledged write follows every earlier one.
+func (r *Replicator) awaitQuorum(acks <-chan error) int {
+	if acks == nil {
+		return 0
+	}
+
+	quorum := len(r.peers)/2 + 1
+	timeout := time.NewTimer(r.waitTimeout)
+	defer timeout.Stop()
+
+	acked, answered := 0, 0
+	for acked < quorum && acked+len(r.peers)-answered >= quorum {
+		select {
+		case err := <-acks:
+			answered++
+			if err == nil {
+				acked++
+			}
+		case <-timeout.C:
+			return acked
+		}
+	}
+	return acked
+}
+
+// deliver sends a peer's queued writes until its queue is closed
+func (r *Replicator) deliver(queue *peerQueue) {
+	defer r.wg.Done()
+
+	for write := range queue.writes {
+		err := r.send(queue.baseURL, write)
+		if write.acks != nil {
+			write.acks <- err
+		}
+		if err != nil {
+			atomic.AddInt64(&r.failed, 1)
+			fields := logrus.Fields{
+				constants.LoggerCategory:  constants.LoggerCategoryReplication,
+				constants.LoggerRequestID: write.requestID,
+			}
+			logger.ErrorF("replicating %s %s to %s: %v", fields, write.method, write.path, queue.baseURL, err)
+			continue
+		}
+		atomic.AddInt64(&r.sent, 1)
+	}
+}
+
+// send performs a single write against a peer
+func (r *Replicator) send(baseURL string, write replicatedWrite) error {
+	req, err := http.NewRequest(write.method, baseURL+write.path, bytes.NewReader(write.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(ReplicatedHeader, "true")
+	if write.requestID != "" {
+		req.Header.Set(RequestIDHeader, write.requestID)
+	}
+	if write.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	// 404 on a delete only means the peer never had the key
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("peer responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// replicate forwards a successful write to the peers, unless it was itself replicated from one.
+// With X-Wait-Replication it first waits for a quorum of peers to apply the write and reports
+// the acknowledgements in X-Replication-Acks, so it must be called before the response is written.
+func (ch *CacheHandler) replicate(c *gin.Context, method, path string, body interface{}) {
+	if c.GetHeader(ReplicatedHeader) == "true" {
+		return
+	}
+	replicator := ch.options.Replicator
+	wait, _ := strconv.ParseBool(c.GetHeader(WaitReplicationHeader))
+	wait = wait && replicator != nil
+
+	acks := replicator.enqueue(c.GetString(constants.LoggerRequestID), method, path, body, wait)
+	if wait {
+		acked := replicator.awaitQuorum(acks)
+		c.Header(ReplicationAcksHeader, fmt.Sprintf("%d/%d", acked, len(replicator.peers)))
+	}
+}
+
+// queryString returns the request's raw query with its leading "?", or "" when there is none
+func queryString(c *gin.Context) string {
+	if c.Request.URL.RawQuery == "" {
+		return ""
+	}
+	return "?" + c.Request.URL.RawQuery
+}
@@ -0,0 +1,165 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/handler"
+	"github.com/Vinodbagra/cache-thread/internal/routes"
+	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// node is an in-process cache server
+type node struct {
+	server  *httptest.Server
+	service *service.CacheService
+}
+
+// newNode serves the cache API on a test server, replicating to peers when given any
+func newNode(t *testing.T, peers ...string) *node {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	replicator := handler.NewReplicator(peers, time.Second)
+	r := gin.New()
+	cacheRoute := routes.NewCacheRoute(r.Group("api"), service.CacheOptions{MaxSize: 1000}, handler.HandlerOptions{Replicator: replicator})
+	cacheRoute.Routes()
+
+	n := &node{server: httptest.NewServer(r), service: cacheRoute.Service}
+	t.Cleanup(func() {
+		n.server.Close()
+		replicator.Close()
+		n.service.Close()
+	})
+	return n
+}
+
+// baseURL is the node's cache API base URL, as configured on its peers
+func (n *node) baseURL() string {
+	return n.server.URL + "/api/cache"
+}
+
+// do sends a write with X-Wait-Replication and returns the response status and acks
+func (n *node) do(t *testing.T, method, path string, body interface{}) (int, string) {
+	t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, n.baseURL()+path, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handler.WaitReplicationHeader, "true")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, resp.Header.Get(handler.ReplicationAcksHeader)
+}
+
+// value returns the value the node holds for key
+func (n *node) value(key string) (interface{}, bool) {
+	entry, found := n.service.Get(key)
+	if !found {
+		return nil, false
+	}
+	return entry.GetValue(), true
+}
+
+func TestReplicationForwardsEveryWrite(t *testing.T) {
+	peer := newNode(t)
+	primary := newNode(t, peer.baseURL())
+
+	writes := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"put", http.MethodPut, "/put", map[string]interface{}{"key": "a", "value": "one"}},
+		{"get default", http.MethodPost, "/getdefault/b", map[string]interface{}{"default": "two"}},
+		{"rename", http.MethodPost, "/rename", map[string]interface{}{"from": "a", "to": "c"}},
+		{"bulk put", http.MethodPost, "/bulk/put", map[string]interface{}{"items": []map[string]interface{}{
+			{"key": "tmp:1", "value": 1}, {"key": "tmp:2", "value": 2}, {"key": "d", "value": "four"},
+		}}},
+		{"pattern delete", http.MethodDelete, "/pattern?p=tmp:*", nil},
+		{"delete", http.MethodDelete, "/delete/b", nil},
+	}
+	for _, w := range writes {
+		status, acks := primary.do(t, w.method, w.path, w.body)
+		if status >= 400 {
+			t.Fatalf("%s: primary responded %d", w.name, status)
+		}
+		if acks != "1/1" {
+			t.Fatalf("%s: %s = %q, want 1/1", w.name, handler.ReplicationAcksHeader, acks)
+		}
+	}
+
+	want := map[string]interface{}{"c": "one", "d": "four"}
+	for key, value := range want {
+		if got, found := peer.value(key); !found || got != value {
+			t.Errorf("peer %s = %v, %v; want %v", key, got, found, value)
+		}
+	}
+	for _, key := range []string{"a", "b", "tmp:1", "tmp:2"} {
+		if _, found := peer.value(key); found {
+			t.Errorf("peer still holds %s", key)
+		}
+	}
+	if size := peer.service.Size(); size != len(want) {
+		t.Errorf("peer holds %d keys, want %d", size, len(want))
+	}
+}
+
+func TestReplicationForwardsAsyncBulkPut(t *testing.T) {
+	peer := newNode(t)
+	primary := newNode(t, peer.baseURL())
+
+	items := make([]map[string]interface{}, 250)
+	for i := range items {
+		items[i] = map[string]interface{}{"key": fmt.Sprintf("k%d", i), "value": i}
+	}
+	status, _ := primary.do(t, http.MethodPost, "/bulk/put?async=true", map[string]interface{}{"items": items})
+	if status != http.StatusAccepted {
+		t.Fatalf("async bulk put responded %d, want 202", status)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for peer.service.Size() < len(items) {
+		if time.Now().After(deadline) {
+			t.Fatalf("peer holds %d of %d keys replicated from an async bulk put", peer.service.Size(), len(items))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplicatedWritesAreNotForwardedAgain(t *testing.T) {
+	third := newNode(t)
+	peer := newNode(t, third.baseURL())
+	primary := newNode(t, peer.baseURL())
+
+	if status, acks := primary.do(t, http.MethodPut, "/put", map[string]interface{}{"key": "a", "value": 1}); status >= 400 || acks != "1/1" {
+		t.Fatalf("put responded %d with acks %q", status, acks)
+	}
+	if _, found := peer.value("a"); !found {
+		t.Fatal("put did not reach the peer")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, found := third.value("a"); found {
+		t.Fatal("peer forwarded a replicated write")
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader carries a request's correlation ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs so they cannot bloat logs
+const maxRequestIDLength = 128
+
+// RequestID is a middleware giving every request a correlation ID. A valid X-Request-ID from
+// the client is kept, otherwise one is generated. The ID is stored in the gin context under
+// constants.LoggerRequestID, echoed in the response header and forwarded with replicated writes.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(constants.LoggerRequestID, id)
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII up to maxRequestIDLength
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 16 random bytes, hex encoded
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// requestFields returns the log fields for a line logged while handling c
+func requestFields(c *gin.Context, category string) logrus.Fields {
+	return logrus.Fields{
+		constants.LoggerCategory:  category,
+		constants.LoggerRequestID: c.GetString(constants.LoggerRequestID),
+	}
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestIDInHeaderAndLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logged bytes.Buffer
+	r := gin.New()
+	r.Use(RequestID())
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: logger.HTTPLogger, Output: &logged}))
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, sent := range []string{"client-id-1", "", "bad id"} {
+		logged.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		if sent != "" {
+			req.Header.Set(RequestIDHeader, sent)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		id := w.Header().Get(RequestIDHeader)
+		switch {
+		case validRequestID(sent) && id != sent:
+			t.Fatalf("sent %q, response header %q; want it kept", sent, id)
+		case !validRequestID(sent) && (len(id) != 32 || id == sent):
+			t.Fatalf("sent %q, response header %q; want a generated ID", sent, id)
+		}
+		if want := constants.LoggerRequestID + "=" + id; !strings.Contains(logged.String(), want) {
+			t.Fatalf("log line %q does not contain %q", logged.String(), want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+	"github.com/Vinodbagra/cache-thread/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+)
+
+// respond writes obj as MessagePack when the client asks for it via the Accept header,
+// and as JSON otherwise. obj is encoded before anything is written, so a value that
+// cannot be serialized yields an error instead of a half-written response.
+func respond(c *gin.Context, status int, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	if wantsMsgPack(c) {
+		c.Render(status, render.MsgPack{Data: obj})
+	} else {
+		c.Data(status, "application/json; charset=utf-8", data)
+	}
+	return nil
+}
+
+// wantsMsgPack reports whether the client's Accept header prefers MessagePack to JSON
+func wantsMsgPack(c *gin.Context) bool {
+	switch c.NegotiateFormat(binding.MIMEJSON, binding.MIMEMSGPACK2, binding.MIMEMSGPACK) {
+	case binding.MIMEMSGPACK2, binding.MIMEMSGPACK:
+		return true
+	default:
+		return false
+	}
+}
+
+// respondValue is respond for responses carrying cached values; when a stored value
+// cannot be serialized it answers 500 UNSERIALIZABLE_VALUE naming the key
+func respondValue(c *gin.Context, status int, key string, obj interface{}) {
+	if err := respond(c, status, obj); err != nil {
+		unserializable(c, key, err)
+	}
+}
+
+// unserializable writes the structured error for a cached value that cannot be encoded
+func unserializable(c *gin.Context, key string, err error) {
+	logger.ErrorF("serializing value of key '%s': %v", requestFields(c, constants.LoggerCategoryHTTP), key, err)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Error:   "Value cannot be serialized",
+		Code:    "UNSERIALIZABLE_VALUE",
+		Message: fmt.Sprintf("key '%s': %v", key, err),
+	})
+}
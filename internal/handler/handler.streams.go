@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// streamCloseEvent is the final SSE event an open stream receives when the server shuts down
+const streamCloseEvent = "close"
+
+// streamTracker keeps count of open SSE streams so shutdown can end them cleanly instead of
+// letting the HTTP server cut them when its own timeout runs out
+type streamTracker struct {
+	mutex   sync.Mutex // guards closed against streams opening concurrently with closeAll
+	closed  bool
+	closing chan struct{} // closed once the streams must end
+	open    sync.WaitGroup
+	active  int64
+}
+
+func newStreamTracker() *streamTracker {
+	return &streamTracker{closing: make(chan struct{})}
+}
+
+// begin registers a stream and returns a channel closed when it must end, or false once
+// the server is shutting down. Every successful begin must be paired with end.
+func (t *streamTracker) begin() (<-chan struct{}, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.closed {
+		return nil, false
+	}
+	t.open.Add(1)
+	atomic.AddInt64(&t.active, 1)
+	return t.closing, true
+}
+
+// end unregisters a stream started with begin
+func (t *streamTracker) end() {
+	atomic.AddInt64(&t.active, -1)
+	t.open.Done()
+}
+
+// CloseStreams refuses new SSE streams, tells every open one to send a final "close" event
+// and end, and waits until they have or ctx is done. It returns how many were still open
+// then. Call it before shutting down the HTTP server, which would otherwise wait on them.
+func (ch *CacheHandler) CloseStreams(ctx context.Context) int {
+	t := ch.streams
+
+	t.mutex.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.closing)
+	}
+	t.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.open.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	return int(atomic.LoadInt64(&t.active))
+}
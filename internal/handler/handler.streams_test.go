@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// openStream starts an SSE request to url and returns its body once the first event arrived
+func openStream(t *testing.T, url string) *bufio.Reader {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s returned %d", url, resp.StatusCode)
+	}
+	body := bufio.NewReader(resp.Body)
+	if line, err := body.ReadString('\n'); err != nil || !strings.HasPrefix(line, "event:") {
+		t.Fatalf("first line of %s = %q, %v; want an event", url, line, err)
+	}
+	return body
+}
+
+func TestCloseStreamsEndsWithCloseEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cs := service.NewCacheService(10, 0)
+	defer cs.Close()
+	ch := NewCacheHandler(cs, HandlerOptions{StatsStreamInterval: time.Hour})
+	r := gin.New()
+	r.GET("/stats/stream", ch.StreamStats)
+	r.GET("/subscribe/:channel", ch.Subscribe)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	streams := []*bufio.Reader{openStream(t, server.URL+"/stats/stream"), openStream(t, server.URL+"/subscribe/news")}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if open := ch.CloseStreams(ctx); open != 0 {
+		t.Fatalf("%d streams still open after CloseStreams", open)
+	}
+
+	for i, body := range streams {
+		rest, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("stream %d: %v", i, err)
+		}
+		if !strings.Contains(string(rest), "event:"+streamCloseEvent+"\n") {
+			t.Fatalf("stream %d ended without a close event: %q", i, rest)
+		}
+	}
+
+	// Streams opened after shutdown began are refused
+	resp, err := http.Get(server.URL + "/stats/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("stream opened after CloseStreams returned %d, want 503", resp.StatusCode)
+	}
+}
@@ -1,16 +1,35 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // CacheEntry represents a single cache entry with value, expiration time, and LRU pointers
 type CacheEntry struct {
-	Key        string      `json:"key"`
-	Value      interface{} `json:"value"`
-	Expiration int64       `json:"expiration"` // Unix timestamp, 0 means no expiration
-	CreatedAt  time.Time   `json:"created_at"`
-	AccessedAt time.Time   `json:"accessed_at"`
-	Prev       *CacheEntry
-	Next       *CacheEntry
+	Key          string        `json:"key"`
+	Nonce        []byte        `json:"-"`          // AES-GCM nonce, set only when values are encrypted
+	Expiration   int64         `json:"expiration"` // Unix timestamp, 0 means no expiration
+	MaxIdle      time.Duration `json:"max_idle"`   // Expire once not read for this long, 0 means no idle limit
+	CreatedAt    time.Time     `json:"created_at"`
+	ModifiedAt   time.Time     `json:"modified_at"` // Last time the value was written
+	AccessedAt   time.Time     `json:"accessed_at"`
+	AccessCount  int64         `json:"access_count"` // Number of successful Gets, updated atomically
+	ValueSize    int64         `json:"-"`            // Serialized size of the value in bytes
+	Checksum     uint32        `json:"-"`            // CRC32 of the stored value, taken on write when checksums are on
+	Accesses     []int64       `json:"-"`            // Last K access times (Unix nanoseconds, oldest first) under LRU-K eviction
+	HeapIndex    int           `json:"-"`            // Position in the LRU-K eviction heap plus one, 0 when not in it
+	Removed      bool          `json:"-"`            // Tombstone set once the entry has left the cache
+	Revalidating bool          `json:"-"`            // A stale-while-revalidate refresh has been triggered
+	Referenced   bool          `json:"-"`            // Accessed since the clock last passed it, under clock eviction
+	kind         valueKind     // Which of str, num and boxed holds the value; packed beside the flags
+	Prev         *CacheEntry
+	Next         *CacheEntry
+
+	// The value, read and written through GetValue and SetValue
+	str   string
+	num   int64
+	boxed interface{}
 }
 
 // CacheStats holds statistics about cache performance
@@ -22,25 +41,112 @@ type CacheStats struct {
 	CurrentSize     int     `json:"current_size"`
 	MaxSize         int     `json:"max_size"`
 	Evictions       int64   `json:"evictions"`
+	EvictionRate    float64 `json:"eviction_rate"` // Evictions per second over the last 10 seconds
+	Thrashing       bool    `json:"thrashing"`     // EvictionRate is above CACHE_THRASH_THRESHOLD
 	ExpiredRemovals int64   `json:"expired_removals"`
 	Uptime          string  `json:"uptime"`
+
+	// Values that no longer matched their checksum when read (CACHE_CHECKSUM_VALUES only)
+	CorruptionsDetected int64 `json:"corruptions_detected"`
+
+	// Detailed stats only (?detailed=true): live entries per remaining-TTL bucket
+	TTLBuckets map[string]int `json:"ttl_buckets,omitempty"`
+	// Detailed stats only: serialized value sizes
+	ValueSizes *ValueSizeStats `json:"value_sizes,omitempty"`
+}
+
+// ValueSizeStats holds serialized value sizes: min, max and average over every write
+// since startup, and the bytes held by the entries currently in the cache
+type ValueSizeStats struct {
+	Writes       int64   `json:"writes"`
+	MinBytes     int64   `json:"min_bytes"`
+	MaxBytes     int64   `json:"max_bytes"`
+	AvgBytes     float64 `json:"avg_bytes"`
+	CurrentBytes int64   `json:"current_bytes"`
+}
+
+// SizeResponse represents the lightweight size probe response
+type SizeResponse struct {
+	CurrentSize int `json:"current_size"`
+	MaxSize     int `json:"max_size"`
 }
 
 // PutRequest represents the request body for PUT operations
 type PutRequest struct {
-	Key   string      `json:"key" binding:"required"`
-	Value interface{} `json:"value" binding:"required"`
-	TTL   *int        `json:"ttl,omitempty"` // TTL in seconds, optional
+	Key         string      `json:"key" binding:"required"`
+	Value       interface{} `json:"value" binding:"required"`
+	TTL         *int        `json:"ttl,omitempty"`          // TTL in seconds, optional
+	ExpireAt    *Timestamp  `json:"expire_at,omitempty"`    // RFC3339 or Unix seconds, mutually exclusive with TTL
+	PreserveTTL bool        `json:"preserve_ttl,omitempty"` // Keep a live key's expiration; TTL then only applies to new keys
+	Persistent  bool        `json:"persistent,omitempty"`   // Never expire, ignoring the default TTL; excludes TTL and ExpireAt
+	MaxIdle     *int        `json:"max_idle,omitempty"`     // Expire once not read for this many seconds, alongside any TTL
 }
 
 // GetResponse represents the response for GET operations
 type GetResponse struct {
 	Key        string      `json:"key"`
+	Path       string      `json:"path,omitempty"` // JSONPath the value was extracted with, if any
 	Value      interface{} `json:"value"`
 	Found      bool        `json:"found"`
 	Expired    bool        `json:"expired,omitempty"`
+	Stale      bool        `json:"stale,omitempty"` // Expired but served within the stale-while-revalidate window
+	TTL        *int64      `json:"ttl,omitempty"`   // Remaining seconds, reported only by the expiring-soon listing
 	CreatedAt  time.Time   `json:"created_at,omitempty"`
+	ModifiedAt time.Time   `json:"modified_at,omitempty"`
 	AccessedAt time.Time   `json:"accessed_at,omitempty"`
+	Status     int         `json:"status,omitempty"` // Per-key status in bulk get results: 200 found, 404 not
+}
+
+// GetDefaultRequest represents the request body for get-with-default operations
+type GetDefaultRequest struct {
+	Default interface{} `json:"default" binding:"required"`
+	TTL     *int        `json:"ttl,omitempty"` // TTL in seconds for the stored default, optional
+}
+
+// GetDefaultResponse represents the response for get-with-default operations
+type GetDefaultResponse struct {
+	GetResponse
+	Created bool `json:"created"` // The key was missing and the default was stored
+}
+
+// RenameRequest represents the request body for rename operations
+type RenameRequest struct {
+	From      string `json:"from" binding:"required"`
+	To        string `json:"to" binding:"required"`
+	Overwrite bool   `json:"overwrite,omitempty"` // Replace an existing target key
+}
+
+// RenameResponse represents the response for rename operations
+type RenameResponse struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Renamed bool   `json:"renamed"`
+}
+
+// ExpireRequest represents the request body for setting a key's TTL
+type ExpireRequest struct {
+	TTL *int `json:"ttl" binding:"required"` // New TTL in seconds, from now
+}
+
+// DefaultTTLRequest represents the request body for changing the default TTL
+type DefaultTTLRequest struct {
+	TTL *int `json:"ttl" binding:"required"` // Seconds; 0 makes puts without a TTL never expire
+}
+
+// DefaultTTLResponse reports the default TTL in effect
+type DefaultTTLResponse struct {
+	DefaultTTL int64 `json:"default_ttl"` // Seconds; 0 means puts without a TTL never expire
+}
+
+// ExpireResponse represents the response for expire and persist operations
+type ExpireResponse struct {
+	Key string `json:"key"`
+	TTL int64  `json:"ttl"` // Remaining TTL in seconds, -1 when the key never expires
+}
+
+// DeleteIfRequest represents the optional body of a conditional delete
+type DeleteIfRequest struct {
+	Expected interface{} `json:"expected"` // Delete only if the stored value equals this
 }
 
 // DeleteResponse represents the response for DELETE operations
@@ -50,12 +156,134 @@ type DeleteResponse struct {
 	Found   bool   `json:"found"`
 }
 
+// KeyCountResponse represents the response for key count operations
+type KeyCountResponse struct {
+	Prefix string `json:"prefix"`
+	Count  int    `json:"count"`
+}
+
+// NextEvictionResponse represents the key the next eviction would remove
+type NextEvictionResponse struct {
+	Key   string `json:"key,omitempty"`
+	Found bool   `json:"found"`
+}
+
+// ExpiringResponse represents the entries closest to expiring
+type ExpiringResponse struct {
+	Entries []GetResponse `json:"entries"` // Soonest to expire first, each with its remaining ttl
+	Count   int           `json:"count"`
+}
+
+// SampleResponse represents a random sample of live entries
+type SampleResponse struct {
+	Entries []GetResponse `json:"entries"` // In no particular order, each with its remaining ttl
+	Count   int           `json:"count"`
+}
+
+// ScanResponse represents one page of a cursor-based key scan
+type ScanResponse struct {
+	Keys   []string `json:"keys"`
+	Count  int      `json:"count"`
+	Cursor string   `json:"cursor"` // Pass back to get the next page; "0" when the scan is complete
+}
+
+// DeletePatternResponse represents the response for delete-by-pattern operations
+type DeletePatternResponse struct {
+	Pattern string `json:"pattern"`
+	Deleted int    `json:"deleted"`
+}
+
 // ClearResponse represents the response for CLEAR operations
 type ClearResponse struct {
 	ItemsCleared int    `json:"items_cleared"`
 	Message      string `json:"message"`
 }
 
+// KeyInfoResponse represents per-key access statistics
+type KeyInfoResponse struct {
+	Key         string    `json:"key"`
+	CreatedAt   time.Time `json:"created_at"`
+	AccessedAt  time.Time `json:"accessed_at"`
+	AccessCount int64     `json:"access_count"`
+	TTL         int64     `json:"ttl"`                // Remaining seconds, -1 means no expiration
+	MaxIdle     int64     `json:"max_idle,omitempty"` // Idle limit in seconds, if any
+}
+
+// CompareResponse represents the comparison of two keys' values
+type CompareResponse struct {
+	Equal bool        `json:"equal"`
+	A     GetResponse `json:"a"`
+	B     GetResponse `json:"b"`
+}
+
+// OpLogEntry records a single mutating operation (values are omitted to bound memory)
+type OpLogEntry struct {
+	Op        string    `json:"op"`
+	Key       string    `json:"key,omitempty"`
+	From      string    `json:"from,omitempty"` // Previous key of a rename
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// HTTPRouteStats holds request metrics for a single route template
+type HTTPRouteStats struct {
+	Route       string           `json:"route"` // e.g. "GET /api/cache/get/:key"
+	Count       int64            `json:"count"`
+	StatusCodes map[string]int64 `json:"status_codes"`
+	AvgLatency  string           `json:"avg_latency"`
+	MaxLatency  string           `json:"max_latency"`
+}
+
+// HTTPStatsResponse represents the per-route HTTP metrics response
+type HTTPStatsResponse struct {
+	Routes []HTTPRouteStats `json:"routes"`
+}
+
+// LatencyPercentiles summarizes sampled latencies for one operation
+type LatencyPercentiles struct {
+	Count   int64  `json:"count"`   // operations recorded
+	Samples int    `json:"samples"` // operations kept in the bounded sample
+	P50     string `json:"p50,omitempty"`
+	P95     string `json:"p95,omitempty"`
+	P99     string `json:"p99,omitempty"`
+}
+
+// LatencyResponse represents the operation latency percentiles response
+type LatencyResponse struct {
+	Operations map[string]LatencyPercentiles `json:"operations"`
+}
+
+// PublishRequest represents the request body for publish operations
+type PublishRequest struct {
+	Message interface{} `json:"message" binding:"required"`
+}
+
+// PublishResponse represents the response for publish operations
+type PublishResponse struct {
+	Channel     string `json:"channel"`
+	Subscribers int    `json:"subscribers"` // Subscribers the message was delivered to
+}
+
+// CheckResult is the outcome of a single internal consistency check
+type CheckResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"` // Why the check failed
+}
+
+// DiagnosticsResponse represents the response for the diagnostics endpoint
+type DiagnosticsResponse struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []CheckResult `json:"checks"`
+}
+
+// Envelope wraps a response body when the response envelope is enabled: data carries
+// the usual body of a successful response and error the ErrorResponse of a failed one
+type Envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
 // ErrorResponse represents error responses
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -74,18 +302,55 @@ type HealthResponse struct {
 // BulkPutRequest represents bulk put operations
 type BulkPutRequest struct {
 	Items []PutRequest `json:"items" binding:"required"`
+	TTL   *int         `json:"ttl,omitempty"` // Batch default TTL in seconds for items without their own
 }
 
 // BulkPutResponse represents bulk put response
 type BulkPutResponse struct {
-	Successful int      `json:"successful"`
-	Failed     int      `json:"failed"`
-	Errors     []string `json:"errors,omitempty"`
+	Successful int          `json:"successful"`
+	Failed     int          `json:"failed"`
+	Errors     []string     `json:"errors,omitempty"`
+	Results    []ItemResult `json:"results"` // One per item, in request order
+}
+
+// ItemResult reports the outcome of one item of a bulk put, so a client can retry just the
+// failures. Status is what the item would have got as a single put: 201 for a new key, 200
+// for an overwrite, or the error status with Code and Error set.
+type ItemResult struct {
+	Key     string `json:"key"`
+	Status  int    `json:"status"`
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Created bool   `json:"-"` // Set by the service; the handler derives Status and Code
+	Err     error  `json:"-"`
+}
+
+// WarmupResponse represents warmup results; Skipped counts keys left alone because
+// they already held a live value
+type WarmupResponse struct {
+	Loaded  int      `json:"loaded"`
+	Skipped int      `json:"skipped"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// BulkJobResponse reports the progress of an async bulk put
+type BulkJobResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"` // "running" or "completed"
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Successful  int        `json:"successful"`
+	Failed      int        `json:"failed"`
+	Errors      []string   `json:"errors,omitempty"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
 }
 
 // BulkGetRequest represents bulk get operations
 type BulkGetRequest struct {
-	Keys []string `json:"keys" binding:"required"`
+	Keys    []string    `json:"keys" binding:"required"`
+	Default interface{} `json:"default,omitempty"` // Value reported for missing keys, optional
 }
 
 // BulkGetResponse represents bulk get response
@@ -95,22 +360,123 @@ type BulkGetResponse struct {
 	NotFound int                   `json:"not_found"`
 }
 
+// BulkTTLResponse represents bulk TTL lookups: remaining seconds per key,
+// -1 for keys without expiration and -2 for missing keys
+type BulkTTLResponse struct {
+	TTLs map[string]int64 `json:"ttls"`
+}
+
+// BulkExistsResponse represents bulk presence checks
+type BulkExistsResponse struct {
+	Exists   map[string]bool `json:"exists"`
+	Found    int             `json:"found"`
+	NotFound int             `json:"not_found"`
+}
+
+// BulkTouchRequest represents a request extending the TTL of several keys
+type BulkTouchRequest struct {
+	Keys []string `json:"keys" binding:"required"`
+	TTL  *int     `json:"ttl,omitempty"` // TTL in seconds from now, defaults to the cache default TTL
+}
+
+// BulkTouchResponse represents bulk touch results: whether each key was updated
+type BulkTouchResponse struct {
+	Touched  map[string]bool `json:"touched"`
+	Updated  int             `json:"updated"`
+	NotFound int             `json:"not_found"`
+}
+
+// ImportEntry is one entry of a snapshot to import
+type ImportEntry struct {
+	Key       string      `json:"key" binding:"required"`
+	Value     interface{} `json:"value"`
+	ExpireAt  *Timestamp  `json:"expire_at,omitempty"`  // No expiration when omitted
+	CreatedAt *Timestamp  `json:"created_at,omitempty"` // Defaults to the time of the import
+}
+
+// ImportRequest represents the request body for imports
+type ImportRequest struct {
+	Entries []ImportEntry `json:"entries" binding:"required"`
+}
+
+// ImportResponse represents import results. Imported counts every entry stored,
+// including the Overwritten ones that replaced a live value.
+type ImportResponse struct {
+	Strategy    string   `json:"strategy"`
+	Imported    int      `json:"imported"`
+	Skipped     int      `json:"skipped"`
+	Overwritten int      `json:"overwritten"`
+	Failed      int      `json:"failed"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// CleanupStatus represents the state of the background cleanup worker
+type CleanupStatus struct {
+	Disabled    bool       `json:"disabled"` // no background worker runs; only forced sweeps reap
+	Paused      bool       `json:"paused"`
+	Interval    string     `json:"interval"`
+	Runs        int64      `json:"runs"` // sweeps completed, forced ones included
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	LastReaped  int        `json:"last_reaped"` // expired entries removed by the last sweep
+	TotalReaped int64      `json:"total_reaped"`
+}
+
+// WorkerHealth represents the liveness of a background worker
+type WorkerHealth struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Alive      bool       `json:"alive"`    // the goroutine is running
+	Sweeping   bool       `json:"sweeping"` // a sweep is in progress right now
+	Interval   string     `json:"interval"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	LastTickAt *time.Time `json:"last_tick_at,omitempty"`
+	Ticks      int64      `json:"ticks"`
+}
+
+// LRUOrderResponse represents the recency list, most recently used key first
+type LRUOrderResponse struct {
+	Keys  []string `json:"keys"`
+	Count int      `json:"count"` // len(keys), at most the requested limit
+	Total int      `json:"total"` // entries in the cache
+}
+
+// WorkersResponse represents the background worker health response
+type WorkersResponse struct {
+	Goroutines int            `json:"goroutines"`
+	Workers    []WorkerHealth `json:"workers"`
+}
+
 // CacheConfiguration represents cache configuration
 type CacheConfiguration struct {
 	MaxSize         int           `json:"max_size"`
 	DefaultTTL      time.Duration `json:"default_ttl"`
+	MaxTTL          time.Duration `json:"max_ttl"` // 0 means writes may set any TTL
+	MaxTTLMode      string        `json:"max_ttl_mode,omitempty"`
 	CleanupInterval time.Duration `json:"cleanup_interval"`
+	CleanupDisabled bool          `json:"cleanup_disabled"`
+	EvictionPolicy  string        `json:"eviction_policy"`
 	StartTime       time.Time     `json:"start_time"`
+	ReadOnly        bool          `json:"read_only"`
+	HighWatermark   int           `json:"high_watermark"` // eviction starts at this many entries
+	LowWatermark    int           `json:"low_watermark"`  // and brings the cache down to this many
 }
 
-// IsExpired checks if the cache entry has expired
+// IsExpired checks if the cache entry has expired, by its TTL or by idling past MaxIdle
 func (ce *CacheEntry) IsExpired() bool {
+	if ce.IsIdleExpired() {
+		return true
+	}
 	if ce.Expiration == 0 {
 		return false // No expiration set
 	}
 	return time.Now().Unix() > ce.Expiration
 }
 
+// IsIdleExpired reports whether the entry has gone unread for longer than its MaxIdle
+func (ce *CacheEntry) IsIdleExpired() bool {
+	return ce.MaxIdle > 0 && time.Since(ce.AccessedAt) > ce.MaxIdle
+}
+
 // UpdateAccessTime updates the last accessed time
 func (ce *CacheEntry) UpdateAccessTime() {
 	ce.AccessedAt = time.Now()
@@ -125,14 +491,18 @@ func (ce *CacheEntry) SetExpiration(ttl time.Duration) {
 	}
 }
 
-// GetTTL returns the remaining TTL in seconds
+// GetTTL returns the remaining TTL in seconds, until the expiration or the idle limit,
+// whichever comes first
 func (ce *CacheEntry) GetTTL() int64 {
-	if ce.Expiration == 0 {
-		return -1 // No expiration
+	remaining := int64(-1) // No expiration
+	if ce.Expiration != 0 {
+		remaining = max(ce.Expiration-time.Now().Unix(), 0) // 0 once expired
 	}
-	remaining := ce.Expiration - time.Now().Unix()
-	if remaining < 0 {
-		return 0 // Expired
+	if ce.MaxIdle > 0 {
+		idle := max(int64((ce.MaxIdle-time.Since(ce.AccessedAt))/time.Second), 0)
+		if remaining < 0 || idle < remaining {
+			remaining = idle
+		}
 	}
 	return remaining
 }
@@ -141,10 +511,12 @@ func (ce *CacheEntry) GetTTL() int64 {
 func (ce *CacheEntry) ToResponse() GetResponse {
 	return GetResponse{
 		Key:        ce.Key,
-		Value:      ce.Value,
+		Value:      ce.GetValue(),
 		Found:      true,
 		Expired:    ce.IsExpired(),
+		Stale:      ce.IsExpired(), // Get only returns an expired entry while it may be served stale
 		CreatedAt:  ce.CreatedAt,
+		ModifiedAt: ce.ModifiedAt,
 		AccessedAt: ce.AccessedAt,
 	}
 }
\ No newline at end of file
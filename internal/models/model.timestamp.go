@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Timestamp is a point in time that unmarshals from either an RFC3339 string
+// or a number of Unix seconds
+type Timestamp struct {
+	time.Time
+}
+
+// UnmarshalJSON accepts "2025-01-02T15:04:05Z" as well as 1735830245
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var seconds int64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		t.Time = time.Unix(seconds, 0)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("timestamp must be an RFC3339 string or Unix seconds")
+	}
+
+	parsed, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return fmt.Errorf("timestamp must be an RFC3339 string or Unix seconds: %v", err)
+	}
+
+	t.Time = parsed
+	return nil
+}
@@ -0,0 +1,37 @@
+package models
+
+// valueKind tags which field of a CacheEntry holds its value
+type valueKind uint8
+
+const (
+	valueBoxed  valueKind = iota // any other value, held in boxed
+	valueString                  // held unboxed in str
+	valueInt64                   // held unboxed in num
+)
+
+// SetValue stores v in the entry. Strings and int64s, the most common small values, are
+// kept unboxed so each entry is a single allocation; anything else is kept as is.
+func (ce *CacheEntry) SetValue(v interface{}) {
+	ce.str, ce.num, ce.boxed = "", 0, nil
+
+	switch v := v.(type) {
+	case string:
+		ce.kind, ce.str = valueString, v
+	case int64:
+		ce.kind, ce.num = valueInt64, v
+	default:
+		ce.kind, ce.boxed = valueBoxed, v
+	}
+}
+
+// GetValue returns the entry's value with the dynamic type it was stored with
+func (ce *CacheEntry) GetValue() interface{} {
+	switch ce.kind {
+	case valueString:
+		return ce.str
+	case valueInt64:
+		return ce.num
+	default:
+		return ce.boxed
+	}
+}
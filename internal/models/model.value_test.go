@@ -0,0 +1,92 @@
+package models
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestSetValueRoundTrip(t *testing.T) {
+	m := map[string]interface{}{"a": 1.0}
+	for _, v := range []interface{}{"text", "", int64(-7), int64(0), 42, 3.5, true, nil, m} {
+		entry := &CacheEntry{}
+		entry.SetValue("previous")
+		entry.SetValue(v)
+		got := entry.GetValue()
+		if _, isMap := v.(map[string]interface{}); isMap {
+			if got.(map[string]interface{})["a"] != 1.0 {
+				t.Fatalf("map round-tripped as %v", got)
+			}
+			continue
+		}
+		if got != v {
+			t.Fatalf("SetValue(%#v) then GetValue() = %#v", v, got)
+		}
+	}
+
+	// An int is not an int64; it keeps its own type, boxed
+	entry := &CacheEntry{}
+	entry.SetValue(5)
+	if _, ok := entry.GetValue().(int); !ok {
+		t.Fatalf("int came back as %T", entry.GetValue())
+	}
+}
+
+// boxedEntry stands in for the entry layout before values were held unboxed
+type boxedEntry struct {
+	Key   string
+	Value interface{}
+}
+
+// benchmarkRetained stores 10000 values, made by value and boxed the way decoded JSON
+// arrives, through store and reports the heap objects each stored entry keeps alive
+func benchmarkRetained(b *testing.B, value func(i int) interface{}, store func(i int, v interface{}) interface{}) {
+	const n = 10000
+	var stats runtime.MemStats
+	var objects uint64
+	for i := 0; i < b.N; i++ {
+		entries := make([]interface{}, n)
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		before := stats.HeapObjects
+
+		for j := range entries {
+			entries[j] = store(j, value(i*n+j))
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+		objects += stats.HeapObjects - before
+		runtime.KeepAlive(entries)
+	}
+	b.ReportMetric(float64(objects)/float64(b.N*n), "objects/entry")
+}
+
+func stringValue(i int) interface{} { return "value:" + strconv.Itoa(i) }
+
+func int64Value(i int) interface{} { return int64(i) << 20 }
+
+func storeEntry(_ int, v interface{}) interface{} {
+	entry := &CacheEntry{}
+	entry.SetValue(v)
+	return entry
+}
+
+func storeBoxed(_ int, v interface{}) interface{} {
+	return &boxedEntry{Value: v}
+}
+
+func BenchmarkRetainedString(b *testing.B) {
+	benchmarkRetained(b, stringValue, storeEntry)
+}
+
+func BenchmarkRetainedBoxedString(b *testing.B) {
+	benchmarkRetained(b, stringValue, storeBoxed)
+}
+
+func BenchmarkRetainedInt64(b *testing.B) {
+	benchmarkRetained(b, int64Value, storeEntry)
+}
+
+func BenchmarkRetainedBoxedInt64(b *testing.B) {
+	benchmarkRetained(b, int64Value, storeBoxed)
+}
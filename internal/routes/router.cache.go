@@ -1,8 +1,6 @@
 package routes
 
 import (
-	"time"
-
 	"github.com/Vinodbagra/cache-thread/internal/handler"
 	"github.com/Vinodbagra/cache-thread/internal/service"
 	"github.com/gin-gonic/gin"
@@ -10,34 +8,86 @@ import (
 
 type cacheRoutes struct {
 	Handler *handler.CacheHandler
+	Service *service.CacheService
 	router  *gin.RouterGroup
 }
 
-func NewCacheRoute(router *gin.RouterGroup, cacheMaxSize int, cacheDefaultTTL time.Duration) *cacheRoutes {
-	cacheService := service.NewCacheService(cacheMaxSize, cacheDefaultTTL)
-	cacheHandler := handler.NewCacheHandler(cacheService)
+func NewCacheRoute(router *gin.RouterGroup, cacheOptions service.CacheOptions, handlerOptions handler.HandlerOptions) *cacheRoutes {
+	cacheService := service.NewCacheServiceWithOptions(cacheOptions)
+	cacheHandler := handler.NewCacheHandler(cacheService, handlerOptions)
 
-	return &cacheRoutes{Handler: cacheHandler, router: router}
+	return &cacheRoutes{Handler: cacheHandler, Service: cacheService, router: router}
 }
 
 func (r *cacheRoutes) Routes() {
 	// Cache API Routes
 	cacheRoute := r.router.Group("/cache")
+	// The envelope wraps everything below it, including 413s from the body limit and 500s from
+	// recovered panics, before compression
+	cacheRoute.Use(r.Handler.RecordHTTPStats(), r.Handler.CompressResponses(), r.Handler.EnvelopeResponses(), r.Handler.RecoverPanics(), r.Handler.LimitRequestBody())
 	{
 		// Basic CRUD operations
-		cacheRoute.PUT("/put", r.Handler.Put)               // Store key-value pair
-		cacheRoute.GET("/get/:key", r.Handler.Get)          // Get value by key
-		cacheRoute.DELETE("/delete/:key", r.Handler.Delete) // Delete key
-		cacheRoute.DELETE("/clear", r.Handler.Clear)        // Clear entire cache
+		cacheRoute.PUT("/put", r.Handler.Put)                        // Store key-value pair
+		cacheRoute.GET("/get/:key", r.Handler.Get)                   // Get value by key
+		cacheRoute.GET("/get", r.Handler.Get)                        // Get value by ?key= (for keys with slashes)
+		cacheRoute.POST("/getdefault/:key", r.Handler.GetDefault)    // Get value or store a default
+		cacheRoute.POST("/getdefault", r.Handler.GetDefault)         // Get value or store a default by ?key=
+		cacheRoute.GET("/info/:key", r.Handler.GetInfo)              // Get per-key access statistics
+		cacheRoute.GET("/info", r.Handler.GetInfo)                   // Get per-key access statistics by ?key=
+		cacheRoute.DELETE("/delete/:key", r.Handler.Delete)          // Delete key
+		cacheRoute.DELETE("/delete", r.Handler.Delete)               // Delete key by ?key=
+		cacheRoute.DELETE("/pattern", r.Handler.DeleteByPattern)     // Delete keys matching a glob pattern
+		cacheRoute.DELETE("/hierarchy", r.Handler.DeleteByHierarchy) // Delete keys matching a segment pattern
+		cacheRoute.DELETE("/clear", r.Handler.Clear)                 // Clear entire cache
+		cacheRoute.POST("/rename", r.Handler.Rename)                 // Move a value to a new key
+		cacheRoute.POST("/expire/:key", r.Handler.Expire)            // Set a new TTL on a key
+		cacheRoute.POST("/expire", r.Handler.Expire)                 // Set a new TTL on ?key=
+		cacheRoute.POST("/persist/:key", r.Handler.Persist)          // Remove a key's expiration
+		cacheRoute.POST("/persist", r.Handler.Persist)               // Remove the expiration of ?key=
 
 		// Bulk operations
-		cacheRoute.POST("/bulk/put", r.Handler.BulkPut) // Bulk store key-value pairs
-		cacheRoute.POST("/bulk/get", r.Handler.BulkGet) // Bulk get values
+		cacheRoute.POST("/bulk/put", r.Handler.BulkPut)        // Bulk store key-value pairs
+		cacheRoute.POST("/bulk/get", r.Handler.BulkGet)        // Bulk get values
+		cacheRoute.POST("/bulk/ttl", r.Handler.BulkGetTTL)     // Bulk get remaining TTLs
+		cacheRoute.POST("/bulk/exists", r.Handler.BulkExists)  // Bulk check which keys are present
+		cacheRoute.POST("/bulk/touch", r.Handler.BulkTouch)    // Bulk extend TTLs
+		cacheRoute.GET("/bulk/jobs/:id", r.Handler.GetBulkJob) // Progress of an async bulk put
+		cacheRoute.POST("/import", r.Handler.Import)           // Load a snapshot with a merge strategy
+		cacheRoute.POST("/warmup", r.Handler.Warmup)           // Load a warmup set, optionally skipping present keys
+
+		// Publish/subscribe (messages are delivered, never stored)
+		cacheRoute.POST("/publish/:channel", r.Handler.Publish)    // Publish a message to a channel
+		cacheRoute.GET("/subscribe/:channel", r.Handler.Subscribe) // Receive a channel's messages over SSE
 
 		// Information and monitoring
-		cacheRoute.GET("/stats", r.Handler.GetStats)          // Get cache statistics
-		cacheRoute.GET("/health", r.Handler.GetHealth)        // Health check
-		cacheRoute.GET("/keys", r.Handler.GetKeys)            // List all keys (for debugging)
-		cacheRoute.GET("/config", r.Handler.GetConfiguration) // Get cache configuration
+		cacheRoute.GET("/stats", r.Handler.GetStats)                    // Get cache statistics
+		cacheRoute.GET("/stats/stream", r.Handler.StreamStats)          // Stream cache statistics over SSE
+		cacheRoute.GET("/size", r.Handler.GetSize)                      // Get current item count
+		cacheRoute.GET("/health", r.Handler.GetHealth)                  // Health check
+		cacheRoute.GET("/ping", r.Handler.Ping)                         // Liveness probe for load balancers
+		cacheRoute.GET("/diagnostics", r.Handler.GetDiagnostics)        // Internal consistency checks
+		cacheRoute.GET("/next-eviction", r.Handler.GetNextEviction)     // Key the next eviction would remove
+		cacheRoute.GET("/expiring", r.Handler.GetExpiring)              // Entries closest to expiring
+		cacheRoute.GET("/sample", r.Handler.GetSample)                  // Random live entries for estimates
+		cacheRoute.GET("/export.csv", r.Handler.ExportCSV)              // Every live entry as CSV
+		cacheRoute.GET("/compare", r.Handler.Compare)                   // Diff the values of two keys
+		cacheRoute.GET("/oplog", r.Handler.GetOpLog)                    // Recent mutating operations
+		cacheRoute.GET("/http-stats", r.Handler.GetHTTPStats)           // Per-route request counts and latencies
+		cacheRoute.GET("/latency", r.Handler.GetLatency)                // Get/Put latency percentiles
+		cacheRoute.GET("/keys", r.Handler.GetKeys)                      // List all keys (for debugging)
+		cacheRoute.GET("/keys/count", r.Handler.CountKeys)              // Count keys with a prefix
+		cacheRoute.GET("/scan", r.Handler.Scan)                         // Iterate keys page by page with a cursor
+		cacheRoute.GET("/config", r.Handler.GetConfiguration)           // Get cache configuration
+		cacheRoute.POST("/config/default-ttl", r.Handler.SetDefaultTTL) // Change the TTL of puts without one
+
+		// Background cleanup control
+		cacheRoute.GET("/cleanup", r.Handler.GetCleanup)            // Cleanup worker status
+		cacheRoute.POST("/cleanup/run", r.Handler.RunCleanup)       // Reap expired entries now
+		cacheRoute.POST("/cleanup/pause", r.Handler.PauseCleanup)   // Stop background sweeps
+		cacheRoute.POST("/cleanup/resume", r.Handler.ResumeCleanup) // Restart background sweeps
+
+		// Debugging
+		cacheRoute.GET("/debug/workers", r.Handler.GetWorkers)    // Background worker liveness
+		cacheRoute.GET("/debug/lru-order", r.Handler.GetLRUOrder) // Keys from most to least recently used (DEBUG only)
 	}
 }
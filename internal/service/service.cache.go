@@ -2,22 +2,139 @@ package service
 
 import (
 	"fmt"
+	"math/rand/v2"
+	"path"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Vinodbagra/cache-thread/internal/constants"
 	"github.com/Vinodbagra/cache-thread/internal/models"
 )
 
+// backpressureSampleSize is how many entries Put inspects when estimating expired backlog
+const backpressureSampleSize = 20
+
+// minCompactSize is the peak map size below which automatic compaction is skipped
+const minCompactSize = 1024
+
+// NoExpiration passed as a TTL stores a key that never expires, overriding the default TTL
+const NoExpiration time.Duration = -1
+
+// CacheOptions holds the tunables used to construct a CacheService
+type CacheOptions struct {
+	MaxSize    int
+	DefaultTTL time.Duration
+
+	// Backpressure kicks in when the estimated number of expired-but-not-reaped
+	// entries exceeds BackpressureThreshold (0 disables it)
+	BackpressureThreshold int
+	BackpressureMode      string // constants.BackpressureModeCleanup or constants.BackpressureModeReject
+
+	// The cache is thrashing while the rolling eviction rate exceeds ThrashThreshold
+	// evictions per second (0 disables detection); ThrashMode says what happens then
+	ThrashThreshold float64
+	ThrashMode      string // constants.ThrashModeWarn (the default) or constants.ThrashModeReject
+
+	// EncryptionKey enables AES-GCM encryption of stored values when set (16, 24 or 32 bytes)
+	EncryptionKey []byte
+
+	// OpLogSize is how many recent mutating operations are kept for auditing (0 disables it)
+	OpLogSize int
+
+	// KeyHashThreshold stores keys longer than this many bytes as their SHA-256 digest (0 disables it)
+	KeyHashThreshold int
+
+	// ReadOnly rejects every write, for replicas that only serve reads
+	ReadOnly bool
+
+	// StaleWhileRevalidate keeps serving entries this long past expiration, flagged stale,
+	// while OnRevalidate callbacks refresh them (0 disables it)
+	StaleWhileRevalidate time.Duration
+
+	// CompactThreshold rebuilds the key map once this fraction of its peak size has been
+	// deleted, returning the memory Go maps keep after deletions (0 disables it)
+	CompactThreshold float64
+
+	// TTLPromotionStep extends an entry's expiration by this much on every Get hit, so
+	// frequently read keys live longer; its remaining TTL never exceeds TTLPromotionMax
+	// (0 means uncapped). Entries without an expiration are unaffected. 0 disables it.
+	TTLPromotionStep time.Duration
+	TTLPromotionMax  time.Duration
+
+	// MaxTTL caps how far in the future a put, touch or expire may set an expiration
+	// (0 means uncapped). Longer ones are clamped to MaxTTL, or rejected with
+	// ErrTTLTooLong when MaxTTLMode is constants.MaxTTLModeReject. Entries without an
+	// expiration are unaffected.
+	MaxTTL     time.Duration
+	MaxTTLMode string // constants.MaxTTLModeClamp (the default) or constants.MaxTTLModeReject
+
+	// FreezeTimeout thaws a frozen cache automatically after this long (default 30s)
+	FreezeTimeout time.Duration
+
+	// When the cache holds HighWatermark entries (default and at most MaxSize) and a new key
+	// arrives, least recently used entries are evicted until LowWatermark remain after the
+	// insert, so workloads hovering at the limit do not evict on every Put. LowWatermark
+	// defaults to HighWatermark, which evicts a single entry as before.
+	HighWatermark int
+	LowWatermark  int
+
+	// CleanupJitter delays the first background cleanup by a random duration up to this
+	// long, so instances started together do not reap in lockstep (0 disables it)
+	CleanupJitter time.Duration
+
+	// DisableCleanup never starts the background cleanup worker. Expired entries are then
+	// only removed lazily when accessed, by eviction, by backpressure or by RunCleanup.
+	DisableCleanup bool
+
+	// EvictionPolicy chooses which entry a full cache evicts: constants.EvictionPolicyLRU
+	// (the default), constants.EvictionPolicyLRUK, which keeps the last LRUK access
+	// times per entry (default 2) so one-off scans do not push out frequently read keys,
+	// or constants.EvictionPolicyClock, which only flags entries on access
+	EvictionPolicy string
+	LRUK           int
+
+	// ChecksumValues stores a CRC32 of every value written and verifies it on Get, so a
+	// value changed in memory is detected: it is removed, counted and reported to
+	// OnCorruption callbacks, and the read misses. Costs a serialization per write and read.
+	ChecksumValues bool
+
+	// BatchPromotions serves Get hits under the read lock, so reads no longer serialize
+	// on the write lock. Their moves to the head of the recency list are queued and
+	// applied in batches every few milliseconds and before any eviction, so eviction
+	// order and accessed_at trail reads by up to one batch. Reads of keys with an idle
+	// limit, stale reads and reads with TTL promotion still take the write lock.
+	BatchPromotions bool
+}
+
 // CacheService implements the cache business logic
 type CacheService struct {
-	data         map[string]*models.CacheEntry
-	head         *models.CacheEntry // Most recently used
-	tail         *models.CacheEntry // Least recently used
-	maxSize      int
-	defaultTTL   time.Duration
-	startTime    time.Time
-	
-	// Statistics
+	data       map[string]*models.CacheEntry
+	head       *models.CacheEntry // Most recently used
+	tail       *models.CacheEntry // Least recently used
+	maxSize    int
+	defaultTTL int64 // a time.Duration, accessed with sync/atomic since it can change at runtime
+	startTime  time.Time
+
+	// Batch eviction: starts when a new key arrives at highWatermark entries and
+	// continues until lowWatermark remain after the insert
+	highWatermark int
+	lowWatermark  int
+
+	// Chooses each eviction's victim
+	evictionPolicyName string
+	eviction           evictionPolicy
+
+	// Backpressure against expired entries piling up faster than cleanup reaps them
+	backpressureThreshold int
+	backpressureMode      string
+
+	// Rolling eviction rate and the protective mode entered when it is too high
+	thrash thrashState
+
+	// Statistics, updated with sync/atomic so GetStats reads them without the lock
 	hits            int64
 	misses          int64
 	evictions       int64
@@ -27,19 +144,166 @@ type CacheService struct {
 	mutex       sync.RWMutex
 	cleanupDone chan bool
 	stopCleanup chan bool
+
+	// Background cleanup statistics and pause state
+	cleanup cleanupState
+
+	// Random delay before the first cleanup; jitter returns a duration in [0, max)
+	cleanupJitter time.Duration
+	jitter        func(max time.Duration) time.Duration
+	cleanupEvery  time.Duration // time between sweeps: cleanupInterval, shortened only by tests
+
+	// Optional front tier notified when entries change or leave the cache
+	hot hotTier
+
+	// Set once the instance is draining for shutdown; writes are rejected afterwards
+	draining int32
+
+	// Encrypts values at rest when an encryption key is configured
+	cipher *valueCipher
+
+	// Callbacks fired once for every entry removed because it expired
+	onExpire []ExpireCallback
+
+	// Integrity checks of stored values against their write-time checksum
+	checksums checksumState
+
+	// Recent mutating operations for audit/replay
+	opLog *opLog
+
+	// Keys longer than this are stored hashed
+	keyHashThreshold int
+
+	// Read-only replicas reject every write
+	readOnly bool
+
+	// Publish/subscribe registry, separate from the key-value store
+	pubsub *pubSub
+
+	// Grace period past expiration during which entries are served stale
+	staleGrace   time.Duration
+	onRevalidate []RevalidateCallback
+
+	// Automatic map compaction after mass deletions
+	compactThreshold float64
+	peakSize         int // largest map size since the map was last rebuilt
+
+	// Expiration growth on access
+	ttlPromotionStep time.Duration
+	ttlPromotionMax  time.Duration
+
+	// Upper bound on expirations set by writes
+	maxTTL     time.Duration
+	maxTTLMode string
+
+	// Write pausing for consistent snapshots
+	freeze        freezeState
+	freezeTimeout time.Duration
+
+	// Asynchronous persistence to a log and snapshot; nil when off
+	persist *writeBehind
+
+	// Value rewrites applied on put and get
+	transforms transformState
+
+	// Reads served under the read lock, waiting to be applied to the recency list; nil
+	// unless promotions are batched
+	promotions *promotionQueue
+
+	// Sampled operation latencies for percentile reporting
+	getLatency *latencySampler
+	putLatency *latencySampler
+
+	// Serialized sizes of stored values
+	valueSizes valueSizeStats
+}
+
+// ExpireCallback is invoked with the key and value of an entry removed because it expired
+type ExpireCallback func(key string, value interface{})
+
+// RevalidateCallback is invoked with the key and stale value of an entry served within
+// the stale-while-revalidate grace window; it is expected to Put a fresh value
+type RevalidateCallback func(key string, value interface{})
+
+// hotTier is notified by CacheService whenever an entry changes or leaves the cache
+type hotTier interface {
+	invalidate(key string)
+	invalidateAll()
 }
 
 // NewCacheService creates a new cache service instance
 func NewCacheService(maxSize int, defaultTTL time.Duration) *CacheService {
+	return NewCacheServiceWithOptions(CacheOptions{
+		MaxSize:    maxSize,
+		DefaultTTL: defaultTTL,
+	})
+}
+
+// NewCacheServiceWithOptions creates a new cache service instance from the given options
+func NewCacheServiceWithOptions(opts CacheOptions) *CacheService {
 	service := &CacheService{
-		data:        make(map[string]*models.CacheEntry),
-		maxSize:     maxSize,
-		defaultTTL:  defaultTTL,
-		startTime:   time.Now(),
-		cleanupDone: make(chan bool),
-		stopCleanup: make(chan bool),
+		data:                  make(map[string]*models.CacheEntry),
+		maxSize:               opts.MaxSize,
+		highWatermark:         opts.HighWatermark,
+		lowWatermark:          opts.LowWatermark,
+		defaultTTL:            int64(opts.DefaultTTL),
+		startTime:             time.Now(),
+		backpressureThreshold: opts.BackpressureThreshold,
+		backpressureMode:      opts.BackpressureMode,
+		thrash:                thrashState{threshold: opts.ThrashThreshold, mode: opts.ThrashMode},
+		checksums:             checksumState{enabled: opts.ChecksumValues},
+		keyHashThreshold:      opts.KeyHashThreshold,
+		readOnly:              opts.ReadOnly,
+		pubsub:                newPubSub(),
+		staleGrace:            opts.StaleWhileRevalidate,
+		compactThreshold:      opts.CompactThreshold,
+		ttlPromotionStep:      opts.TTLPromotionStep,
+		ttlPromotionMax:       opts.TTLPromotionMax,
+		maxTTL:                opts.MaxTTL,
+		maxTTLMode:            opts.MaxTTLMode,
+		freezeTimeout:         opts.FreezeTimeout,
+		cleanupJitter:         opts.CleanupJitter,
+		jitter:                rand.N[time.Duration],
+		cleanupEvery:          cleanupInterval,
+		getLatency:            newLatencySampler(),
+		putLatency:            newLatencySampler(),
+		cleanupDone:           make(chan bool),
+		stopCleanup:           make(chan bool),
 	}
 	
+	if service.highWatermark <= 0 || service.highWatermark > service.maxSize {
+		service.highWatermark = service.maxSize
+	}
+	if service.lowWatermark <= 0 || service.lowWatermark > service.highWatermark {
+		service.lowWatermark = service.highWatermark
+	}
+
+	if service.freezeTimeout <= 0 {
+		service.freezeTimeout = defaultFreezeTimeout
+	}
+
+	if service.maxTTL > 0 && service.DefaultTTL() > service.maxTTL {
+		service.defaultTTL = int64(service.maxTTL)
+	}
+
+	if opts.OpLogSize > 0 {
+		service.opLog = newOpLog(opts.OpLogSize)
+	}
+
+	if len(opts.EncryptionKey) > 0 {
+		valueCipher, err := newValueCipher(opts.EncryptionKey)
+		if err != nil {
+			panic(fmt.Sprintf("invalid cache encryption key: %v", err))
+		}
+		service.cipher = valueCipher
+	}
+
+	service.evictionPolicyName = opts.EvictionPolicy
+	if service.evictionPolicyName == "" {
+		service.evictionPolicyName = constants.EvictionPolicyLRU
+	}
+	service.eviction = newEvictionPolicy(service, opts)
+
 	// Initialize doubly linked list with sentinel nodes
 	service.head = &models.CacheEntry{}
 	service.tail = &models.CacheEntry{}
@@ -47,94 +311,486 @@ func NewCacheService(maxSize int, defaultTTL time.Duration) *CacheService {
 	service.tail.Prev = service.head
 	
 	// Start background cleanup goroutine
-	go service.cleanupWorker()
+	service.cleanup.disabled = opts.DisableCleanup
+	if !service.cleanup.disabled {
+		go service.cleanupWorker()
+	}
 	
+	if opts.BatchPromotions {
+		service.promotions = newPromotionQueue()
+		go service.promotionWorker()
+	}
+
 	return service
 }
 
-// Put inserts or updates a key-value pair with optional TTL
-func (cs *CacheService) Put(key string, value interface{}, ttl *time.Duration) error {
+// Put inserts or updates a key-value pair with optional TTL.
+// It reports whether a new entry was created rather than an existing one updated.
+func (cs *CacheService) Put(key string, value interface{}, ttl *time.Duration) (bool, error) {
+	return cs.put(key, value, cs.expirationFor(ttl), 0, false, nil)
+}
+
+// PutPreserveTTL updates the value of a live key while keeping its expiration and
+// creation time. ttl only applies when the key is missing or expired and a new entry is stored.
+func (cs *CacheService) PutPreserveTTL(key string, value interface{}, ttl *time.Duration) (bool, error) {
+	return cs.put(key, value, cs.expirationFor(ttl), 0, true, nil)
+}
+
+// expirationFor converts an optional TTL into an absolute Unix expiration, falling back to the default TTL
+func (cs *CacheService) expirationFor(ttl *time.Duration) int64 {
+	if ttl != nil && *ttl == NoExpiration {
+		return 0
+	}
+	if ttl != nil && *ttl > 0 {
+		return time.Now().Add(*ttl).Unix()
+	} else if defaultTTL := cs.DefaultTTL(); defaultTTL > 0 {
+		return time.Now().Add(defaultTTL).Unix()
+	}
+	return 0
+}
+
+// DefaultTTL returns the TTL applied to puts that do not set one (0 means they never expire)
+func (cs *CacheService) DefaultTTL() time.Duration {
+	return time.Duration(atomic.LoadInt64(&cs.defaultTTL))
+}
+
+// SetDefaultTTL changes the TTL applied to later puts that do not set one; 0 makes them
+// never expire. Existing entries keep their expiration. A ttl over MaxTTL is clamped to it
+// like any other. It returns the new default, ErrNegativeTTL when ttl is negative, or
+// ErrTTLTooLong when it is over MaxTTL in reject mode.
+func (cs *CacheService) SetDefaultTTL(ttl time.Duration) (time.Duration, error) {
+	if ttl < 0 {
+		return 0, constants.ErrNegativeTTL
+	}
+	ttl, err := cs.CapTTL(ttl)
+	if err != nil {
+		return 0, err
+	}
+	atomic.StoreInt64(&cs.defaultTTL, int64(ttl))
+	return ttl, nil
+}
+
+// MaxTTL returns the longest TTL a write may set (0 means uncapped)
+func (cs *CacheService) MaxTTL() time.Duration {
+	return cs.maxTTL
+}
+
+// CapTTL applies MaxTTL to a TTL a write asks for: a longer one is clamped to MaxTTL, or
+// returns ErrTTLTooLong in reject mode. Other TTLs are returned unchanged.
+func (cs *CacheService) CapTTL(ttl time.Duration) (time.Duration, error) {
+	if cs.maxTTL <= 0 || ttl <= cs.maxTTL {
+		return ttl, nil
+	}
+	if cs.maxTTLMode == constants.MaxTTLModeReject {
+		return 0, constants.ErrTTLTooLong
+	}
+	return cs.maxTTL, nil
+}
+
+// capExpiration applies MaxTTL to an absolute Unix expiration; 0 (no expiration) is kept
+func (cs *CacheService) capExpiration(expiration int64) (int64, error) {
+	if cs.maxTTL <= 0 || expiration == 0 {
+		return expiration, nil
+	}
+	ceiling := time.Now().Add(cs.maxTTL).Unix()
+	if expiration <= ceiling {
+		return expiration, nil
+	}
+	if cs.maxTTLMode == constants.MaxTTLModeReject {
+		return 0, constants.ErrTTLTooLong
+	}
+	return ceiling, nil
+}
+
+// PutAt stores a key-value pair that expires at the given wall-clock time.
+// A time in the past stores the entry already expired, so the next Get misses.
+func (cs *CacheService) PutAt(key string, value interface{}, at time.Time) (bool, error) {
+	return cs.put(key, value, expirationAt(at), 0, false, nil)
+}
+
+// expirationAt converts a wall-clock time into an absolute Unix expiration
+func expirationAt(at time.Time) int64 {
+	expiration := at.Unix()
+	if expiration <= 0 {
+		expiration = 1 // 0 means "no expiration", keep pre-epoch times expired
+	}
+	return expiration
+}
+
+// PutItem stores a put request the way the put endpoint does: its expiration comes from
+// ExpireAt, TTL (or defaultTTL when neither is set), Persistent or the default TTL, and
+// MaxIdle adds an idle limit. Conflicting fields return ErrConflictingExpiration.
+func (cs *CacheService) PutItem(item models.PutRequest, defaultTTL *int) (bool, error) {
+	return cs.putItem(item, defaultTTL, nil)
+}
+
+// PutItemEvicting is PutItem that also returns the entries evicted to make room for a new
+// key, with their values, or nil when nothing was evicted. Keys stored hashed because of
+// KeyHashThreshold are returned in their hashed form.
+func (cs *CacheService) PutItemEvicting(item models.PutRequest, defaultTTL *int) (bool, []models.GetResponse, error) {
+	var evicted []models.GetResponse
+	created, err := cs.putItem(item, defaultTTL, &evicted)
+	return created, evicted, err
+}
+
+// putItem implements PutItem, appending evicted entries to evicted when it is not nil
+func (cs *CacheService) putItem(item models.PutRequest, defaultTTL *int, evicted *[]models.GetResponse) (bool, error) {
+	expiration, maxIdle, err := cs.itemExpiration(item, defaultTTL)
+	if err != nil {
+		return false, err
+	}
+	return cs.put(item.Key, item.Value, expiration, maxIdle, item.PreserveTTL, evicted)
+}
+
+// itemExpiration returns the absolute Unix expiration (0 means none) and idle limit a put
+// request asks for, as described on PutItem
+func (cs *CacheService) itemExpiration(item models.PutRequest, defaultTTL *int) (int64, time.Duration, error) {
+	switch {
+	case item.TTL != nil && item.ExpireAt != nil:
+		return 0, 0, fmt.Errorf("%w: ttl and expire_at cannot both be set", constants.ErrConflictingExpiration)
+	case item.PreserveTTL && item.ExpireAt != nil:
+		return 0, 0, fmt.Errorf("%w: preserve_ttl cannot be combined with expire_at", constants.ErrConflictingExpiration)
+	case item.Persistent && (item.TTL != nil || item.ExpireAt != nil):
+		return 0, 0, fmt.Errorf("%w: persistent cannot be combined with ttl or expire_at", constants.ErrConflictingExpiration)
+	}
+
+	var maxIdle time.Duration
+	if item.MaxIdle != nil && *item.MaxIdle > 0 {
+		maxIdle = time.Duration(*item.MaxIdle) * time.Second
+	}
+
+	if item.ExpireAt != nil {
+		return expirationAt(item.ExpireAt.Time), maxIdle, nil
+	}
+
+	itemTTL := item.TTL
+	if itemTTL == nil {
+		itemTTL = defaultTTL
+	}
+	var ttl *time.Duration
+	if item.Persistent {
+		noExpiration := NoExpiration
+		ttl = &noExpiration
+	} else if itemTTL != nil && *itemTTL > 0 {
+		duration := time.Duration(*itemTTL) * time.Second
+		ttl = &duration
+	}
+	return cs.expirationFor(ttl), maxIdle, nil
+}
+
+// put stores a key-value pair with an absolute Unix expiration (0 means none) and an
+// idle limit (0 means none). With keepTTL a live existing entry keeps both of its own.
+// When evicted is not nil, entries evicted to make room are appended to it. It reports
+// whether the key was created, which includes replacing a key expired past its stale grace.
+func (cs *CacheService) put(key string, value interface{}, expiration int64, maxIdle time.Duration, keepTTL bool, evicted *[]models.GetResponse) (bool, error) {
+	defer cs.putLatency.observe(cs.putLatency.start())
+
 	if key == "" {
-		return fmt.Errorf("key cannot be empty")
+		return false, constants.ErrKeyEmpty
+	}
+	if err := cs.writeGuard(); err != nil {
+		return false, err
+	}
+	expiration, err := cs.capExpiration(expiration)
+	if err != nil {
+		return false, err
 	}
+	value = cs.transformPut(key, value)
+	key = cs.internalKey(key)
 	
+	// Measure and encrypt outside the lock so neither extends the critical section
+	size := valueSize(value)
+	var nonce []byte
+	if cs.cipher != nil {
+		ciphertext, n, err := cs.cipher.seal(value)
+		if err != nil {
+			return false, err
+		}
+		value, nonce = ciphertext, n
+	}
+	checksum := cs.valueChecksum(value)
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
 	cs.mutex.Lock()
 	defer cs.mutex.Unlock()
 	
-	var expiration int64
-	if ttl != nil && *ttl > 0 {
-		expiration = time.Now().Add(*ttl).Unix()
-	} else if cs.defaultTTL > 0 {
-		expiration = time.Now().Add(cs.defaultTTL).Unix()
-	}
-	
 	now := time.Now()
 	
-	if entry, exists := cs.data[key]; exists {
+	// A key expired past its stale grace is only awaiting cleanup: expire it and store the
+	// value as a new key. A stale key still being served is refreshed in place.
+	if entry, exists := cs.data[key]; exists && cs.pastGrace(entry) {
+		cs.expireEntry(entry)
+	} else if exists {
 		// Update existing entry
-		entry.Value = value
-		entry.Expiration = expiration
+		if !keepTTL || entry.IsExpired() {
+			entry.Expiration = expiration
+			entry.MaxIdle = maxIdle
+		}
+		entry.SetValue(value)
+		entry.Nonce = nonce
+		entry.Checksum = checksum
+		cs.storeValueSize(entry, size)
+		entry.ModifiedAt = now
 		entry.AccessedAt = now
-		cs.moveToHead(entry)
-	} else {
-		// Create new entry
-		entry := &models.CacheEntry{
-			Key:        key,
-			Value:      value,
-			Expiration: expiration,
-			CreatedAt:  now,
-			AccessedAt: now,
-		}
-		
-		// Check if we need to evict
-		if len(cs.data) >= cs.maxSize {
-			cs.evictLRU()
-		}
-		
-		cs.data[key] = entry
-		cs.addToHead(entry)
+		entry.Revalidating = false
+		cs.writeTouch(entry)
+		if cs.hot != nil {
+			cs.hot.invalidate(key)
+		}
+		cs.persist.changed(key)
+		cs.opLog.record(OpPut, key)
+		return false, nil
 	}
 	
-	return nil
+	entry, victims, err := cs.insertEntry(key, value, nonce, size, expiration)
+	if err != nil {
+		return false, err
+	}
+	entry.MaxIdle = maxIdle
+	entry.Checksum = checksum
+	if evicted != nil {
+		*evicted = cs.evictedResponses(victims)
+	}
+
+	return true, nil
+}
+
+// insertEntry adds a new entry for a key that is not in the cache, evicting if full, and
+// returns it with the entries evicted. Callers must hold the write lock and pass an already
+// encrypted value when a cipher is set, along with the serialized size of the plain value.
+func (cs *CacheService) insertEntry(key string, value interface{}, nonce []byte, size int64, expiration int64) (*models.CacheEntry, []*models.CacheEntry, error) {
+	// Make sure expired entries are not crowding out live ones before growing the cache
+	if err := cs.applyBackpressure(); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	entry := &models.CacheEntry{
+		Key:        key,
+		Nonce:      nonce,
+		Expiration: expiration,
+		CreatedAt:  now,
+		ModifiedAt: now,
+		AccessedAt: now,
+	}
+	entry.SetValue(value)
+
+	// At the high watermark, evict in one pass down to the low watermark
+	var evicted []*models.CacheEntry
+	if len(cs.data) >= cs.highWatermark {
+		// Making room would evict; while thrashing that may be refused
+		if err := cs.updateThrashing(); err != nil {
+			return nil, nil, err
+		}
+		// Let reads not yet applied to the recency list protect their entries
+		cs.applyPromotions()
+		for len(cs.data) >= cs.lowWatermark && cs.tail.Prev != cs.head {
+			if victim := cs.evict(); victim != nil {
+				evicted = append(evicted, victim)
+			}
+		}
+	}
+
+	cs.data[key] = entry
+	cs.addToHead(entry)
+	cs.eviction.accessed(entry)
+	cs.eviction.added(entry)
+	cs.storeValueSize(entry, size)
+	cs.persist.changed(key)
+	cs.opLog.record(OpPut, key)
+	if len(cs.data) > cs.peakSize {
+		cs.peakSize = len(cs.data)
+	}
+
+	return entry, evicted, nil
 }
 
 // Get retrieves a value by key and updates access order
 func (cs *CacheService) Get(key string) (*models.CacheEntry, bool) {
+	defer cs.getLatency.observe(cs.getLatency.start())
+
 	if key == "" {
 		return nil, false
 	}
+	clientKey, key := key, cs.internalKey(key)
 	
+	if cs.promotions != nil {
+		if entry, found, handled := cs.getShared(clientKey, key); handled {
+			return entry, found
+		}
+	}
+
 	cs.mutex.Lock()
 	defer cs.mutex.Unlock()
+	cs.applyPromotions()
 	
 	entry, exists := cs.data[key]
 	if !exists {
-		cs.misses++
+		atomic.AddInt64(&cs.misses, 1)
 		return nil, false
 	}
 	
-	// Check if entry has expired
-	if entry.IsExpired() {
-		cs.removeEntry(entry)
-		cs.expiredRemovals++
-		cs.misses++
+	// Check if entry has expired; within the stale grace window it is still served
+	if cs.pastGrace(entry) {
+		cs.expireEntry(entry)
+		atomic.AddInt64(&cs.misses, 1)
+		return nil, false
+	}
+	stale := entry.IsExpired()
+	if !cs.intact(entry) {
+		cs.dropCorrupted(clientKey, entry)
+		atomic.AddInt64(&cs.misses, 1)
 		return nil, false
 	}
 	
 	// Update access time and move to head (most recently used)
 	entry.UpdateAccessTime()
+	atomic.AddInt64(&entry.AccessCount, 1)
 	cs.moveToHead(entry)
-	cs.hits++
+	if !stale {
+		cs.promoteTTL(entry)
+	}
+
+	if cs.cipher != nil {
+		plain, err := cs.decryptedCopy(entry)
+		if err != nil {
+			atomic.AddInt64(&cs.misses, 1)
+			return nil, false
+		}
+		entry = plain
+	}
+	atomic.AddInt64(&cs.hits, 1)
 	
-	return entry, true
+	if stale {
+		cs.revalidate(clientKey, entry)
+	}
+
+	return cs.transformGet(clientKey, entry), true
+}
+
+// GetOrPut returns the live value for key, or atomically stores and returns defaultValue
+// when the key is missing or expired. created reports whether the default was stored.
+// While the cache is frozen hits are still served; only storing the default waits for Thaw.
+func (cs *CacheService) GetOrPut(key string, defaultValue interface{}, ttl *time.Duration) (*models.CacheEntry, bool, error) {
+	if !cs.freeze.gate.TryRLock() {
+		entry, created, err := cs.getOrPut(key, defaultValue, ttl, false)
+		if err != errWaitForThaw {
+			return entry, created, err
+		}
+		cs.freeze.gate.RLock()
+	}
+	defer cs.freeze.gate.RUnlock()
+
+	return cs.getOrPut(key, defaultValue, ttl, true)
+}
+
+// getOrPut implements GetOrPut. canWrite is false while the cache is frozen, in which
+// case a miss returns errWaitForThaw instead of storing the default.
+func (cs *CacheService) getOrPut(key string, defaultValue interface{}, ttl *time.Duration, canWrite bool) (*models.CacheEntry, bool, error) {
+	if key == "" {
+		return nil, false, constants.ErrKeyEmpty
+	}
+	clientKey, key := key, cs.internalKey(key)
+	defaultValue = cs.transformPut(clientKey, defaultValue)
+
+	// Measure and encrypt the default up front so the lock is not held meanwhile
+	size := valueSize(defaultValue)
+	stored, nonce := defaultValue, []byte(nil)
+	if cs.cipher != nil {
+		ciphertext, n, err := cs.cipher.seal(defaultValue)
+		if err != nil {
+			return nil, false, err
+		}
+		stored, nonce = ciphertext, n
+	}
+	checksum := cs.valueChecksum(stored)
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	if entry, exists := cs.data[key]; exists {
+		if !entry.IsExpired() && cs.intact(entry) {
+			entry.UpdateAccessTime()
+			atomic.AddInt64(&entry.AccessCount, 1)
+			cs.moveToHead(entry)
+			cs.promoteTTL(entry)
+			atomic.AddInt64(&cs.hits, 1)
+
+			if cs.cipher != nil {
+				plain, err := cs.decryptedCopy(entry)
+				if err != nil {
+					return nil, false, err
+				}
+				entry = plain
+			}
+			return cs.transformGet(clientKey, entry), false, nil
+		}
+		if entry.IsExpired() {
+			cs.expireEntry(entry)
+		} else {
+			cs.dropCorrupted(clientKey, entry)
+		}
+	}
+	if !canWrite {
+		return nil, false, errWaitForThaw
+	}
+	atomic.AddInt64(&cs.misses, 1)
+
+	if err := cs.writeGuard(); err != nil {
+		return nil, false, err
+	}
+
+	expiration, err := cs.capExpiration(cs.expirationFor(ttl))
+	if err != nil {
+		return nil, false, err
+	}
+	entry, _, err := cs.insertEntry(key, stored, nonce, size, expiration)
+	if err != nil {
+		return nil, false, err
+	}
+	entry.Checksum = checksum
+
+	if cs.cipher != nil {
+		copied := *entry
+		copied.SetValue(defaultValue)
+		copied.Nonce = nil
+		entry = &copied
+	}
+	return cs.transformGet(clientKey, entry), true, nil
+}
+
+// GetInfo returns access statistics for a key without promoting it in LRU order
+func (cs *CacheService) GetInfo(key string) (models.KeyInfoResponse, bool) {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	entry, exists := cs.data[cs.internalKey(key)]
+	if !exists || entry.IsExpired() {
+		return models.KeyInfoResponse{}, false
+	}
+
+	return models.KeyInfoResponse{
+		Key:         key,
+		CreatedAt:   entry.CreatedAt,
+		AccessedAt:  entry.AccessedAt,
+		AccessCount: atomic.LoadInt64(&entry.AccessCount),
+		TTL:         entry.GetTTL(),
+		MaxIdle:     int64(entry.MaxIdle / time.Second),
+	}, true
 }
 
 // Delete removes a specific key from the cache
 func (cs *CacheService) Delete(key string) (bool, bool) {
-	if key == "" {
+	if key == "" || cs.writeGuard() != nil {
 		return false, false
 	}
+	key = cs.internalKey(key)
 	
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
 	cs.mutex.Lock()
 	defer cs.mutex.Unlock()
 	
@@ -144,93 +800,329 @@ func (cs *CacheService) Delete(key string) (bool, bool) {
 	}
 	
 	cs.removeEntry(entry)
+	cs.opLog.record(OpDelete, key)
+	cs.maybeCompact()
 	return true, true
 }
 
-// Clear removes all entries from the cache
-func (cs *CacheService) Clear() int {
-	cs.mutex.Lock()
-	defer cs.mutex.Unlock()
-	
-	itemsCleared := len(cs.data)
-	cs.data = make(map[string]*models.CacheEntry)
-	cs.head.Next = cs.tail
-	cs.tail.Prev = cs.head
-	
-	return itemsCleared
+// Rename moves an entry to a new key, preserving its value, TTL, access metadata and LRU position.
+// It fails if oldKey is missing, or if newKey exists and overwrite is false. An expired entry
+// at newKey never blocks the rename; it is expired, firing its callbacks, and replaced.
+func (cs *CacheService) Rename(oldKey, newKey string, overwrite bool) error {
+	if oldKey == "" || newKey == "" {
+		return constants.ErrKeyEmpty
+	}
+	if err := cs.writeGuard(); err != nil {
+		return err
+	}
+	oldKey, newKey = cs.internalKey(oldKey), cs.internalKey(newKey)
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	entry, exists := cs.data[oldKey]
+	if !exists {
+		return constants.ErrKeyNotFound
+	}
+	if entry.IsExpired() {
+		cs.expireEntry(entry)
+		return constants.ErrKeyNotFound
+	}
+	if oldKey == newKey {
+		return nil
+	}
+
+	if target, exists := cs.data[newKey]; exists {
+		switch {
+		case target.IsExpired():
+			cs.expireEntry(target)
+		case !overwrite:
+			return constants.ErrKeyExists
+		default:
+			cs.removeEntry(target)
+		}
+	}
+
+	if cs.hot != nil {
+		cs.hot.invalidate(oldKey)
+	}
+	delete(cs.data, oldKey)
+	entry.Key = newKey
+	cs.data[newKey] = entry
+	cs.persist.changed(oldKey)
+	cs.persist.changed(newKey)
+	cs.opLog.recordRename(oldKey, newKey)
+
+	return nil
+}
+
+// Expire sets a live key to expire ttl from now, keeping its value and LRU position. A ttl
+// over MaxTTL is clamped to it. It reports false when the key is missing or expired, ttl is
+// not positive or over MaxTTL in reject mode, or writes are rejected.
+func (cs *CacheService) Expire(key string, ttl time.Duration) bool {
+	if ttl <= 0 {
+		return false
+	}
+	ttl, err := cs.CapTTL(ttl)
+	if err != nil {
+		return false
+	}
+	return cs.setExpiration(key, time.Now().Add(ttl).Unix(), OpExpire)
+}
+
+// Persist removes a live key's expiration and idle limit so it is only ever evicted, never
+// expired. It reports false when the key is missing or expired, or writes are rejected.
+func (cs *CacheService) Persist(key string) bool {
+	return cs.setExpiration(key, 0, OpPersist)
+}
+
+// setExpiration replaces the absolute expiration of a live key
+func (cs *CacheService) setExpiration(key string, expiration int64, op string) bool {
+	if key == "" || cs.writeGuard() != nil {
+		return false
+	}
+	key = cs.internalKey(key)
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	return cs.setExpirationLocked(key, expiration, op)
+}
+
+// setExpirationLocked implements setExpiration for an internal key. Persist also drops the
+// idle limit and touch counts as an access. Callers must hold the write lock.
+func (cs *CacheService) setExpirationLocked(key string, expiration int64, op string) bool {
+	entry, exists := cs.data[key]
+	if !exists {
+		return false
+	}
+	if entry.IsExpired() {
+		cs.expireEntry(entry)
+		return false
+	}
+
+	entry.Expiration = expiration
+	switch op {
+	case OpPersist:
+		entry.MaxIdle = 0
+	case OpTouch:
+		entry.UpdateAccessTime()
+	}
+	if cs.hot != nil {
+		cs.hot.invalidate(key)
+	}
+	cs.persist.changed(key)
+	cs.opLog.record(op, key)
+	return true
+}
+
+// Clear removes all entries from the cache
+func (cs *CacheService) Clear() int {
+	if cs.writeGuard() != nil {
+		return 0
+	}
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	
+	itemsCleared := len(cs.data)
+	if cs.hot != nil {
+		cs.hot.invalidateAll()
+	}
+	cs.dropPromotions()
+	cs.data = make(map[string]*models.CacheEntry)
+	cs.peakSize = 0
+	cs.valueSizes.current = 0
+	cs.head.Next = cs.tail
+	cs.tail.Prev = cs.head
+	cs.eviction.reset(cs.data)
+	cs.persist.clearedAll()
+	cs.opLog.record(OpClear, "")
+	
+	return itemsCleared
+}
+
+// ClearWhere removes every entry matching the predicate and returns how many were removed.
+// Each removal is logged as a delete, so the operation log tells it apart from a full Clear.
+func (cs *CacheService) ClearWhere(predicate func(*models.CacheEntry) bool) int {
+	if cs.writeGuard() != nil {
+		return 0
+	}
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	var matched []*models.CacheEntry
+	for _, entry := range cs.data {
+		if predicate(entry) {
+			matched = append(matched, entry)
+		}
+	}
+
+	for _, entry := range matched {
+		cs.removeEntry(entry)
+		cs.opLog.record(OpDelete, entry.Key)
+	}
+	cs.maybeCompact()
+
+	return len(matched)
 }
 
-// GetStats returns current cache statistics
+// GetStats returns current cache statistics. The counters are read without the lock, so
+// a stats call does not queue behind writers for them; only the size and the eviction rate
+// are read under the read lock. Each counter is read atomically but not all as one
+// snapshot, so hits and misses may be a few operations apart.
 func (cs *CacheService) GetStats() models.CacheStats {
-	cs.mutex.RLock()
-	defer cs.mutex.RUnlock()
-	
-	totalRequests := cs.hits + cs.misses
+	hits := atomic.LoadInt64(&cs.hits)
+	misses := atomic.LoadInt64(&cs.misses)
+	totalRequests := hits + misses
 	var hitRate float64
 	if totalRequests > 0 {
-		hitRate = float64(cs.hits) / float64(totalRequests)
+		hitRate = float64(hits) / float64(totalRequests)
 	}
 	
 	uptime := time.Since(cs.startTime).String()
+	now := time.Now()
 	
+	cs.mutex.RLock()
+	currentSize := len(cs.data)
+	evictionRate := cs.thrash.evictions.rate(now)
+	thrashing := cs.thrash.thrashing(now)
+	cs.mutex.RUnlock()
+
 	return models.CacheStats{
-		Hits:            cs.hits,
-		Misses:          cs.misses,
+		Hits:            hits,
+		Misses:          misses,
 		HitRate:         hitRate,
 		TotalRequests:   totalRequests,
-		CurrentSize:     len(cs.data),
+		CurrentSize:     currentSize,
 		MaxSize:         cs.maxSize,
-		Evictions:       cs.evictions,
-		ExpiredRemovals: cs.expiredRemovals,
+		Evictions:       atomic.LoadInt64(&cs.evictions),
+		EvictionRate:    evictionRate,
+		Thrashing:       thrashing,
+		ExpiredRemovals: atomic.LoadInt64(&cs.expiredRemovals),
 		Uptime:          uptime,
+
+		CorruptionsDetected: atomic.LoadInt64(&cs.checksums.detected),
+	}
+}
+
+// Size returns the current number of entries without computing full statistics
+func (cs *CacheService) Size() int {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	return len(cs.data)
+}
+
+// TTLHistogram counts live entries by remaining TTL. It scans every entry,
+// so it backs the detailed stats rather than every stats call.
+func (cs *CacheService) TTLHistogram() map[string]int {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	buckets := map[string]int{
+		constants.TTLBucketUnderMinute: 0,
+		constants.TTLBucketUnderTenMin: 0,
+		constants.TTLBucketUnderHour:   0,
+		constants.TTLBucketOverHour:    0,
+		constants.TTLBucketNone:        0,
+	}
+
+	now := time.Now().Unix()
+	for _, entry := range cs.data {
+		if entry.Expiration == 0 {
+			buckets[constants.TTLBucketNone]++
+			continue
+		}
+
+		remaining := entry.Expiration - now
+		switch {
+		case remaining < 0:
+			// Expired but not yet reaped, no longer part of the TTL profile
+		case remaining < 60:
+			buckets[constants.TTLBucketUnderMinute]++
+		case remaining < 10*60:
+			buckets[constants.TTLBucketUnderTenMin]++
+		case remaining < 60*60:
+			buckets[constants.TTLBucketUnderHour]++
+		default:
+			buckets[constants.TTLBucketOverHour]++
+		}
 	}
+
+	return buckets
 }
 
 // GetConfiguration returns cache configuration
 func (cs *CacheService) GetConfiguration() models.CacheConfiguration {
 	return models.CacheConfiguration{
 		MaxSize:         cs.maxSize,
-		DefaultTTL:      cs.defaultTTL,
-		CleanupInterval: 30 * time.Second,
+		DefaultTTL:      cs.DefaultTTL(),
+		MaxTTL:          cs.maxTTL,
+		MaxTTLMode:      cs.maxTTLMode,
+		CleanupInterval: cs.cleanupEvery,
+		CleanupDisabled: cs.cleanup.disabled,
+		EvictionPolicy:  cs.evictionPolicyName,
 		StartTime:       cs.startTime,
+		ReadOnly:        cs.readOnly,
+		HighWatermark:   cs.highWatermark,
+		LowWatermark:    cs.lowWatermark,
 	}
 }
 
-// BulkPut performs multiple put operations
-func (cs *CacheService) BulkPut(items []models.PutRequest) models.BulkPutResponse {
+// BulkPut performs multiple put operations.
+// batchTTL (seconds, optional) applies to items without their own TTL before the service default.
+func (cs *CacheService) BulkPut(items []models.PutRequest, batchTTL *int) models.BulkPutResponse {
 	response := models.BulkPutResponse{}
 	
+	response.Results = make([]models.ItemResult, 0, len(items))
+
 	for _, item := range items {
-		var ttl *time.Duration
-		if item.TTL != nil && *item.TTL > 0 {
-			duration := time.Duration(*item.TTL) * time.Second
-			ttl = &duration
-		}
-		
-		if err := cs.Put(item.Key, item.Value, ttl); err != nil {
+		created, err := cs.PutItem(item, batchTTL)
+		result := models.ItemResult{Key: item.Key, Created: created, Err: err}
+		if err != nil {
 			response.Failed++
 			response.Errors = append(response.Errors, fmt.Sprintf("Key '%s': %v", item.Key, err))
+			result.Error = err.Error()
 		} else {
 			response.Successful++
 		}
+		response.Results = append(response.Results, result)
 	}
 	
 	return response
 }
 
-// BulkGet performs multiple get operations
-func (cs *CacheService) BulkGet(keys []string) models.BulkGetResponse {
+// BulkGet performs multiple get operations. Missing keys are reported with found=false and,
+// when defaultValue is not nil, carry it as their value; they still count as not found.
+func (cs *CacheService) BulkGet(keys []string, defaultValue interface{}) models.BulkGetResponse {
 	response := models.BulkGetResponse{
 		Results: make(map[string]models.GetResponse),
 	}
 	
 	for _, key := range keys {
 		if entry, found := cs.Get(key); found {
-			response.Results[key] = entry.ToResponse()
+			result := entry.ToResponse()
+			result.Key = key // report the client key even if it is stored hashed
+			response.Results[key] = result
 			response.Found++
 		} else {
 			response.Results[key] = models.GetResponse{
 				Key:   key,
+				Value: defaultValue,
 				Found: false,
 			}
 			response.NotFound++
@@ -240,7 +1132,70 @@ func (cs *CacheService) BulkGet(keys []string) models.BulkGetResponse {
 	return response
 }
 
-// ListKeys returns all keys in the cache (for debugging)
+// BulkGetTTL returns the remaining TTL in seconds for each key: -1 when the key never
+// expires and -2 when it is missing or expired. Like GetInfo it neither promotes keys nor counts hits.
+func (cs *CacheService) BulkGetTTL(keys []string) map[string]int64 {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	ttls := make(map[string]int64, len(keys))
+	for _, key := range keys {
+		entry, exists := cs.data[cs.internalKey(key)]
+		if !exists || entry.IsExpired() {
+			ttls[key] = -2
+			continue
+		}
+		ttls[key] = entry.GetTTL()
+	}
+
+	return ttls
+}
+
+// BulkExists reports for each key whether it holds a live value. Expired keys count as
+// absent. Like BulkGetTTL it neither promotes keys nor counts hits or misses.
+func (cs *CacheService) BulkExists(keys []string) map[string]bool {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	exists := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		entry, found := cs.data[cs.internalKey(key)]
+		exists[key] = found && !entry.IsExpired()
+	}
+
+	return exists
+}
+
+// BulkTouch sets every live key in keys to expire ttl from now, or after the default TTL
+// when ttl is nil (never, without one), and counts it as an access for idle limits. All
+// keys are updated under a single write lock. Expirations past MaxTTL are clamped to it. It
+// reports for each key whether it was touched; missing and expired keys, and every key while
+// writes are rejected or when ttl is over MaxTTL in reject mode, report false.
+func (cs *CacheService) BulkTouch(keys []string, ttl *time.Duration) map[string]bool {
+	touched := make(map[string]bool, len(keys))
+	expiration, err := cs.capExpiration(cs.expirationFor(ttl))
+	if err != nil || cs.writeGuard() != nil {
+		for _, key := range keys {
+			touched[key] = false
+		}
+		return touched
+	}
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	for _, key := range keys {
+		touched[key] = key != "" && cs.setExpirationLocked(cs.internalKey(key), expiration, OpTouch)
+	}
+
+	return touched
+}
+
+// ListKeys returns all keys in the cache (for debugging).
+// Keys stored hashed because of KeyHashThreshold are returned in their hashed form.
 func (cs *CacheService) ListKeys() []string {
 	cs.mutex.RLock()
 	defer cs.mutex.RUnlock()
@@ -253,10 +1208,130 @@ func (cs *CacheService) ListKeys() []string {
 	return keys
 }
 
-// Close stops the background cleanup worker
+// CountKeys returns how many non-expired keys start with prefix; an empty prefix counts all.
+// Keys stored hashed because of KeyHashThreshold are matched on their hashed form.
+func (cs *CacheService) CountKeys(prefix string) int {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	count := 0
+	for key, entry := range cs.data {
+		if strings.HasPrefix(key, prefix) && !entry.IsExpired() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// ListKeysLimit returns at most limit keys, stopping iteration once the limit is reached
+func (cs *CacheService) ListKeysLimit(limit int) []string {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	if limit > len(cs.data) {
+		limit = len(cs.data)
+	}
+
+	keys := make([]string, 0, limit)
+	for key := range cs.data {
+		if len(keys) == limit {
+			break
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// OnRevalidate registers a callback fired when an entry is first served stale, so the
+// value can be refreshed in the background. It fires once per stale period and runs on
+// its own goroutine; the entry keeps being served stale until a Put replaces it.
+func (cs *CacheService) OnRevalidate(fn RevalidateCallback) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	cs.onRevalidate = append(cs.onRevalidate, fn)
+}
+
+// OnExpire registers a callback fired exactly once per expired entry, whether it is
+// removed by a Get or by the cleanup worker. Callbacks run on their own goroutine.
+func (cs *CacheService) OnExpire(fn ExpireCallback) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	cs.onExpire = append(cs.onExpire, fn)
+}
+
+// OpLog returns the recent mutating operations, newest first
+func (cs *CacheService) OpLog() []models.OpLogEntry {
+	return cs.opLog.snapshot()
+}
+
+// Drain stops the cache from accepting writes ahead of shutdown; reads keep working
+func (cs *CacheService) Drain() {
+	atomic.StoreInt32(&cs.draining, 1)
+}
+
+// IsReadOnly reports whether the cache was configured to reject writes
+func (cs *CacheService) IsReadOnly() bool {
+	return cs.readOnly
+}
+
+// writeGuard returns the reason writes are currently refused, if any
+func (cs *CacheService) writeGuard() error {
+	if cs.readOnly {
+		return constants.ErrReadOnly
+	}
+	if cs.IsDraining() {
+		return constants.ErrDraining
+	}
+	return nil
+}
+
+// IsDraining reports whether Drain has been called
+func (cs *CacheService) IsDraining() bool {
+	return atomic.LoadInt32(&cs.draining) == 1
+}
+
+// Compact rebuilds the key map at its current size. Go maps never shrink, so after
+// deleting most keys the old buckets stay allocated until the map is replaced.
+func (cs *CacheService) Compact() {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	cs.compact()
+}
+
+// compact copies live entries into a right-sized map. Must be called with the lock held.
+func (cs *CacheService) compact() {
+	data := make(map[string]*models.CacheEntry, len(cs.data))
+	for key, entry := range cs.data {
+		data[key] = entry
+	}
+	cs.data = data
+	cs.peakSize = len(data)
+}
+
+// maybeCompact compacts once the deleted fraction of the peak size exceeds the threshold.
+// Small maps are left alone since rebuilding them saves little. Must be called with the lock held.
+func (cs *CacheService) maybeCompact() {
+	if cs.compactThreshold <= 0 || cs.peakSize < minCompactSize {
+		return
+	}
+	if float64(cs.peakSize-len(cs.data)) >= cs.compactThreshold*float64(cs.peakSize) {
+		cs.compact()
+	}
+}
+
+// Close stops the background cleanup worker and the write-behind flusher, flushing what
+// the flusher has not yet written
 func (cs *CacheService) Close() {
 	close(cs.stopCleanup)
-	<-cs.cleanupDone
+	if !cs.cleanup.disabled {
+		<-cs.cleanupDone
+	}
+	cs.CloseWriteBehind()
 }
 
 // Internal methods for LRU management
@@ -275,59 +1350,386 @@ func (cs *CacheService) removeFromList(entry *models.CacheEntry) {
 	entry.Next.Prev = entry.Prev
 }
 
-// moveToHead moves an existing entry to head (mark as most recently used)
+// moveToHead reports an access to the eviction policy and, unless the policy tracks
+// recency itself, moves the entry to head (mark as most recently used)
 func (cs *CacheService) moveToHead(entry *models.CacheEntry) {
+	cs.eviction.accessed(entry)
+	if !cs.eviction.reorders() {
+		return
+	}
 	cs.removeFromList(entry)
 	cs.addToHead(entry)
 }
 
-// evictLRU removes the least recently used entry
-func (cs *CacheService) evictLRU() {
-	if cs.tail.Prev != cs.head {
-		lru := cs.tail.Prev
-		cs.removeEntry(lru)
-		cs.evictions++
+// writeTouch moves an overwritten entry to head. Policies that keep their own access state
+// are not told: an overwrite is not a read, and under clock would otherwise earn the entry
+// a second chance.
+func (cs *CacheService) writeTouch(entry *models.CacheEntry) {
+	if !cs.eviction.reorders() {
+		return
 	}
+	cs.moveToHead(entry)
+}
+
+// evict removes the entry chosen by the eviction policy and returns it, or nil when the
+// cache is empty
+func (cs *CacheService) evict() *models.CacheEntry {
+	victim := cs.eviction.take()
+	if victim != nil {
+		cs.removeEntry(victim)
+		atomic.AddInt64(&cs.evictions, 1)
+		cs.thrash.evictions.record(time.Now())
+	}
+	return victim
+}
+
+// evictedResponses converts evicted entries to responses carrying their plain values,
+// skipping any that fail to decrypt. Must be called with the lock held.
+func (cs *CacheService) evictedResponses(evicted []*models.CacheEntry) []models.GetResponse {
+	if len(evicted) == 0 {
+		return nil
+	}
+
+	responses := make([]models.GetResponse, 0, len(evicted))
+	for _, entry := range evicted {
+		if cs.cipher != nil {
+			plain, err := cs.decryptedCopy(entry)
+			if err != nil {
+				continue
+			}
+			entry = plain
+		}
+		response := entry.ToResponse()
+		response.Stale = false
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// NextEvictionCandidate returns the key the next capacity-triggered eviction would remove
+// (under LRU the least recently used entry) without removing it. It returns false when the cache is empty.
+func (cs *CacheService) NextEvictionCandidate() (string, bool) {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	victim := cs.eviction.victim()
+	if victim == nil {
+		return "", false
+	}
+	return victim.Key, true
+}
+
+// RecencyOrder returns up to limit keys in recency-list order, most recently used first,
+// and the number of entries in the cache. Reads not yet applied to the list under
+// BatchPromotions are applied first. The list is the eviction order under LRU; the clock
+// and LRU-K policies pick victims by their own bookkeeping. Keys stored hashed because of
+// KeyHashThreshold are returned in their hashed form. Meant for debugging eviction.
+func (cs *CacheService) RecencyOrder(limit int) ([]string, int) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.applyPromotions()
+
+	keys := make([]string, 0, min(limit, len(cs.data)))
+	for node := cs.head.Next; node != cs.tail && len(keys) < limit; node = node.Next {
+		keys = append(keys, node.Key)
+	}
+	return keys, len(cs.data)
+}
+
+// DeleteIf removes key only if it still holds expected (compared with reflect.DeepEqual).
+// found reports whether a live entry existed; deleted is false on a mismatch.
+func (cs *CacheService) DeleteIf(key string, expected interface{}) (bool, bool) {
+	if key == "" || cs.writeGuard() != nil {
+		return false, false
+	}
+	key = cs.internalKey(key)
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	entry, exists := cs.data[key]
+	if !exists {
+		return false, false
+	}
+	if entry.IsExpired() {
+		cs.expireEntry(entry)
+		return false, false
+	}
+
+	current := entry.GetValue()
+	if cs.cipher != nil {
+		plain, err := cs.decryptedCopy(entry)
+		if err != nil {
+			return false, true
+		}
+		current = plain.GetValue()
+	}
+	if !reflect.DeepEqual(decodeRaw(current), expected) {
+		return false, true
+	}
+
+	cs.removeEntry(entry)
+	cs.opLog.record(OpDelete, key)
+	cs.maybeCompact()
+	return true, true
+}
+
+// DeleteByPattern removes every key matching the glob pattern (path.Match syntax,
+// e.g. "user:*:temp") and returns how many were removed. Keys stored hashed
+// because of KeyHashThreshold are matched on their hashed form.
+func (cs *CacheService) DeleteByPattern(pattern string) (int, error) {
+	if pattern == "" {
+		return 0, constants.ErrPatternEmpty
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("%w %q: %v", constants.ErrInvalidPattern, pattern, err)
+	}
+	if err := cs.writeGuard(); err != nil {
+		return 0, err
+	}
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	// Collect matches first so the map is not mutated while ranging over it
+	var matched []*models.CacheEntry
+	for key, entry := range cs.data {
+		if ok, _ := path.Match(pattern, key); ok {
+			matched = append(matched, entry)
+		}
+	}
+
+	for _, entry := range matched {
+		cs.removeEntry(entry)
+		cs.opLog.record(OpDelete, entry.Key)
+	}
+	cs.maybeCompact()
+
+	return len(matched), nil
 }
 
-// removeEntry removes an entry from both map and linked list
-func (cs *CacheService) removeEntry(entry *models.CacheEntry) {
+// removeEntry removes an entry from both map and linked list.
+// The entry is tombstoned so a second removal through another path is a no-op;
+// it reports whether this call actually removed the entry.
+func (cs *CacheService) removeEntry(entry *models.CacheEntry) bool {
+	if entry.Removed {
+		return false
+	}
+	entry.Removed = true
+
 	delete(cs.data, entry.Key)
 	cs.removeFromList(entry)
+	cs.eviction.removed(entry)
+	cs.valueSizes.current -= entry.ValueSize
+	if cs.hot != nil {
+		cs.hot.invalidate(entry.Key)
+	}
+	cs.persist.changed(entry.Key)
+	return true
+}
+
+// expireEntry removes an expired entry and fires the expiration callbacks exactly once
+func (cs *CacheService) expireEntry(entry *models.CacheEntry) {
+	if !cs.removeEntry(entry) {
+		return
+	}
+	atomic.AddInt64(&cs.expiredRemovals, 1)
+
+	if len(cs.onExpire) == 0 {
+		return
+	}
+
+	value := entry.GetValue()
+	if cs.cipher != nil {
+		if plain, err := cs.decryptedCopy(entry); err == nil {
+			value = plain.GetValue()
+		} else {
+			value = nil
+		}
+	}
+
+	// Run callbacks outside the lock so they may safely call back into the cache
+	callbacks := cs.onExpire
+	go func(key string) {
+		for _, fn := range callbacks {
+			fn(key, value)
+		}
+	}(entry.Key)
+}
+
+// promoteTTL grows a live entry's expiration by the promotion step, capped at the
+// promotion max from now. Must be called with the lock held.
+func (cs *CacheService) promoteTTL(entry *models.CacheEntry) {
+	if cs.ttlPromotionStep <= 0 || entry.Expiration == 0 {
+		return
+	}
+
+	expiration := entry.Expiration + int64(cs.ttlPromotionStep/time.Second)
+	if cs.ttlPromotionMax > 0 {
+		if ceiling := time.Now().Add(cs.ttlPromotionMax).Unix(); expiration > ceiling {
+			expiration = ceiling
+		}
+	}
+	if expiration > entry.Expiration {
+		entry.Expiration = expiration
+	}
+}
+
+// pastGrace reports whether an entry has expired beyond the stale-while-revalidate window
+func (cs *CacheService) pastGrace(entry *models.CacheEntry) bool {
+	// Serving an idle key stale would count as a read and revive it, so it gets no grace
+	if entry.IsIdleExpired() {
+		return true
+	}
+	if entry.Expiration == 0 {
+		return false
+	}
+	return time.Now().Unix() > entry.Expiration+int64(cs.staleGrace/time.Second)
+}
+
+// revalidate fires the revalidate callbacks for a stale entry unless a refresh is already underway.
+// Must be called with the lock held.
+func (cs *CacheService) revalidate(key string, entry *models.CacheEntry) {
+	live := cs.data[cs.internalKey(key)]
+	if live == nil || live.Revalidating || len(cs.onRevalidate) == 0 {
+		return
+	}
+	live.Revalidating = true
+
+	callbacks, value := cs.onRevalidate, entry.GetValue()
+	go func() {
+		for _, fn := range callbacks {
+			fn(key, value)
+		}
+	}()
+}
+
+// decryptedCopy returns a detached copy of an encrypted entry with its plaintext value.
+// Must be called with the lock held.
+func (cs *CacheService) decryptedCopy(entry *models.CacheEntry) (*models.CacheEntry, error) {
+	value, err := cs.cipher.open(entry.GetValue().([]byte), entry.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	plain := &models.CacheEntry{
+		Key:         entry.Key,
+		Expiration:  entry.Expiration,
+		MaxIdle:     entry.MaxIdle,
+		CreatedAt:   entry.CreatedAt,
+		ModifiedAt:  entry.ModifiedAt,
+		AccessedAt:  entry.AccessedAt,
+		AccessCount: atomic.LoadInt64(&entry.AccessCount),
+	}
+	plain.SetValue(value)
+	return plain, nil
+}
+
+// applyBackpressure estimates the expired backlog from a sample of entries and either
+// reaps expired entries inline or rejects the write when it exceeds the threshold.
+// Must be called with the write lock held.
+func (cs *CacheService) applyBackpressure() error {
+	if cs.backpressureThreshold <= 0 || len(cs.data) <= cs.backpressureThreshold {
+		return nil
+	}
+
+	for {
+		expired, sampled := cs.sampleExpired(backpressureSampleSize)
+		if sampled == 0 || len(expired)*len(cs.data)/sampled <= cs.backpressureThreshold {
+			return nil
+		}
+
+		if cs.backpressureMode == constants.BackpressureModeReject {
+			return constants.ErrCacheBusy
+		}
+
+		for _, entry := range expired {
+			cs.expireEntry(entry)
+		}
+	}
+}
+
+// sampleExpired inspects up to n entries and returns the expired ones among them.
+// Map iteration order is randomized, so repeated calls sample different entries.
+func (cs *CacheService) sampleExpired(n int) ([]*models.CacheEntry, int) {
+	var expired []*models.CacheEntry
+	sampled := 0
+	for _, entry := range cs.data {
+		if sampled == n {
+			break
+		}
+		sampled++
+		if cs.pastGrace(entry) {
+			expired = append(expired, entry)
+		}
+	}
+
+	return expired, sampled
 }
 
 // cleanupWorker runs periodically to remove expired entries
 func (cs *CacheService) cleanupWorker() {
-	ticker := time.NewTicker(30 * time.Second) // Cleanup every 30 seconds
+	cs.cleanup.heartbeat.startedAt.Store(time.Now().UnixNano())
+	cs.cleanup.heartbeat.alive.Store(true)
+
+	// Offset the first tick so instances started together run cleanup at different times
+	if cs.cleanupJitter > 0 {
+		select {
+		case <-time.After(cs.jitter(cs.cleanupJitter)):
+		case <-cs.stopCleanup:
+			cs.cleanup.heartbeat.alive.Store(false)
+			cs.cleanupDone <- true
+			return
+		}
+	}
+
+	ticker := time.NewTicker(cs.cleanupEvery)
 	defer ticker.Stop()
 	
 	for {
 		select {
 		case <-ticker.C:
-			cs.cleanupExpired()
+			cs.cleanup.heartbeat.lastTick.Store(time.Now().UnixNano())
+			cs.cleanup.heartbeat.ticks.Add(1)
+			cs.backgroundSweep()
 		case <-cs.stopCleanup:
+			cs.cleanup.heartbeat.alive.Store(false)
 			cs.cleanupDone <- true
 			return
 		}
 	}
 }
 
-// cleanupExpired removes all expired entries
-func (cs *CacheService) cleanupExpired() {
+// cleanupExpired removes all expired entries and returns how many it removed. It skips
+// the run, reporting false, while the cache is frozen.
+func (cs *CacheService) cleanupExpired() (int, bool) {
+	if !cs.freeze.gate.TryRLock() {
+		return 0, false
+	}
+	defer cs.freeze.gate.RUnlock()
+
 	cs.mutex.Lock()
 	defer cs.mutex.Unlock()
 	
 	var expiredKeys []string
 	for key, entry := range cs.data {
-		if entry.IsExpired() {
+		if cs.pastGrace(entry) {
 			expiredKeys = append(expiredKeys, key)
 		}
 	}
 	
 	for _, key := range expiredKeys {
 		if entry, exists := cs.data[key]; exists {
-			cs.removeEntry(entry)
-			cs.expiredRemovals++
+			cs.expireEntry(entry)
 		}
 	}
+	cs.maybeCompact()
+
+	return len(expiredKeys), true
 }
\ No newline at end of file
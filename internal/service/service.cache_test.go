@@ -0,0 +1,506 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// expireNow backdates key's expiration so it is expired but not yet reaped
+func expireNow(cs *CacheService, key string) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.data[key].Expiration = time.Now().Add(-time.Second).Unix()
+}
+
+// expireCounter counts expiration callbacks per key
+type expireCounter struct {
+	mutex sync.Mutex
+	calls map[string]int
+	total int
+}
+
+func newExpireCounter(cs *CacheService) *expireCounter {
+	counter := &expireCounter{calls: make(map[string]int)}
+	cs.OnExpire(func(key string, value interface{}) {
+		counter.mutex.Lock()
+		defer counter.mutex.Unlock()
+		counter.calls[key]++
+		counter.total++
+	})
+	return counter
+}
+
+// wait returns once at least n callbacks fired, and a little longer for any extra ones
+func (c *expireCounter) wait(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mutex.Lock()
+		total := c.total
+		c.mutex.Unlock()
+		if total >= n {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d expiration callbacks fired", total, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestExpireCallbackFiresOnceAcrossPaths(t *testing.T) {
+	const keys = 200
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 2 * keys, DisableCleanup: true})
+	defer cs.Close()
+	counter := newExpireCounter(cs)
+
+	ttl := time.Hour
+	for i := 0; i < keys; i++ {
+		cs.Put(fmt.Sprintf("k%d", i), i, &ttl)
+		cs.Put(fmt.Sprintf("src%d", i), i, nil)
+		expireNow(cs, fmt.Sprintf("k%d", i))
+	}
+
+	// A reader, the cleanup sweep and renames onto the expired keys all race to remove them
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < keys; i++ {
+			cs.Get(fmt.Sprintf("k%d", i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			cs.RunCleanup()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := keys - 1; i >= 0; i-- {
+			if err := cs.Rename(fmt.Sprintf("src%d", i), fmt.Sprintf("k%d", i), false); err != nil {
+				t.Errorf("rename onto expired k%d: %v", i, err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	counter.wait(t, keys)
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	for i := 0; i < keys; i++ {
+		if n := counter.calls[fmt.Sprintf("k%d", i)]; n != 1 {
+			t.Errorf("k%d: expiration callback fired %d times, want 1", i, n)
+		}
+	}
+	if got := cs.GetStats().ExpiredRemovals; got != keys {
+		t.Errorf("expired_removals = %d, want %d", got, keys)
+	}
+}
+
+func TestOpLogTellsPartialClearFromFull(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, OpLogSize: 10, DisableCleanup: true})
+	defer cs.Close()
+
+	ttl := time.Hour
+	cs.Put("ttl", 1, &ttl)
+	cs.Put("forever", 2, nil)
+	expiring := func(entry *models.CacheEntry) bool { return entry.Expiration != 0 }
+
+	cs.ClearWhere(expiring)
+	if ops := cs.OpLog(); len(ops) != 3 || ops[0].Op != OpDelete || ops[0].Key != "ttl" {
+		t.Fatalf("oplog after a partial clear %+v, want a delete of ttl on top", ops)
+	}
+
+	// A clear matching nothing changes nothing and records nothing
+	cs.ClearWhere(expiring)
+	if ops := cs.OpLog(); len(ops) != 3 {
+		t.Fatalf("oplog after an empty partial clear holds %d entries, want 3", len(ops))
+	}
+
+	cs.Clear()
+	if ops := cs.OpLog(); len(ops) != 4 || ops[0].Op != OpClear || ops[0].Key != "" {
+		t.Fatalf("oplog after a full clear %+v, want a clear on top", ops)
+	}
+}
+
+func TestRenameOverExpiredTarget(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, OpLogSize: 10, DisableCleanup: true})
+	defer cs.Close()
+	counter := newExpireCounter(cs)
+
+	ttl := time.Hour
+	cs.Put("from", "value", nil)
+	cs.Put("to", "old", &ttl)
+	expireNow(cs, "to")
+
+	if err := cs.Rename("from", "to", false); err != nil {
+		t.Fatalf("rename onto an expired key: %v", err)
+	}
+	counter.wait(t, 1)
+	counter.mutex.Lock()
+	calls := counter.calls["to"]
+	counter.mutex.Unlock()
+	if calls != 1 {
+		t.Fatalf("expired target's callback fired %d times, want 1", calls)
+	}
+	if entry, found := cs.Get("to"); !found || entry.GetValue() != "value" {
+		t.Fatalf("Get(to) = %v, %v; want the renamed value", entry, found)
+	}
+
+	if ops := cs.OpLog(); len(ops) == 0 || ops[0].Op != OpRename || ops[0].From != "from" || ops[0].Key != "to" {
+		t.Fatalf("latest oplog entry %+v, want a rename from from to to", ops)
+	}
+
+	cs.Put("other", 1, nil)
+	if err := cs.Rename("other", "to", false); !errors.Is(err, constants.ErrKeyExists) {
+		t.Fatalf("rename onto a live key = %v, want ErrKeyExists", err)
+	}
+}
+
+func TestPutOverExpiredKeyCreates(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, DisableCleanup: true})
+	defer cs.Close()
+	counter := newExpireCounter(cs)
+
+	ttl := time.Hour
+	cs.Put("k", "old", &ttl)
+	before, _ := cs.GetInfo("k")
+	expireNow(cs, "k")
+	time.Sleep(time.Millisecond)
+
+	if created, err := cs.Put("k", "new", nil); err != nil || !created {
+		t.Fatalf("Put over an expired key = %v, %v; want created", created, err)
+	}
+	counter.wait(t, 1)
+	if got := cs.GetStats().ExpiredRemovals; got != 1 {
+		t.Fatalf("expired_removals = %d, want 1", got)
+	}
+	after, found := cs.GetInfo("k")
+	if !found || !after.CreatedAt.After(before.CreatedAt) {
+		t.Fatalf("created_at %v, want a fresh one after %v", after.CreatedAt, before.CreatedAt)
+	}
+	if after.TTL != -1 {
+		t.Fatalf("ttl %d carried over from the expired key, want none", after.TTL)
+	}
+
+	// preserve_ttl has no TTL to keep from an expired key
+	cs.Put("p", "old", &ttl)
+	expireNow(cs, "p")
+	created, err := cs.PutItem(models.PutRequest{Key: "p", Value: "new", PreserveTTL: true}, nil)
+	if err != nil || !created {
+		t.Fatalf("preserve_ttl put over an expired key = %v, %v; want created", created, err)
+	}
+	if entry, found := cs.Get("p"); !found || entry.GetValue() != "new" {
+		t.Fatalf("Get(p) = %v, %v; want new", entry, found)
+	}
+}
+
+func TestPutRefreshesStaleKey(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, StaleWhileRevalidate: time.Hour, DisableCleanup: true})
+	defer cs.Close()
+	counter := newExpireCounter(cs)
+
+	ttl := time.Hour
+	cs.Put("k", "old", &ttl)
+	expireNow(cs, "k")
+
+	// Inside the grace window the key is still served, so a refresh updates it
+	if created, err := cs.Put("k", "new", &ttl); err != nil || created {
+		t.Fatalf("Put over a stale key = %v, %v; want an update", created, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	counter.mutex.Lock()
+	total := counter.total
+	counter.mutex.Unlock()
+	if total != 0 {
+		t.Fatalf("refreshing a stale key fired %d expiration callbacks", total)
+	}
+}
+
+// expireAll backdates every key with a TTL so it is expired but not yet reaped
+func expireAll(cs *CacheService) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	for _, entry := range cs.data {
+		if entry.Expiration != 0 {
+			entry.Expiration = time.Now().Add(-time.Second).Unix()
+		}
+	}
+}
+
+func TestBackpressureBoundsExpiredBacklog(t *testing.T) {
+	const threshold, round, writers = 500, 2000, 4
+	cs := NewCacheServiceWithOptions(CacheOptions{
+		MaxSize:               100000,
+		DisableCleanup:        true,
+		BackpressureThreshold: threshold,
+		BackpressureMode:      constants.BackpressureModeCleanup,
+	})
+	defer cs.Close()
+
+	// Each round of short-lived keys has expired by the next, with no cleanup worker to
+	// reap them; only the writes themselves keep the backlog down
+	ttl := time.Second
+	for r := 0; r < 10; r++ {
+		var wg sync.WaitGroup
+		for w := 0; w < writers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < round; i += writers {
+					if _, err := cs.Put(fmt.Sprintf("r%d:%d", r, i), i, &ttl); err != nil {
+						t.Errorf("put: %v", err)
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		// The backlog estimate comes from samples, so allow it some slack
+		if size := cs.Size(); size > round+3*threshold {
+			t.Fatalf("round %d: cache holds %d keys, want at most %d live plus a bounded backlog", r, size, round)
+		}
+		expireAll(cs)
+	}
+	if removed := cs.GetStats().ExpiredRemovals; removed < 9*round-3*threshold {
+		t.Fatalf("expired_removals = %d; backpressure reaped too little", removed)
+	}
+}
+
+func TestBackpressureRejects(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{
+		MaxSize:               1000,
+		DisableCleanup:        true,
+		BackpressureThreshold: 10,
+		BackpressureMode:      constants.BackpressureModeReject,
+	})
+	defer cs.Close()
+
+	ttl := time.Second
+	cs.Put("live", 0, nil)
+	for i := 0; i < 100; i++ {
+		cs.Put(fmt.Sprintf("k%d", i), i, &ttl)
+	}
+	expireAll(cs)
+	if _, err := cs.Put("new", 1, nil); !errors.Is(err, constants.ErrCacheBusy) {
+		t.Fatalf("put with an expired backlog = %v, want ErrCacheBusy", err)
+	}
+	if _, err := cs.Put("live", 1, nil); err != nil {
+		t.Fatalf("overwriting a live key in reject mode: %v", err)
+	}
+}
+
+func TestListKeysLimit(t *testing.T) {
+	cs := NewCacheService(100, 0)
+	defer cs.Close()
+	for i := 0; i < 50; i++ {
+		cs.Put(fmt.Sprintf("k%d", i), i, nil)
+	}
+
+	for limit, want := range map[int]int{0: 0, 10: 10, 50: 50, 80: 50} {
+		keys := cs.ListKeysLimit(limit)
+		seen := make(map[string]bool)
+		for _, key := range keys {
+			if _, found := cs.GetInfo(key); !found || seen[key] {
+				t.Fatalf("limit %d: unexpected or repeated key %q", limit, key)
+			}
+			seen[key] = true
+		}
+		if len(keys) != want {
+			t.Fatalf("limit %d returned %d keys, want %d", limit, len(keys), want)
+		}
+	}
+}
+
+// newListCache builds a cache of 100000 keys for the key listing benchmarks
+func newListCache() *CacheService {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 100000, DisableCleanup: true})
+	for i := 0; i < 100000; i++ {
+		cs.Put(fmt.Sprintf("key:%d", i), i, nil)
+	}
+	return cs
+}
+
+// BenchmarkListKeysThenTruncate is how GetKeys served a limit before ListKeysLimit
+func BenchmarkListKeysThenTruncate(b *testing.B) {
+	cs := newListCache()
+	defer cs.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cs.ListKeys()[:100]
+	}
+}
+
+func BenchmarkListKeysLimit(b *testing.B) {
+	cs := newListCache()
+	defer cs.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cs.ListKeysLimit(100)
+	}
+}
+
+// heapInUse returns the bytes of heap in use after a full collection
+func heapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
+
+func TestCompactShrinksMap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("inserts a million keys")
+	}
+	const keys = 1000000
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: keys, DisableCleanup: true})
+	defer cs.Close()
+	for i := 0; i < keys; i++ {
+		cs.Put(strconv.Itoa(i), i, nil)
+	}
+	for i := 0; i < keys; i++ {
+		if i%100 != 0 {
+			cs.Delete(strconv.Itoa(i))
+		}
+	}
+
+	before := heapInUse()
+	cs.Compact()
+	after := heapInUse()
+	t.Logf("heap in use %d MiB before Compact, %d MiB after", before>>20, after>>20)
+
+	if size := cs.Size(); size != keys/100 {
+		t.Fatalf("Compact left %d keys, want %d", size, keys/100)
+	}
+	if cs.peakSize != keys/100 {
+		t.Fatalf("peak size %d after Compact, want the current size %d", cs.peakSize, keys/100)
+	}
+	// The buckets of the million-key map alone take tens of MiB
+	if after+16<<20 > before {
+		t.Fatalf("Compact freed %d KiB, want the old map's buckets released", (int64(before)-int64(after))>>10)
+	}
+	if entry, found := cs.Get("500"); !found || entry.GetValue() != 500 {
+		t.Fatalf("Get(500) after Compact = %v, %v", entry, found)
+	}
+}
+
+func TestDeleteCompactsPastThreshold(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 4 * minCompactSize, CompactThreshold: 0.5, DisableCleanup: true})
+	defer cs.Close()
+	for i := 0; i < 2*minCompactSize; i++ {
+		cs.Put(strconv.Itoa(i), i, nil)
+	}
+
+	// Deleting half of the peak size triggers the rebuild, which resets the peak
+	for i := 0; i < minCompactSize; i++ {
+		cs.Delete(strconv.Itoa(i))
+	}
+	if cs.peakSize != minCompactSize {
+		t.Fatalf("peak size %d after deleting half the keys, want a compaction to %d", cs.peakSize, minCompactSize)
+	}
+}
+
+func TestGetStatsDuringWrites(t *testing.T) {
+	const workers, ops = 8, 2000
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 500, DisableCleanup: true})
+	defer cs.Close()
+
+	var wg sync.WaitGroup
+	var found int64
+	var foundMutex sync.Mutex
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			hits := int64(0)
+			for i := 0; i < ops; i++ {
+				key := fmt.Sprintf("k%d", (w*ops+i)%1000)
+				cs.Put(key, i, nil)
+				if _, ok := cs.Get(key); ok {
+					hits++
+				}
+			}
+			foundMutex.Lock()
+			found += hits
+			foundMutex.Unlock()
+		}(w)
+	}
+
+	// Counters read without the lock must still never go backwards
+	stop := make(chan struct{})
+	statsDone := make(chan struct{})
+	go func() {
+		defer close(statsDone)
+		var last models.CacheStats
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			stats := cs.GetStats()
+			if stats.Hits < last.Hits || stats.Misses < last.Misses || stats.Evictions < last.Evictions {
+				t.Errorf("stats went backwards: %+v after %+v", stats, last)
+				return
+			}
+			if stats.CurrentSize > stats.MaxSize {
+				t.Errorf("current size %d above max %d", stats.CurrentSize, stats.MaxSize)
+				return
+			}
+			last = stats
+		}
+	}()
+	wg.Wait()
+	close(stop)
+	<-statsDone
+
+	stats := cs.GetStats()
+	if stats.Hits != found || stats.TotalRequests != workers*ops {
+		t.Fatalf("hits %d of %d requests, want %d of %d", stats.Hits, stats.TotalRequests, found, workers*ops)
+	}
+	if stats.Evictions == 0 {
+		t.Fatal("no evictions counted although 1000 keys went through a cache of 500")
+	}
+}
+
+func TestWatermarksEvictInBatches(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, HighWatermark: 8, LowWatermark: 4, DisableCleanup: true})
+	defer cs.Close()
+
+	for i := 0; i < 8; i++ {
+		if evicted := putEvicting(t, cs, fmt.Sprintf("k%d", i)); len(evicted) != 0 {
+			t.Fatalf("put k%d below the high watermark evicted %v", i, evicted)
+		}
+	}
+
+	// Reaching the high watermark evicts the oldest keys until the low watermark remain
+	evicted := putEvicting(t, cs, "k8")
+	if want := []string{"k0", "k1", "k2", "k3", "k4"}; fmt.Sprint(evicted) != fmt.Sprint(want) {
+		t.Fatalf("put at the high watermark evicted %v, want %v", evicted, want)
+	}
+	if size := cs.GetStats().CurrentSize; size != 4 {
+		t.Fatalf("size = %d after a batch eviction, want the low watermark 4", size)
+	}
+
+	// Then there is room again up to the high watermark
+	for i := 9; i < 13; i++ {
+		if evicted := putEvicting(t, cs, fmt.Sprintf("k%d", i)); len(evicted) != 0 {
+			t.Fatalf("put k%d after a batch eviction evicted %v", i, evicted)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"encoding/json"
+	"hash/crc32"
+	"sync/atomic"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// CorruptionCallback is invoked with the key of an entry whose value no longer matched its
+// checksum when read; the entry has already been removed
+type CorruptionCallback func(key string)
+
+// checksumState verifies stored values against a CRC32 taken when they were written, to
+// catch values changed in memory behind the cache's back. Callbacks are guarded by the
+// cache mutex; detected is updated with sync/atomic.
+type checksumState struct {
+	enabled   bool
+	detected  int64
+	callbacks []CorruptionCallback
+}
+
+// OnCorruption registers a callback fired on its own goroutine for every corrupted value a
+// read detects. It only fires when ChecksumValues is set.
+func (cs *CacheService) OnCorruption(fn CorruptionCallback) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	cs.checksums.callbacks = append(cs.checksums.callbacks, fn)
+}
+
+// valueChecksum returns the CRC32 of a stored value's JSON form, as encrypted when a cipher
+// is set, or 0 when checksums are off. Called outside the lock, like valueSize.
+func (cs *CacheService) valueChecksum(value interface{}) uint32 {
+	if !cs.checksums.enabled {
+		return 0
+	}
+	if raw, ok := value.(json.RawMessage); ok {
+		return crc32.ChecksumIEEE(raw)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return crc32.ChecksumIEEE(encoded)
+}
+
+// intact reports whether entry's value still matches its checksum; always true when
+// checksums are off. Callers must hold at least the read lock.
+func (cs *CacheService) intact(entry *models.CacheEntry) bool {
+	return !cs.checksums.enabled || cs.valueChecksum(entry.GetValue()) == entry.Checksum
+}
+
+// dropCorrupted removes an entry that failed intact, counts it and fires the corruption
+// callbacks. Callers must hold the write lock.
+func (cs *CacheService) dropCorrupted(key string, entry *models.CacheEntry) {
+	if !cs.removeEntry(entry) {
+		return
+	}
+	atomic.AddInt64(&cs.checksums.detected, 1)
+
+	callbacks := cs.checksums.callbacks
+	go func() {
+		for _, fn := range callbacks {
+			fn(key)
+		}
+	}()
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// corrupt changes key's stored value in place, behind the cache's back
+func corrupt(cs *CacheService, key string, change func(value interface{})) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	change(cs.data[key].GetValue())
+}
+
+func TestChecksumDetectsCorruption(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, ChecksumValues: true})
+	defer cs.Close()
+	corrupted := make(chan string, 1)
+	cs.OnCorruption(func(key string) { corrupted <- key })
+
+	cs.Put("intact", map[string]interface{}{"n": 1.0}, nil)
+	cs.Put("k", map[string]interface{}{"n": 1.0}, nil)
+	cs.Put("raw", json.RawMessage(`{"n":1}`), nil)
+	corrupt(cs, "k", func(value interface{}) { value.(map[string]interface{})["n"] = 2.0 })
+
+	if _, found := cs.Get("intact"); !found {
+		t.Fatal("intact value rejected")
+	}
+	if _, found := cs.Get("k"); found {
+		t.Fatal("corrupted value served")
+	}
+	select {
+	case key := <-corrupted:
+		if key != "k" {
+			t.Fatalf("callback fired for %q, want k", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("corruption callback did not fire")
+	}
+	if _, found := cs.GetInfo("k"); found {
+		t.Fatal("corrupted entry kept")
+	}
+
+	corrupt(cs, "raw", func(value interface{}) { value.(json.RawMessage)[5] = '9' })
+	if _, found := cs.Get("raw"); found {
+		t.Fatal("corrupted raw value served")
+	}
+	if got := cs.GetStats().CorruptionsDetected; got != 2 {
+		t.Fatalf("corruptions_detected = %d, want 2", got)
+	}
+
+	// A default is stored over a corrupted value rather than the corruption returned
+	cs.Put("d", map[string]interface{}{"n": 1.0}, nil)
+	corrupt(cs, "d", func(value interface{}) { value.(map[string]interface{})["n"] = 2.0 })
+	entry, created, err := cs.GetOrPut("d", "fresh", nil)
+	if err != nil || !created || entry.GetValue() != "fresh" {
+		t.Fatalf("GetOrPut over a corrupted value = %v, %v, %v; want the default stored", entry, created, err)
+	}
+}
+
+func TestChecksumDetectsCorruptedCiphertext(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, ChecksumValues: true, EncryptionKey: []byte("0123456789abcdef")})
+	defer cs.Close()
+
+	cs.Put("k", "secret", nil)
+	corrupt(cs, "k", func(value interface{}) { value.([]byte)[0] ^= 0xff })
+	if _, found := cs.Get("k"); found {
+		t.Fatal("corrupted ciphertext served")
+	}
+	if got := cs.GetStats().CorruptionsDetected; got != 1 {
+		t.Fatalf("corruptions_detected = %d, want 1", got)
+	}
+}
+
+func TestChecksumsOff(t *testing.T) {
+	cs := NewCacheService(10, 0)
+	defer cs.Close()
+
+	cs.Put("k", map[string]interface{}{"n": 1.0}, nil)
+	corrupt(cs, "k", func(value interface{}) { value.(map[string]interface{})["n"] = 2.0 })
+	if entry, found := cs.Get("k"); !found || entry.GetValue().(map[string]interface{})["n"] != 2.0 {
+		t.Fatalf("Get without checksums = %v, %v; want the changed value served", entry, found)
+	}
+}
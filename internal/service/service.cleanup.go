@@ -0,0 +1,175 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// cleanupInterval is how often the background worker reaps expired entries
+const cleanupInterval = 30 * time.Second
+
+// Background worker states reported by CleanupHealth
+const (
+	WorkerRunning  = "running"
+	WorkerPaused   = "paused"
+	WorkerStalled  = "stalled"
+	WorkerStopped  = "stopped"
+	WorkerDisabled = "disabled"
+)
+
+// cleanupState tracks background cleanup for inspection and runtime control. mutex is
+// held for the whole of every sweep, so once PauseCleanup returns no sweep is running
+// and the worker starts none until ResumeCleanup.
+type cleanupState struct {
+	mutex       sync.Mutex
+	disabled    bool // no worker was started; set once at construction
+	paused      bool
+	runs        int64
+	lastRunAt   time.Time
+	lastReaped  int
+	totalReaped int64
+
+	// Written by the worker without mutex, so health can be read while a sweep is stuck
+	heartbeat cleanupHeartbeat
+}
+
+// cleanupHeartbeat records the worker's liveness; times are Unix nanoseconds
+type cleanupHeartbeat struct {
+	alive     atomic.Bool
+	startedAt atomic.Int64
+	lastTick  atomic.Int64 // 0 before the first tick
+	ticks     atomic.Int64
+}
+
+// CleanupHealth reports whether the cleanup worker is alive and still ticking. The worker
+// is stalled when it has not received a tick for twice the interval (allowing for the start
+// jitter before the first one), e.g. because a sweep is stuck. Unlike CleanupStatus it
+// never waits for a running sweep.
+func (cs *CacheService) CleanupHealth() models.WorkerHealth {
+	heartbeat := &cs.cleanup.heartbeat
+	health := models.WorkerHealth{
+		Name:     "cleanup",
+		Alive:    heartbeat.alive.Load(),
+		Interval: cs.cleanupEvery.String(),
+		Ticks:    heartbeat.ticks.Load(),
+	}
+	if cs.cleanup.disabled {
+		health.Status = WorkerDisabled
+		return health
+	}
+
+	// Until the first tick, measure from the worker's start
+	last, allowance := heartbeat.startedAt.Load(), 2*cs.cleanupEvery+cs.cleanupJitter
+	if last != 0 {
+		startedAt := time.Unix(0, last)
+		health.StartedAt = &startedAt
+	}
+	if tick := heartbeat.lastTick.Load(); tick != 0 {
+		lastTickAt := time.Unix(0, tick)
+		health.LastTickAt = &lastTickAt
+		last, allowance = tick, 2*cs.cleanupEvery
+	}
+
+	paused := false
+	if cs.cleanup.mutex.TryLock() {
+		paused = cs.cleanup.paused
+		cs.cleanup.mutex.Unlock()
+	} else {
+		health.Sweeping = true
+	}
+
+	switch {
+	case !health.Alive:
+		health.Status = WorkerStopped
+	case last != 0 && time.Since(time.Unix(0, last)) > allowance:
+		health.Status = WorkerStalled
+	case paused:
+		health.Status = WorkerPaused
+	default:
+		health.Status = WorkerRunning
+	}
+	return health
+}
+
+// CleanupStatus reports whether background cleanup is paused and what its sweeps reaped
+func (cs *CacheService) CleanupStatus() models.CleanupStatus {
+	cs.cleanup.mutex.Lock()
+	defer cs.cleanup.mutex.Unlock()
+
+	return cs.cleanupStatusLocked()
+}
+
+// RunCleanup sweeps expired entries now, even while background cleanup is paused,
+// and returns the resulting status. Nothing is reaped while the cache is frozen.
+func (cs *CacheService) RunCleanup() models.CleanupStatus {
+	cs.cleanup.mutex.Lock()
+	defer cs.cleanup.mutex.Unlock()
+
+	cs.sweepLocked()
+	return cs.cleanupStatusLocked()
+}
+
+// PauseCleanup stops the background worker from sweeping until ResumeCleanup. It waits
+// for a sweep in progress to finish and reports whether cleanup was running before.
+func (cs *CacheService) PauseCleanup() bool {
+	cs.cleanup.mutex.Lock()
+	defer cs.cleanup.mutex.Unlock()
+
+	wasRunning := !cs.cleanup.paused
+	cs.cleanup.paused = true
+	return wasRunning
+}
+
+// ResumeCleanup lets the background worker sweep again from its next tick and
+// reports whether cleanup was paused before
+func (cs *CacheService) ResumeCleanup() bool {
+	cs.cleanup.mutex.Lock()
+	defer cs.cleanup.mutex.Unlock()
+
+	wasPaused := cs.cleanup.paused
+	cs.cleanup.paused = false
+	return wasPaused
+}
+
+// backgroundSweep is the worker's sweep, skipped while cleanup is paused
+func (cs *CacheService) backgroundSweep() {
+	cs.cleanup.mutex.Lock()
+	defer cs.cleanup.mutex.Unlock()
+
+	if !cs.cleanup.paused {
+		cs.sweepLocked()
+	}
+}
+
+// sweepLocked reaps expired entries and records the run. Must be called with cleanup.mutex held.
+func (cs *CacheService) sweepLocked() {
+	reaped, ran := cs.cleanupExpired()
+	if !ran {
+		return
+	}
+
+	cs.cleanup.runs++
+	cs.cleanup.lastRunAt = time.Now()
+	cs.cleanup.lastReaped = reaped
+	cs.cleanup.totalReaped += int64(reaped)
+}
+
+// cleanupStatusLocked builds the status. Must be called with cleanup.mutex held.
+func (cs *CacheService) cleanupStatusLocked() models.CleanupStatus {
+	status := models.CleanupStatus{
+		Disabled:    cs.cleanup.disabled,
+		Paused:      cs.cleanup.paused,
+		Interval:    cs.cleanupEvery.String(),
+		Runs:        cs.cleanup.runs,
+		LastReaped:  cs.cleanup.lastReaped,
+		TotalReaped: cs.cleanup.totalReaped,
+	}
+	if !cs.cleanup.lastRunAt.IsZero() {
+		lastRunAt := cs.cleanup.lastRunAt
+		status.LastRunAt = &lastRunAt
+	}
+	return status
+}
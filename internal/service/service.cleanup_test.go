@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// startCleanup starts the cleanup worker of a cache built with DisableCleanup, sweeping
+// every interval and delaying its first sweep by jitter's pick
+func startCleanup(cs *CacheService, every time.Duration, jitter func(max time.Duration) time.Duration) {
+	cs.cleanupEvery = every
+	if jitter != nil {
+		cs.jitter = jitter
+	}
+	cs.cleanup.disabled = false
+	go cs.cleanupWorker()
+}
+
+// waitTicks waits until the cleanup worker has ticked n times and returns the time of the
+// latest tick
+func waitTicks(t *testing.T, cs *CacheService, n int64) time.Time {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for cs.cleanup.heartbeat.ticks.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("cleanup worker ticked %d times in 5s, want %d", cs.cleanup.heartbeat.ticks.Load(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return time.Unix(0, cs.cleanup.heartbeat.lastTick.Load())
+}
+
+func TestCleanupJitterDelaysFirstRun(t *testing.T) {
+	const every, offset, maxJitter = 20 * time.Millisecond, 100 * time.Millisecond, time.Second
+
+	// The default pick stays within the configured bound
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, DisableCleanup: true, CleanupJitter: maxJitter})
+	for i := 0; i < 1000; i++ {
+		if d := cs.jitter(maxJitter); d < 0 || d >= maxJitter {
+			t.Fatalf("jitter picked %v, want a duration in [0, %v)", d, maxJitter)
+		}
+	}
+
+	asked := make(chan time.Duration, 1)
+	startCleanup(cs, every, func(max time.Duration) time.Duration {
+		asked <- max
+		return offset
+	})
+	defer cs.Close()
+	if max := <-asked; max != maxJitter {
+		t.Fatalf("jitter asked for a delay up to %v, want %v", max, maxJitter)
+	}
+
+	// The first run comes one interval after the offset
+	startedAt := time.Unix(0, cs.cleanup.heartbeat.startedAt.Load())
+	if first := waitTicks(t, cs, 1).Sub(startedAt); first < offset+every || first > offset+every+offset {
+		t.Fatalf("first run %v after start, want about %v", first, offset+every)
+	}
+}
+
+func TestDisableCleanupStartsNoWorker(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, DisableCleanup: true})
+	time.Sleep(10 * time.Millisecond)
+
+	if cs.cleanup.heartbeat.startedAt.Load() != 0 || cs.cleanup.heartbeat.alive.Load() {
+		t.Fatal("cleanup worker started although cleanup is disabled")
+	}
+	if health := cs.CleanupHealth(); health.Status != WorkerDisabled {
+		t.Fatalf("worker status %q, want %q", health.Status, WorkerDisabled)
+	}
+	if status := cs.CleanupStatus(); !status.Disabled {
+		t.Fatal("cleanup status does not report cleanup disabled")
+	}
+
+	// Nothing receives from stopCleanup or sends on cleanupDone, so Close must not wait on them
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		cs.Close()
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked without a cleanup worker")
+	}
+}
+
+func TestCleanupHealthReportsStalledWorker(t *testing.T) {
+	const every = 10 * time.Millisecond
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, DisableCleanup: true})
+	defer cs.Close()
+
+	// Holding the cleanup lock stalls the worker in the sweep of its first tick
+	cs.cleanup.mutex.Lock()
+	startCleanup(cs, every, nil)
+	waitTicks(t, cs, 1)
+	time.Sleep(3 * every)
+
+	health := cs.CleanupHealth()
+	if health.Status != WorkerStalled || !health.Alive || !health.Sweeping || health.Ticks != 1 || health.LastTickAt == nil {
+		t.Fatalf("health of a stuck worker %+v, want alive, sweeping and stalled after 1 tick", health)
+	}
+
+	cs.cleanup.mutex.Unlock()
+	waitTicks(t, cs, 2)
+	if health := cs.CleanupHealth(); health.Status != WorkerRunning {
+		t.Fatalf("status %q once the sweep finished, want %q", health.Status, WorkerRunning)
+	}
+}
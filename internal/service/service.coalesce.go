@@ -0,0 +1,146 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+)
+
+// CoalescingCacheService debounces rapid Puts to the same key in front of a CacheService.
+// The first Put to a key starts a timer of one window; later Puts within that window
+// only replace the pending value, and when the timer fires the latest value is applied.
+// Reads go to the underlying cache and see the latest value once its window has passed.
+// Pending writes are applied under the wrapper's lock, so they reach the cache in the
+// order they were made. BulkPut is inherited unchanged and writes through immediately.
+type CoalescingCacheService struct {
+	*CacheService
+
+	window  time.Duration
+	mutex   sync.Mutex
+	pending map[string]*pendingWrite
+
+	applied   int64 // pending writes flushed into the cache
+	coalesced int64 // Puts superseded by a later Put before being applied
+}
+
+// pendingWrite is the latest not-yet-applied Put for a key
+type pendingWrite struct {
+	value interface{}
+	ttl   *time.Duration
+	timer *time.Timer // applies the write when the window ends; stopped if it is dropped
+}
+
+// NewCoalescingCacheService wraps main so Puts to the same key within window are coalesced
+func NewCoalescingCacheService(main *CacheService, window time.Duration) *CoalescingCacheService {
+	return &CoalescingCacheService{
+		CacheService: main,
+		window:       window,
+		pending:      make(map[string]*pendingWrite),
+	}
+}
+
+// Put records the write and applies it once the key's window elapses. created reports
+// whether the write will create the key: it had no live value and no write pending when
+// the window started. Later Puts in the window only replace the value and report false.
+// Validation and read-only/draining errors are still reported immediately.
+func (cc *CoalescingCacheService) Put(key string, value interface{}, ttl *time.Duration) (bool, error) {
+	if key == "" {
+		return false, constants.ErrKeyEmpty
+	}
+	if err := cc.writeGuard(); err != nil {
+		return false, err
+	}
+
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+
+	if write, exists := cc.pending[key]; exists {
+		write.value, write.ttl = value, ttl
+		atomic.AddInt64(&cc.coalesced, 1)
+		return false, nil
+	}
+
+	_, live := cc.GetInfo(key)
+	write := &pendingWrite{value: value, ttl: ttl}
+	// The timer waits for the lock held here, so it always sees write.timer set
+	write.timer = time.AfterFunc(cc.window, func() { cc.flushWrite(key, write) })
+	cc.pending[key] = write
+
+	return !live, nil
+}
+
+// Delete drops any pending write for the key, stopping its timer, so it can neither
+// resurrect the entry nor cut short the window of a later Put
+func (cc *CoalescingCacheService) Delete(key string) (bool, bool) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+
+	if write, exists := cc.pending[key]; exists {
+		write.timer.Stop()
+		delete(cc.pending, key)
+	}
+	return cc.CacheService.Delete(key)
+}
+
+// Clear drops all pending writes and clears the underlying cache
+func (cc *CoalescingCacheService) Clear() int {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+
+	for _, write := range cc.pending {
+		write.timer.Stop()
+	}
+	cc.pending = make(map[string]*pendingWrite)
+	return cc.CacheService.Clear()
+}
+
+// Flush applies every pending write now instead of waiting for its window
+func (cc *CoalescingCacheService) Flush() {
+	cc.mutex.Lock()
+	keys := make([]string, 0, len(cc.pending))
+	for key := range cc.pending {
+		keys = append(keys, key)
+	}
+	cc.mutex.Unlock()
+
+	for _, key := range keys {
+		cc.mutex.Lock()
+		if write, exists := cc.pending[key]; exists {
+			write.timer.Stop()
+			cc.apply(key, write)
+		}
+		cc.mutex.Unlock()
+	}
+}
+
+// Close applies pending writes before stopping the underlying cache
+func (cc *CoalescingCacheService) Close() {
+	cc.Flush()
+	cc.CacheService.Close()
+}
+
+// CoalesceStats reports how many writes were applied and how many were superseded
+func (cc *CoalescingCacheService) CoalesceStats() (applied, coalesced int64) {
+	return atomic.LoadInt64(&cc.applied), atomic.LoadInt64(&cc.coalesced)
+}
+
+// flushWrite applies write when its window ends, unless it was flushed or dropped already
+func (cc *CoalescingCacheService) flushWrite(key string, write *pendingWrite) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+
+	if cc.pending[key] == write {
+		cc.apply(key, write)
+	}
+}
+
+// apply stores a pending write in the cache and forgets it. Callers must hold cc.mutex,
+// which keeps a flush from overtaking an earlier one for the same key.
+func (cc *CoalescingCacheService) apply(key string, write *pendingWrite) {
+	delete(cc.pending, key)
+	if _, err := cc.CacheService.Put(key, write.value, write.ttl); err == nil {
+		atomic.AddInt64(&cc.applied, 1)
+	}
+}
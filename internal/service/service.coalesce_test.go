@@ -0,0 +1,108 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalescingAppliesLatestOnce(t *testing.T) {
+	cc := NewCoalescingCacheService(NewCacheService(10, 0), 50*time.Millisecond)
+	defer cc.Close()
+
+	for i := 0; i < 1000; i++ {
+		created, err := cc.Put("k", i, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created != (i == 0) {
+			t.Fatalf("Put %d reported created=%v", i, created)
+		}
+	}
+	if _, found := cc.Get("k"); found {
+		t.Fatal("value applied before its window ended")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if entry, found := cc.Get("k"); !found || entry.GetValue() != 999 {
+		t.Fatalf("Get after the window = %v, %v; want 999", entry, found)
+	}
+	if applied, coalesced := cc.CoalesceStats(); applied != 1 || coalesced != 999 {
+		t.Fatalf("applied %d, coalesced %d; want 1 and 999", applied, coalesced)
+	}
+
+	// The key now exists, so the next window updates it
+	if created, _ := cc.Put("k", "again", nil); created {
+		t.Fatal("Put over a live key reported created")
+	}
+}
+
+func TestCoalescingDeleteStopsTimer(t *testing.T) {
+	window := 200 * time.Millisecond
+	cc := NewCoalescingCacheService(NewCacheService(10, 0), window)
+	defer cc.Close()
+
+	start := time.Now()
+	cc.Put("k", "old", nil)
+	time.Sleep(window / 2)
+	cc.Delete("k")
+	cc.Put("k", "new", nil)
+
+	// Past the first window but inside the second: the dropped write's timer must not
+	// flush the new one early
+	time.Sleep(time.Until(start.Add(window * 5 / 4)))
+	if entry, found := cc.Get("k"); found {
+		t.Fatalf("Get = %v before the re-Put's window ended", entry.GetValue())
+	}
+
+	time.Sleep(time.Until(start.Add(window * 2)))
+	if entry, found := cc.Get("k"); !found || entry.GetValue() != "new" {
+		t.Fatalf("Get after the re-Put's window = %v, %v; want new", entry, found)
+	}
+	if applied, _ := cc.CoalesceStats(); applied != 1 {
+		t.Fatalf("applied %d writes, want 1", applied)
+	}
+}
+
+func TestCoalescingFlushesInOrder(t *testing.T) {
+	cc := NewCoalescingCacheService(NewCacheService(10, 0), time.Microsecond)
+	defer cc.Close()
+
+	// Windows end constantly while Flush runs alongside; no flush may overtake a later one
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				cc.Flush()
+			}
+		}
+	}()
+	for i := 0; i < 5000; i++ {
+		cc.Put("k", i, nil)
+	}
+	close(stop)
+	wg.Wait()
+	cc.Flush()
+
+	if entry, found := cc.Get("k"); !found || entry.GetValue() != 4999 {
+		t.Fatalf("Get = %v, %v; want the last value 4999", entry, found)
+	}
+}
+
+func TestCoalescingClearDropsPending(t *testing.T) {
+	cc := NewCoalescingCacheService(NewCacheService(10, 0), 20*time.Millisecond)
+	defer cc.Close()
+
+	cc.Put("k", 1, nil)
+	cc.Clear()
+	time.Sleep(60 * time.Millisecond)
+	if _, found := cc.Get("k"); found {
+		t.Fatal("a write pending at Clear was applied")
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// Compare reads the live values of keys a and b in one consistent view and reports whether
+// they are deeply equal (reflect.DeepEqual, as in DeleteIf, with raw JSON values decoded
+// first). Like GetInfo it neither promotes keys nor counts hits. A missing or expired key returns ErrKeyNotFound naming it.
+func (cs *CacheService) Compare(a, b string) (models.CompareResponse, error) {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	first, err := cs.peekResponse(a)
+	if err != nil {
+		return models.CompareResponse{}, err
+	}
+	second, err := cs.peekResponse(b)
+	if err != nil {
+		return models.CompareResponse{}, err
+	}
+
+	return models.CompareResponse{
+		Equal: reflect.DeepEqual(decodeRaw(first.Value), decodeRaw(second.Value)),
+		A:     first,
+		B:     second,
+	}, nil
+}
+
+// peekResponse returns the live entry for key as a response with its plain value, leaving
+// recency and hit counts alone. Callers must hold at least the read lock.
+func (cs *CacheService) peekResponse(key string) (models.GetResponse, error) {
+	entry, exists := cs.data[cs.internalKey(key)]
+	if !exists || entry.IsExpired() {
+		return models.GetResponse{}, fmt.Errorf("%w: %s", constants.ErrKeyNotFound, key)
+	}
+	if cs.cipher != nil {
+		plain, err := cs.decryptedCopy(entry)
+		if err != nil {
+			return models.GetResponse{}, err
+		}
+		entry = plain
+	}
+
+	response := entry.ToResponse()
+	response.Key = key
+	return response, nil
+}
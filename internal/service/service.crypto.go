@@ -0,0 +1,61 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+)
+
+// valueCipher encrypts cached values at rest with AES-GCM
+type valueCipher struct {
+	aead cipher.AEAD
+}
+
+// newValueCipher creates a cipher from a 16, 24 or 32 byte key (AES-128/192/256)
+func newValueCipher(key []byte) (*valueCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &valueCipher{aead: aead}, nil
+}
+
+// seal serializes the value to JSON and encrypts it with a fresh nonce
+func (vc *valueCipher) seal(value interface{}) ([]byte, []byte, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w for encryption: %v", constants.ErrUnserializable, err)
+	}
+
+	nonce := make([]byte, vc.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+
+	return vc.aead.Seal(nil, nonce, plaintext, nil), nonce, nil
+}
+
+// open decrypts and deserializes a value produced by seal
+func (vc *valueCipher) open(ciphertext, nonce []byte) (interface{}, error) {
+	plaintext, err := vc.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(plaintext, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptedValueRoundTrip(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, EncryptionKey: []byte("0123456789abcdef")})
+	defer cs.Close()
+
+	cs.Put("k", "secret value", nil)
+	cs.mutex.RLock()
+	stored := cs.data["k"].GetValue()
+	cs.mutex.RUnlock()
+	sealed, ok := stored.([]byte)
+	if !ok || bytes.Contains(sealed, []byte("secret value")) {
+		t.Fatalf("stored value %v, want ciphertext without the plaintext", stored)
+	}
+
+	if entry, found := cs.Get("k"); !found || entry.GetValue() != "secret value" {
+		t.Fatalf("Get = %v, %v; want the decrypted value", entry, found)
+	}
+}
+
+func TestValueCipherRejectsWrongKeyAndNonce(t *testing.T) {
+	vc, err := newValueCipher([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, nonce, err := vc.seal("secret value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := vc.open(ciphertext, nonce); err != nil || value != "secret value" {
+		t.Fatalf("open = %v, %v; want the sealed value", value, err)
+	}
+
+	other, err := newValueCipher([]byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := other.open(ciphertext, nonce); err == nil {
+		t.Fatalf("open with the wrong key = %v, want an error", value)
+	}
+
+	wrongNonce := append([]byte(nil), nonce...)
+	wrongNonce[0] ^= 0xff
+	if value, err := vc.open(ciphertext, wrongNonce); err == nil {
+		t.Fatalf("open with the wrong nonce = %v, want an error", value)
+	}
+}
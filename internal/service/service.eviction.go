@@ -0,0 +1,230 @@
+package service
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// defaultLRUK is the K of LRU-K eviction when none is configured
+const defaultLRUK = 2
+
+// evictionPolicy chooses which entry a full cache evicts. The service keeps entries on its
+// linked list, new ones at the head; a policy may record more about each access. Callers
+// must hold the write lock for every method but victim, which needs at least the read lock.
+type evictionPolicy interface {
+	// accessed is called whenever a read or write references entry. ReplaceAll also calls
+	// it, without the lock, on entries it has not yet added, which it must only update.
+	accessed(entry *models.CacheEntry)
+	// added is called once entry has joined the cache, after its first accessed
+	added(entry *models.CacheEntry)
+	// removed is called once entry has left the cache
+	removed(entry *models.CacheEntry)
+	// reset is called when Clear or ReplaceAll replaces the cache's contents with entries
+	reset(entries map[string]*models.CacheEntry)
+	// reorders reports whether an access moves the entry to the head of the list,
+	// keeping the list in recency order
+	reorders() bool
+	// victim returns the entry to evict next, or nil when the cache is empty
+	victim() *models.CacheEntry
+	// take returns the entry to evict now, like victim, but may update the policy's state
+	take() *models.CacheEntry
+}
+
+// newEvictionPolicy builds the policy named by opts for cs; an empty name means LRU
+func newEvictionPolicy(cs *CacheService, opts CacheOptions) evictionPolicy {
+	switch opts.EvictionPolicy {
+	case "", constants.EvictionPolicyLRU:
+		return lruPolicy{cs: cs}
+	case constants.EvictionPolicyLRUK:
+		k := opts.LRUK
+		if k <= 0 {
+			k = defaultLRUK
+		}
+		return lruKPolicy{cs: cs, k: k, order: &lruKHeap{k: k}}
+	case constants.EvictionPolicyClock:
+		return clockPolicy{cs: cs}
+	default:
+		panic(fmt.Sprintf("unknown cache eviction policy %q", opts.EvictionPolicy))
+	}
+}
+
+// lruPolicy evicts the least recently used entry, the tail of the recency list
+type lruPolicy struct {
+	cs *CacheService
+}
+
+func (p lruPolicy) accessed(entry *models.CacheEntry) {}
+
+func (p lruPolicy) added(entry *models.CacheEntry) {}
+
+func (p lruPolicy) removed(entry *models.CacheEntry) {}
+
+func (p lruPolicy) reset(entries map[string]*models.CacheEntry) {}
+
+func (p lruPolicy) reorders() bool { return true }
+
+func (p lruPolicy) victim() *models.CacheEntry {
+	if p.cs.tail.Prev == p.cs.head {
+		return nil
+	}
+	return p.cs.tail.Prev
+}
+
+func (p lruPolicy) take() *models.CacheEntry { return p.victim() }
+
+// lruKPolicy evicts the entry whose K-th most recent access is oldest, so a key touched
+// once by a scan goes before a key read K times, however recently the scan ran. Entries
+// with fewer than K accesses count as infinitely old and go first, least recent first.
+// Entries are kept in a heap in that order, so an access or eviction costs O(log n).
+type lruKPolicy struct {
+	cs    *CacheService
+	k     int
+	order *lruKHeap
+}
+
+// accessed records the access time, keeping only the last K, oldest first, and moves the
+// entry to its new place in the eviction order
+func (p lruKPolicy) accessed(entry *models.CacheEntry) {
+	now := time.Now().UnixNano()
+	if len(entry.Accesses) < p.k {
+		entry.Accesses = append(entry.Accesses, now)
+	} else {
+		copy(entry.Accesses, entry.Accesses[1:])
+		entry.Accesses[p.k-1] = now
+	}
+	if entry.HeapIndex > 0 {
+		heap.Fix(p.order, entry.HeapIndex-1)
+	}
+}
+
+func (p lruKPolicy) added(entry *models.CacheEntry) { heap.Push(p.order, entry) }
+
+func (p lruKPolicy) removed(entry *models.CacheEntry) {
+	if entry.HeapIndex > 0 {
+		heap.Remove(p.order, entry.HeapIndex-1)
+	}
+}
+
+// reset orders entries from scratch, in time linear in their number
+func (p lruKPolicy) reset(entries map[string]*models.CacheEntry) {
+	p.order.entries = make([]*models.CacheEntry, 0, len(entries))
+	for _, entry := range entries {
+		entry.HeapIndex = len(p.order.entries) + 1
+		p.order.entries = append(p.order.entries, entry)
+	}
+	heap.Init(p.order)
+}
+
+func (p lruKPolicy) reorders() bool { return true }
+
+func (p lruKPolicy) victim() *models.CacheEntry {
+	if len(p.order.entries) == 0 {
+		return nil
+	}
+	return p.order.entries[0]
+}
+
+func (p lruKPolicy) take() *models.CacheEntry { return p.victim() }
+
+// lruKHeap orders entries for LRU-K eviction, next victim first, through container/heap.
+// Each entry's HeapIndex tracks its position so it can be fixed or removed in place.
+type lruKHeap struct {
+	k       int
+	entries []*models.CacheEntry
+}
+
+func (h *lruKHeap) Len() int { return len(h.entries) }
+
+// Less puts entries with fewer than K accesses first, by their last access, then the rest
+// by their K-th most recent access
+func (h *lruKHeap) Less(i, j int) bool {
+	a, b := h.entries[i], h.entries[j]
+	aFull, bFull := len(a.Accesses) >= h.k, len(b.Accesses) >= h.k
+	switch {
+	case aFull != bFull:
+		return !aFull
+	case !aFull:
+		return a.Accesses[len(a.Accesses)-1] < b.Accesses[len(b.Accesses)-1]
+	default:
+		return a.Accesses[0] < b.Accesses[0]
+	}
+}
+
+func (h *lruKHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].HeapIndex = i + 1
+	h.entries[j].HeapIndex = j + 1
+}
+
+func (h *lruKHeap) Push(x interface{}) {
+	entry := x.(*models.CacheEntry)
+	entry.HeapIndex = len(h.entries) + 1
+	h.entries = append(h.entries, entry)
+}
+
+func (h *lruKHeap) Pop() interface{} {
+	last := len(h.entries) - 1
+	entry := h.entries[last]
+	h.entries[last] = nil
+	h.entries = h.entries[:last]
+	entry.HeapIndex = 0
+	return entry
+}
+
+// clockPolicy approximates LRU with the second-chance (clock) algorithm. An access only sets
+// the entry's reference bit instead of relinking it, so the list stays in insertion order and
+// reads cost less under the write lock. Eviction looks at the oldest entry: one with its bit
+// set has the bit cleared and goes back to the head, the first one without is evicted.
+type clockPolicy struct {
+	cs *CacheService
+}
+
+func (p clockPolicy) accessed(entry *models.CacheEntry) { entry.Referenced = true }
+
+// added clears the bit insertion's access set: a new entry earns its second chance by being
+// read, or a read would protect it no more than a write of a key never read again
+func (p clockPolicy) added(entry *models.CacheEntry) { entry.Referenced = false }
+
+func (p clockPolicy) removed(entry *models.CacheEntry) {}
+
+// reset clears the bits of entries swapped in by ReplaceAll, which start out as added ones do
+func (p clockPolicy) reset(entries map[string]*models.CacheEntry) {
+	for _, entry := range entries {
+		entry.Referenced = false
+	}
+}
+
+func (p clockPolicy) reorders() bool { return false }
+
+// victim returns the entry take would evict without giving any entry its second chance:
+// the oldest unreferenced entry or, when every entry is referenced, the oldest one
+func (p clockPolicy) victim() *models.CacheEntry {
+	if p.cs.tail.Prev == p.cs.head {
+		return nil
+	}
+	for entry := p.cs.tail.Prev; entry != p.cs.head; entry = entry.Prev {
+		if !entry.Referenced {
+			return entry
+		}
+	}
+	return p.cs.tail.Prev
+}
+
+// take gives referenced entries at the old end their second chance until it reaches an
+// unreferenced one, which it returns. It moves each entry at most once, as a second pass
+// over an entry finds its bit cleared.
+func (p clockPolicy) take() *models.CacheEntry {
+	for entry := p.cs.tail.Prev; entry != p.cs.head; entry = p.cs.tail.Prev {
+		if !entry.Referenced {
+			return entry
+		}
+		entry.Referenced = false
+		p.cs.removeFromList(entry)
+		p.cs.addToHead(entry)
+	}
+	return nil
+}
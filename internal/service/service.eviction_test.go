@@ -0,0 +1,229 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// scanSurvivors fills a cache of 10 with 5 keys read twice, runs a scan of 100 keys
+// written once through it, and returns how many of the 5 survived
+func scanSurvivors(t *testing.T, opts CacheOptions) int {
+	t.Helper()
+	opts.MaxSize = 10
+	cs := NewCacheServiceWithOptions(opts)
+	defer cs.Close()
+
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("hot%d", i)
+		cs.Put(key, i, nil)
+		cs.Get(key)
+	}
+	for i := 0; i < 100; i++ {
+		cs.Put(fmt.Sprintf("scan%d", i), i, nil)
+	}
+
+	survivors := 0
+	for i := 0; i < 5; i++ {
+		if _, found := cs.GetInfo(fmt.Sprintf("hot%d", i)); found {
+			survivors++
+		}
+	}
+	return survivors
+}
+
+func TestLRUKScanKeepsHotKeys(t *testing.T) {
+	if n := scanSurvivors(t, CacheOptions{EvictionPolicy: constants.EvictionPolicyLRUK}); n != 5 {
+		t.Fatalf("%d of 5 hot keys survived the scan under LRU-2", n)
+	}
+	// Plain LRU is the baseline the policy exists to beat
+	if n := scanSurvivors(t, CacheOptions{}); n != 0 {
+		t.Fatalf("%d of 5 hot keys survived the scan under LRU, want 0", n)
+	}
+}
+
+// lruKBefore reports whether LRU-K evicts a before b, as the policy documents it
+func lruKBefore(k int, a, b *models.CacheEntry) bool {
+	aFull, bFull := len(a.Accesses) >= k, len(b.Accesses) >= k
+	if aFull != bFull {
+		return !aFull
+	}
+	if !aFull {
+		return a.Accesses[len(a.Accesses)-1] < b.Accesses[len(b.Accesses)-1]
+	}
+	return a.Accesses[0] < b.Accesses[0]
+}
+
+func TestLRUKVictimAfterRandomOperations(t *testing.T) {
+	const k = 3
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 50, EvictionPolicy: constants.EvictionPolicyLRUK, LRUK: k})
+	defer cs.Close()
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprintf("k%d", r.Intn(80))
+		switch op := r.Intn(20); {
+		case op < 8:
+			cs.Put(key, i, nil)
+		case op < 17:
+			cs.Get(key)
+		case op < 19:
+			cs.Delete(key)
+		default:
+			cs.Rename(key, fmt.Sprintf("k%d", r.Intn(80)), true)
+		}
+		if i == 2500 {
+			cs.ReplaceAll([]models.PutRequest{{Key: "k1", Value: 1}, {Key: "k2", Value: 2}})
+		}
+		if i == 4000 {
+			cs.Clear()
+		}
+
+		cs.mutex.RLock()
+		victim := cs.eviction.victim()
+		policy := cs.eviction.(lruKPolicy)
+		if len(policy.order.entries) != len(cs.data) {
+			cs.mutex.RUnlock()
+			t.Fatalf("op %d: heap holds %d entries, cache %d", i, len(policy.order.entries), len(cs.data))
+		}
+		for _, entry := range cs.data {
+			if victim == nil || lruKBefore(k, entry, victim) {
+				cs.mutex.RUnlock()
+				t.Fatalf("op %d: victim %v, but %q should be evicted first", i, victim, entry.Key)
+			}
+		}
+		cs.mutex.RUnlock()
+	}
+}
+
+// benchmarkEvictingPuts writes new keys into a full cache of size entries, every one
+// read K times first so no entry is an easy victim
+func benchmarkEvictingPuts(b *testing.B, policy string, size int) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: size, EvictionPolicy: policy, DisableCleanup: true})
+	defer cs.Close()
+	for i := 0; i < size; i++ {
+		key := fmt.Sprintf("k%d", i)
+		cs.Put(key, i, nil)
+		cs.Get(key)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		key := fmt.Sprintf("n%d", i)
+		cs.Put(key, i, nil)
+		cs.Get(key)
+	}
+}
+
+func BenchmarkEvictingPutLRU(b *testing.B) {
+	benchmarkEvictingPuts(b, constants.EvictionPolicyLRU, 100000)
+}
+
+func BenchmarkEvictingPutLRUK(b *testing.B) {
+	benchmarkEvictingPuts(b, constants.EvictionPolicyLRUK, 100000)
+}
+
+// putEvicting stores key and returns the keys evicted to make room for it
+func putEvicting(t *testing.T, cs *CacheService, key string) []string {
+	t.Helper()
+	_, evicted, err := cs.PutItemEvicting(models.PutRequest{Key: key, Value: key}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, len(evicted))
+	for i, entry := range evicted {
+		keys[i] = entry.Key
+	}
+	return keys
+}
+
+func TestClockGivesSecondChance(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 3, EvictionPolicy: constants.EvictionPolicyClock, DisableCleanup: true})
+	defer cs.Close()
+	for _, key := range []string{"a", "b", "c"} {
+		putEvicting(t, cs, key)
+	}
+	cs.Get("a")
+
+	// a is oldest but was read, so it goes round once more and b, c and only then a are evicted
+	for _, step := range []struct{ put, evicted string }{{"d", "b"}, {"e", "c"}, {"f", "a"}} {
+		if evicted := putEvicting(t, cs, step.put); len(evicted) != 1 || evicted[0] != step.evicted {
+			t.Fatalf("put %s evicted %v, want %s", step.put, evicted, step.evicted)
+		}
+	}
+
+	// victim predicts take without clearing any reference bit
+	cs.Get("d")
+	cs.mutex.RLock()
+	victim := cs.eviction.victim()
+	cs.mutex.RUnlock()
+	if victim == nil || victim.Key != "e" {
+		t.Fatalf("victim %v, want e", victim)
+	}
+	if evicted := putEvicting(t, cs, "g"); len(evicted) != 1 || evicted[0] != "e" {
+		t.Fatalf("put g evicted %v, want e", evicted)
+	}
+	if _, found := cs.GetInfo("d"); !found {
+		t.Fatal("referenced d was evicted")
+	}
+}
+
+func TestClockOverwriteEarnsNoSecondChance(t *testing.T) {
+	overwrites := map[string]func(cs *CacheService){
+		"put": func(cs *CacheService) { cs.Put("a", "again", nil) },
+		"import": func(cs *CacheService) {
+			cs.Import([]models.ImportEntry{{Key: "a", Value: "again"}}, constants.ImportStrategyOverwrite)
+		},
+	}
+	for name, overwrite := range overwrites {
+		cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 3, EvictionPolicy: constants.EvictionPolicyClock, DisableCleanup: true})
+		for _, key := range []string{"a", "b", "c"} {
+			putEvicting(t, cs, key)
+		}
+
+		// a was never read, so being written again leaves it the first to go
+		overwrite(cs)
+		if evicted := putEvicting(t, cs, "d"); len(evicted) != 1 || evicted[0] != "a" {
+			t.Fatalf("%s: put d evicted %v, want the overwritten a", name, evicted)
+		}
+		cs.Close()
+	}
+}
+
+// benchmarkWriteHeavy runs 4 writes to every read in parallel over twice as many keys as
+// the cache holds, so most writes of a new key evict
+func benchmarkWriteHeavy(b *testing.B, policy string) {
+	const size = 10000
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: size, EvictionPolicy: policy, DisableCleanup: true})
+	defer cs.Close()
+	keys := make([]string, 2*size)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%d", i)
+		cs.Put(keys[i], i, nil)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for i := 0; pb.Next(); i++ {
+			key := keys[r.Intn(len(keys))]
+			if i%5 == 0 {
+				cs.Get(key)
+			} else {
+				cs.Put(key, i, nil)
+			}
+		}
+	})
+}
+
+func BenchmarkWriteHeavyLRU(b *testing.B) {
+	benchmarkWriteHeavy(b, constants.EvictionPolicyLRU)
+}
+
+func BenchmarkWriteHeavyClock(b *testing.B) {
+	benchmarkWriteHeavy(b, constants.EvictionPolicyClock)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// expiringCandidate is an entry with the time it will expire
+type expiringCandidate struct {
+	entry     *models.CacheEntry
+	expiresAt time.Time
+}
+
+// expiringHeap is a max-heap on expiresAt, keeping the n soonest-expiring candidates seen so far
+type expiringHeap []expiringCandidate
+
+func (h expiringHeap) Len() int            { return len(h) }
+func (h expiringHeap) Less(i, j int) bool  { return h[i].expiresAt.After(h[j].expiresAt) }
+func (h expiringHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *expiringHeap) Push(x interface{}) { *h = append(*h, x.(expiringCandidate)) }
+func (h *expiringHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// expiresAt returns when entry expires, by its TTL or idle limit whichever comes first,
+// and false for an entry with neither
+func expiresAt(entry *models.CacheEntry) (time.Time, bool) {
+	var at time.Time
+	if entry.Expiration != 0 {
+		at = time.Unix(entry.Expiration, 0)
+	}
+	if entry.MaxIdle > 0 {
+		if idle := entry.AccessedAt.Add(entry.MaxIdle); at.IsZero() || idle.Before(at) {
+			at = idle
+		}
+	}
+	return at, !at.IsZero()
+}
+
+// ExpiringSoon returns up to n live entries closest to expiring, soonest first, each with
+// its remaining TTL. Entries without an expiration or idle limit are never included. Like
+// GetInfo it neither promotes keys nor counts hits. Keys stored hashed because of
+// KeyHashThreshold are returned in their hashed form.
+func (cs *CacheService) ExpiringSoon(n int) []models.GetResponse {
+	if n <= 0 {
+		return []models.GetResponse{}
+	}
+
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	soonest := make(expiringHeap, 0, min(n, len(cs.data)))
+	for _, entry := range cs.data {
+		at, expires := expiresAt(entry)
+		if !expires || entry.IsExpired() {
+			continue
+		}
+		if len(soonest) < n {
+			heap.Push(&soonest, expiringCandidate{entry: entry, expiresAt: at})
+			continue
+		}
+		if at.Before(soonest[0].expiresAt) {
+			soonest[0] = expiringCandidate{entry: entry, expiresAt: at}
+			heap.Fix(&soonest, 0)
+		}
+	}
+
+	ordered := make([]*models.CacheEntry, len(soonest))
+	for i := len(soonest) - 1; i >= 0; i-- {
+		ordered[i] = heap.Pop(&soonest).(expiringCandidate).entry
+	}
+
+	responses := make([]models.GetResponse, 0, len(ordered))
+	for _, entry := range ordered {
+		if cs.cipher != nil {
+			plain, err := cs.decryptedCopy(entry)
+			if err != nil {
+				continue
+			}
+			entry = plain
+		}
+		response := entry.ToResponse()
+		ttl := entry.GetTTL()
+		response.TTL = &ttl
+		responses = append(responses, response)
+	}
+	return responses
+}
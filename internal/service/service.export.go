@@ -0,0 +1,38 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// Export returns every live entry with its plain value and remaining TTL (-1 when it never
+// expires), ordered by key. The entries are copied under the read lock, so the result is a
+// consistent view that the caller can write out without holding up the cache. Like GetInfo
+// it neither promotes keys nor counts hits. Entries that fail to decrypt are left out, and
+// keys stored hashed because of KeyHashThreshold are returned in their hashed form.
+func (cs *CacheService) Export() []models.GetResponse {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	responses := make([]models.GetResponse, 0, len(cs.data))
+	for _, entry := range cs.data {
+		if entry.IsExpired() {
+			continue
+		}
+		if cs.cipher != nil {
+			plain, err := cs.decryptedCopy(entry)
+			if err != nil {
+				continue
+			}
+			entry = plain
+		}
+		response := entry.ToResponse()
+		ttl := entry.GetTTL()
+		response.TTL = &ttl
+		responses = append(responses, response)
+	}
+
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Key < responses[j].Key })
+	return responses
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+)
+
+// putAsync runs Put in the background and returns a channel closed once it returns
+func putAsync(cs *CacheService, key string, value interface{}) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cs.Put(key, value, nil)
+	}()
+	return done
+}
+
+func TestFreezeBlocksWritesUntilThaw(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, FreezeTimeout: time.Minute})
+	defer cs.Close()
+	cs.Put("k", "before", nil)
+
+	if err := cs.Freeze(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Freeze(); !errors.Is(err, constants.ErrAlreadyFrozen) {
+		t.Fatalf("second Freeze = %v, want ErrAlreadyFrozen", err)
+	}
+
+	done := putAsync(cs, "k", "after")
+	select {
+	case <-done:
+		t.Fatal("Put returned while the cache was frozen")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Reads are served from the frozen state meanwhile
+	if entry, found := cs.Get("k"); !found || entry.GetValue() != "before" {
+		t.Fatalf("Get while frozen = %v, %v; want before", entry, found)
+	}
+
+	if !cs.Thaw() {
+		t.Fatal("Thaw reported the cache was not frozen")
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Put still blocked after Thaw")
+	}
+	if entry, found := cs.Get("k"); !found || entry.GetValue() != "after" {
+		t.Fatalf("Get after Thaw = %v, %v; want after", entry, found)
+	}
+	if cs.Thaw() {
+		t.Fatal("Thaw of a thawed cache reported it was frozen")
+	}
+}
+
+func TestFreezeTimeoutThaws(t *testing.T) {
+	timeout := 50 * time.Millisecond
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, FreezeTimeout: timeout})
+	defer cs.Close()
+
+	start := time.Now()
+	cs.Freeze()
+	<-putAsync(cs, "k", 1)
+	if waited := time.Since(start); waited < timeout {
+		t.Fatalf("Put returned after %v, before the freeze timed out", waited)
+	}
+	if cs.IsFrozen() {
+		t.Fatal("cache still frozen after its timeout")
+	}
+
+	// A timeout left over from an earlier freeze must not end a later one early
+	cs.Freeze()
+	cs.Thaw()
+	time.Sleep(timeout / 2)
+	cs.Freeze()
+	time.Sleep(timeout * 3 / 4)
+	if !cs.IsFrozen() {
+		t.Fatal("an earlier freeze's timeout thawed a later freeze")
+	}
+	cs.Thaw()
+}
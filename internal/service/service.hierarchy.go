@@ -0,0 +1,72 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// hierarchySeparator splits keys into the segments hierarchy patterns match
+const hierarchySeparator = ":"
+
+// matchHierarchy reports whether key has as many segments as pattern and each segment equals
+// the pattern's, where a "*" segment matches any single segment, including an empty one
+func matchHierarchy(segments []string, key string) bool {
+	for i, segment := range segments {
+		var part string
+		if i == len(segments)-1 {
+			if strings.Contains(key, hierarchySeparator) {
+				return false
+			}
+			part = key
+		} else {
+			var found bool
+			part, key, found = strings.Cut(key, hierarchySeparator)
+			if !found {
+				return false
+			}
+		}
+		if segment != "*" && segment != part {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteByHierarchy removes every key matching a pattern of ":"-separated segments, where a
+// "*" segment matches any one segment: "a:*:c:*" removes a:b:c:d and a:x:c:y but not a:b:c
+// or a:b:c:d:e. It returns how many keys were removed. Unlike DeleteByPattern, "*" never
+// spans a separator. Keys stored hashed because of KeyHashThreshold are matched on their
+// hashed form.
+func (cs *CacheService) DeleteByHierarchy(pattern string) (int, error) {
+	if pattern == "" {
+		return 0, constants.ErrPatternEmpty
+	}
+	if err := cs.writeGuard(); err != nil {
+		return 0, err
+	}
+	segments := strings.Split(pattern, hierarchySeparator)
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	// Collect matches first so the map is not mutated while ranging over it
+	var matched []*models.CacheEntry
+	for key, entry := range cs.data {
+		if matchHierarchy(segments, key) {
+			matched = append(matched, entry)
+		}
+	}
+
+	for _, entry := range matched {
+		cs.removeEntry(entry)
+		cs.opLog.record(OpDelete, entry.Key)
+	}
+	cs.maybeCompact()
+
+	return len(matched), nil
+}
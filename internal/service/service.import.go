@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// importValue is an import entry measured and, when a cipher is set, encrypted ahead of the lock
+type importValue struct {
+	name       string // the key as given, for error messages
+	key        string
+	value      interface{}
+	nonce      []byte
+	size       int64
+	checksum   uint32
+	expiration int64
+	createdAt  time.Time
+}
+
+// Import stores a snapshot of entries, resolving keys that already hold a live value by
+// strategy: overwrite replaces them, skip-existing keeps them, and keep-newer keeps
+// whichever side has the later created_at, the existing value winning a tie. Imported
+// entries keep their created_at. Entries without expire_at never expire; entries whose
+// expire_at has passed are skipped. The whole import runs under one write lock, so no
+// other write interleaves with it. An empty strategy means overwrite.
+func (cs *CacheService) Import(entries []models.ImportEntry, strategy string) (models.ImportResponse, error) {
+	if strategy == "" {
+		strategy = constants.ImportStrategyOverwrite
+	}
+	switch strategy {
+	case constants.ImportStrategyOverwrite, constants.ImportStrategySkipExisting, constants.ImportStrategyKeepNewer:
+	default:
+		return models.ImportResponse{}, fmt.Errorf("%w %q", constants.ErrInvalidStrategy, strategy)
+	}
+	if err := cs.writeGuard(); err != nil {
+		return models.ImportResponse{}, err
+	}
+
+	response := models.ImportResponse{Strategy: strategy}
+	fail := func(key string, err error) {
+		response.Failed++
+		response.Errors = append(response.Errors, fmt.Sprintf("Failed to import key '%s': %v", key, err))
+	}
+
+	// Measure and encrypt outside the lock, as put does
+	now := time.Now()
+	values := make([]importValue, 0, len(entries))
+	for _, item := range entries {
+		if item.Key == "" {
+			fail(item.Key, constants.ErrKeyEmpty)
+			continue
+		}
+		if item.ExpireAt != nil && !item.ExpireAt.After(now) {
+			response.Skipped++
+			continue
+		}
+		var expiration int64
+		if item.ExpireAt != nil {
+			capped, err := cs.capExpiration(item.ExpireAt.Unix())
+			if err != nil {
+				fail(item.Key, err)
+				continue
+			}
+			expiration = capped
+		}
+
+		value := cs.transformPut(item.Key, item.Value)
+		v := importValue{
+			name:       item.Key,
+			key:        cs.internalKey(item.Key),
+			value:      value,
+			size:       valueSize(value),
+			expiration: expiration,
+			createdAt:  now,
+		}
+		if item.CreatedAt != nil {
+			v.createdAt = item.CreatedAt.Time
+		}
+		if cs.cipher != nil {
+			ciphertext, nonce, err := cs.cipher.seal(value)
+			if err != nil {
+				fail(item.Key, err)
+				continue
+			}
+			v.value, v.nonce = ciphertext, nonce
+		}
+		v.checksum = cs.valueChecksum(v.value)
+		values = append(values, v)
+	}
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	for _, v := range values {
+		entry, exists := cs.data[v.key]
+		live := exists && !entry.IsExpired()
+		if live && !importWins(strategy, entry, v.createdAt) {
+			response.Skipped++
+			continue
+		}
+
+		if exists {
+			entry.Expiration = v.expiration
+			entry.MaxIdle = 0
+			entry.SetValue(v.value)
+			entry.Nonce = v.nonce
+			entry.Checksum = v.checksum
+			cs.storeValueSize(entry, v.size)
+			entry.CreatedAt = v.createdAt
+			entry.ModifiedAt = now
+			entry.AccessedAt = now
+			entry.Revalidating = false
+			cs.writeTouch(entry)
+			if cs.hot != nil {
+				cs.hot.invalidate(v.key)
+			}
+			cs.persist.changed(v.key)
+			cs.opLog.record(OpPut, v.key)
+		} else {
+			inserted, _, err := cs.insertEntry(v.key, v.value, v.nonce, v.size, v.expiration)
+			if err != nil {
+				fail(v.name, err)
+				continue
+			}
+			inserted.CreatedAt = v.createdAt
+			inserted.Checksum = v.checksum
+		}
+
+		response.Imported++
+		if live {
+			response.Overwritten++
+		}
+	}
+
+	return response, nil
+}
+
+// importWins reports whether an imported entry created at createdAt replaces the live
+// entry under strategy
+func importWins(strategy string, existing *models.CacheEntry, createdAt time.Time) bool {
+	switch strategy {
+	case constants.ImportStrategySkipExisting:
+		return false
+	case constants.ImportStrategyKeepNewer:
+		return createdAt.After(existing.CreatedAt)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+func TestImportCapsExpiration(t *testing.T) {
+	farOff := &models.Timestamp{Time: time.Now().Add(24 * time.Hour)}
+	entries := []models.ImportEntry{{Key: "k", Value: "v", ExpireAt: farOff}}
+
+	clamp := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, MaxTTL: time.Hour, MaxTTLMode: constants.MaxTTLModeClamp})
+	defer clamp.Close()
+	if response, err := clamp.Import(entries, ""); err != nil || response.Imported != 1 {
+		t.Fatalf("clamp import = %+v, %v; want k imported", response, err)
+	}
+	if info, found := clamp.GetInfo("k"); !found || info.TTL <= 0 || info.TTL > int64(time.Hour.Seconds()) {
+		t.Fatalf("clamped k = %+v, %v; want a TTL of at most an hour", info, found)
+	}
+
+	reject := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, MaxTTL: time.Hour, MaxTTLMode: constants.MaxTTLModeReject})
+	defer reject.Close()
+	if response, err := reject.Import(entries, ""); err != nil || response.Failed != 1 || response.Imported != 0 {
+		t.Fatalf("reject import = %+v, %v; want k failed", response, err)
+	}
+	if _, found := reject.Get("k"); found {
+		t.Fatal("k stored past the max TTL in reject mode")
+	}
+}
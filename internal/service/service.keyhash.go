@@ -0,0 +1,21 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// hashedKeyPrefix marks internal keys that are digests of a longer client key
+const hashedKeyPrefix = "sha256:"
+
+// internalKey maps a client key to the key stored in the map. Keys longer than the
+// configured threshold are replaced by their SHA-256 digest, so the same long key
+// always lands on the same entry. ListKeys reports these digests, not the originals.
+func (cs *CacheService) internalKey(key string) string {
+	if cs.keyHashThreshold <= 0 || len(key) <= cs.keyHashThreshold {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return hashedKeyPrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// latencyReservoirSize is how many samples each operation keeps for percentile estimates
+const latencyReservoirSize = 1024
+
+// latencySampler keeps a uniform random sample of operation latencies (reservoir
+// sampling), so memory stays bounded no matter how many operations are recorded.
+type latencySampler struct {
+	mutex   sync.Mutex
+	samples []time.Duration
+	count   int64 // operations recorded, including those not kept in the reservoir
+	now     func() time.Time
+}
+
+// newLatencySampler creates an empty sampler timed by the wall clock
+func newLatencySampler() *latencySampler {
+	return &latencySampler{
+		samples: make([]time.Duration, 0, latencyReservoirSize),
+		now:     time.Now,
+	}
+}
+
+// start returns the time an operation began, for a deferred observe
+func (ls *latencySampler) start() time.Time {
+	return ls.now()
+}
+
+// observe records the latency of an operation that began at start
+func (ls *latencySampler) observe(start time.Time) {
+	latency := ls.now().Sub(start)
+
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+
+	ls.count++
+	if len(ls.samples) < latencyReservoirSize {
+		ls.samples = append(ls.samples, latency)
+		return
+	}
+	// Keep the new sample with probability reservoir/count, replacing a random one
+	if slot := rand.Int64N(ls.count); slot < latencyReservoirSize {
+		ls.samples[slot] = latency
+	}
+}
+
+// percentiles summarizes the current sample using the nearest-rank method
+func (ls *latencySampler) percentiles() models.LatencyPercentiles {
+	ls.mutex.Lock()
+	sorted := append([]time.Duration(nil), ls.samples...)
+	count := ls.count
+	ls.mutex.Unlock()
+
+	result := models.LatencyPercentiles{Count: count, Samples: len(sorted)}
+	if len(sorted) == 0 {
+		return result
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := func(p float64) time.Duration {
+		return sorted[int(math.Ceil(p/100*float64(len(sorted))))-1]
+	}
+	result.P50 = rank(50).String()
+	result.P95 = rank(95).String()
+	result.P99 = rank(99).String()
+
+	return result
+}
+
+// Latency returns latency percentiles for Get and Put, estimated from a bounded sample
+func (cs *CacheService) Latency() models.LatencyResponse {
+	return models.LatencyResponse{
+		Operations: map[string]models.LatencyPercentiles{
+			"get": cs.getLatency.percentiles(),
+			"put": cs.putLatency.percentiles(),
+		},
+	}
+}
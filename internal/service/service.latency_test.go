@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyPercentiles(t *testing.T) {
+	ls := newLatencySampler()
+	if p := ls.percentiles(); p.Count != 0 || p.P50 != "" {
+		t.Fatalf("empty sampler reported %+v", p)
+	}
+
+	// A fixed clock makes every operation take exactly the time since its start
+	now := time.Now()
+	ls.now = func() time.Time { return now }
+	for ms := 100; ms >= 1; ms-- {
+		ls.observe(now.Add(-time.Duration(ms) * time.Millisecond))
+	}
+
+	p := ls.percentiles()
+	if p.Count != 100 || p.Samples != 100 {
+		t.Fatalf("count %d, samples %d; want 100 and 100", p.Count, p.Samples)
+	}
+	if p.P50 != "50ms" || p.P95 != "95ms" || p.P99 != "99ms" {
+		t.Fatalf("p50 %s, p95 %s, p99 %s; want 50ms, 95ms and 99ms", p.P50, p.P95, p.P99)
+	}
+}
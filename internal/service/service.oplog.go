@@ -0,0 +1,87 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// Operation names recorded in the operation log
+const (
+	OpPut     = "put"
+	OpDelete  = "delete"
+	OpClear   = "clear"
+	OpExpire  = "expire"
+	OpPersist = "persist"
+	OpTouch   = "touch"
+	OpReplace = "replace"
+	OpRename  = "rename"
+)
+
+// opLog is a fixed-size ring buffer of recent mutating operations.
+// It has its own lock so recording never waits on the cache lock.
+type opLog struct {
+	mutex   sync.Mutex
+	entries []models.OpLogEntry
+	next    int  // slot the next record is written to
+	full    bool // whether the buffer has wrapped
+}
+
+// newOpLog creates an operation log holding the last size operations
+func newOpLog(size int) *opLog {
+	return &opLog{entries: make([]models.OpLogEntry, size)}
+}
+
+// record appends an operation, overwriting the oldest once the buffer is full
+func (ol *opLog) record(op, key string) {
+	if ol == nil || len(ol.entries) == 0 {
+		return
+	}
+
+	ol.mutex.Lock()
+	ol.entries[ol.next] = models.OpLogEntry{Op: op, Key: key, Timestamp: time.Now()}
+	ol.next = (ol.next + 1) % len(ol.entries)
+	if ol.next == 0 {
+		ol.full = true
+	}
+	ol.mutex.Unlock()
+}
+
+// recordRename appends a rename, recorded under the new key with the old one as From
+func (ol *opLog) recordRename(from, to string) {
+	if ol == nil || len(ol.entries) == 0 {
+		return
+	}
+
+	ol.mutex.Lock()
+	ol.entries[ol.next] = models.OpLogEntry{Op: OpRename, Key: to, From: from, Timestamp: time.Now()}
+	ol.next = (ol.next + 1) % len(ol.entries)
+	if ol.next == 0 {
+		ol.full = true
+	}
+	ol.mutex.Unlock()
+}
+
+// snapshot returns the recorded operations newest-first
+func (ol *opLog) snapshot() []models.OpLogEntry {
+	if ol == nil {
+		return []models.OpLogEntry{}
+	}
+
+	ol.mutex.Lock()
+	defer ol.mutex.Unlock()
+
+	count := ol.next
+	if ol.full {
+		count = len(ol.entries)
+	}
+
+	result := make([]models.OpLogEntry, 0, count)
+	for i := 1; i <= count; i++ {
+		idx := (ol.next - i + len(ol.entries)) % len(ol.entries)
+		result = append(result, ol.entries[idx])
+	}
+
+	return result
+}
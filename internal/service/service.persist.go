@@ -0,0 +1,453 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// Operations in the write-behind log and snapshot files
+const (
+	persistHeader = "snapshot" // first line of a snapshot, carrying its seq
+	persistSet    = "set"
+	persistDelete = "delete"
+	persistClear  = "clear"
+)
+
+// persistRecord is one JSON line of the write-behind log or snapshot. A set carries the
+// entry's state at flush time, so replaying a key's last record restores it whatever
+// happened to it in between. Encrypted values are written sealed, with their nonce.
+type persistRecord struct {
+	Op         string          `json:"op"`
+	Seq        uint64          `json:"seq,omitempty"` // flush batch (log) or last batch folded in (snapshot)
+	Key        string          `json:"key,omitempty"`
+	Value      json.RawMessage `json:"value,omitempty"`
+	Sealed     []byte          `json:"sealed,omitempty"`
+	Nonce      []byte          `json:"nonce,omitempty"`
+	Expiration int64           `json:"expiration,omitempty"`
+	MaxIdle    time.Duration   `json:"max_idle,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// PersistErrorCallback is invoked when a background flush or compaction fails
+type PersistErrorCallback func(err error)
+
+// writeBehind persists the cache asynchronously: mutated keys are collected in dirty and
+// written to an append-only log every interval, and every compactInterval the whole cache
+// is written to a snapshot and the log truncated. A crash loses at most the mutations since
+// the last flush. Log batches are numbered; a snapshot records the last batch it includes,
+// so a log left behind by a crash during compaction is skipped on replay.
+type writeBehind struct {
+	path            string // snapshot; the log is path + ".log"
+	interval        time.Duration
+	compactInterval time.Duration
+
+	// Guarded by the cache mutex
+	dirty   map[string]struct{}
+	cleared bool
+	closed  bool // set by CloseWriteBehind; later mutations are not persisted
+	seq     uint64
+	onError []PersistErrorCallback
+
+	// Used only by the flusher, or by closeWriteBehind once the flusher has stopped
+	log *os.File
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// changed marks key for the next flush; a no-op when write-behind is off or closed.
+// Callers must hold the write lock.
+func (wb *writeBehind) changed(key string) {
+	if wb == nil || wb.closed {
+		return
+	}
+	wb.dirty[key] = struct{}{}
+}
+
+// clearedAll records that every key was removed; a no-op when write-behind is off or closed.
+// Callers must hold the write lock.
+func (wb *writeBehind) clearedAll() {
+	if wb == nil || wb.closed {
+		return
+	}
+	wb.cleared = true
+	wb.dirty = make(map[string]struct{})
+}
+
+// OpenWriteBehind restores the cache from the snapshot at path and the log beside it
+// (path + ".log"), then persists every later mutation: the log is appended to every
+// interval and folded into a fresh snapshot every compactInterval. A torn last log line,
+// as left by a crash mid-write, is ignored. It returns the number of keys restored and
+// must be called once, before the cache serves traffic.
+func (cs *CacheService) OpenWriteBehind(path string, interval, compactInterval time.Duration) (int, error) {
+	if interval <= 0 || compactInterval <= 0 {
+		return 0, fmt.Errorf("write-behind intervals must be positive")
+	}
+
+	wb := &writeBehind{
+		path:            path,
+		interval:        interval,
+		compactInterval: compactInterval,
+		dirty:           make(map[string]struct{}),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+
+	cs.mutex.Lock()
+	seq, err := cs.restore(path)
+	cs.mutex.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	wb.seq = seq
+
+	// Fold what was restored into a fresh snapshot, leaving an empty log to append to
+	logFile, err := os.OpenFile(path+".log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	wb.log = logFile
+
+	cs.mutex.Lock()
+	cs.persist = wb
+	restored := len(cs.data)
+	cs.mutex.Unlock()
+
+	if err := cs.compactWriteBehind(); err != nil {
+		cs.mutex.Lock()
+		cs.persist = nil
+		cs.mutex.Unlock()
+		logFile.Close()
+		return 0, err
+	}
+
+	go cs.writeBehindWorker()
+	return restored, nil
+}
+
+// OnPersistError registers a callback fired when a background flush or compaction fails
+func (cs *CacheService) OnPersistError(fn PersistErrorCallback) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	if cs.persist != nil {
+		cs.persist.onError = append(cs.persist.onError, fn)
+	}
+}
+
+// CloseWriteBehind stops the background flusher and flushes outstanding mutations. It is a
+// no-op when write-behind is off and safe to call more than once. Mutations made after it
+// returns are not persisted, so writes should be stopped, e.g. by Drain, beforehand.
+func (cs *CacheService) CloseWriteBehind() error {
+	cs.mutex.RLock()
+	wb := cs.persist
+	cs.mutex.RUnlock()
+	if wb == nil {
+		return nil
+	}
+
+	var err error
+	wb.stopOnce.Do(func() {
+		close(wb.stop)
+		<-wb.done
+		err = cs.flushWriteBehind()
+		cs.mutex.Lock()
+		wb.closed = true
+		cs.mutex.Unlock()
+		if closeErr := wb.log.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
+
+// writeBehindWorker flushes every interval and compacts every compactInterval until stopped
+func (cs *CacheService) writeBehindWorker() {
+	wb := cs.persist
+	defer close(wb.done)
+
+	flush := time.NewTicker(wb.interval)
+	defer flush.Stop()
+	compact := time.NewTicker(wb.compactInterval)
+	defer compact.Stop()
+
+	// After a failure the log may hold a torn batch and has lost the failed batch's keys;
+	// a compaction rewrites both files from the cache, so the next tick retries with one
+	failed := false
+	for {
+		var err error
+		select {
+		case <-flush.C:
+			if failed {
+				err = cs.compactWriteBehind()
+			} else {
+				err = cs.flushWriteBehind()
+			}
+		case <-compact.C:
+			err = cs.compactWriteBehind()
+		case <-wb.stop:
+			return
+		}
+		failed = err != nil
+		if err != nil {
+			cs.mutex.RLock()
+			callbacks := wb.onError
+			cs.mutex.RUnlock()
+			for _, fn := range callbacks {
+				fn(err)
+			}
+		}
+	}
+}
+
+// flushWriteBehind appends the state of every key changed since the last flush to the log
+// as one batch and syncs it to disk
+func (cs *CacheService) flushWriteBehind() error {
+	cs.mutex.Lock()
+	wb := cs.persist
+	if len(wb.dirty) == 0 && !wb.cleared {
+		cs.mutex.Unlock()
+		return nil
+	}
+	wb.seq++
+	seq := wb.seq
+
+	records := make([]persistRecord, 0, len(wb.dirty)+1)
+	if wb.cleared {
+		records = append(records, persistRecord{Op: persistClear})
+	}
+	for key := range wb.dirty {
+		if entry, exists := cs.data[key]; exists {
+			records = append(records, persistedEntry(entry))
+		} else {
+			records = append(records, persistRecord{Op: persistDelete, Key: key})
+		}
+	}
+	wb.dirty = make(map[string]struct{})
+	wb.cleared = false
+	cs.mutex.Unlock()
+
+	batch, err := encodeRecords(records, seq)
+	if err != nil {
+		return err
+	}
+	if _, err := wb.log.Write(batch); err != nil {
+		return err
+	}
+	return wb.log.Sync()
+}
+
+// compactWriteBehind writes every entry to a new snapshot, replaces the old one and
+// truncates the log, whose batches the snapshot now includes
+func (cs *CacheService) compactWriteBehind() error {
+	cs.mutex.Lock()
+	wb := cs.persist
+	seq := wb.seq
+	records := make([]persistRecord, 0, len(cs.data)+1)
+	records = append(records, persistRecord{Op: persistHeader, Seq: seq})
+	for _, entry := range cs.data {
+		records = append(records, persistedEntry(entry))
+	}
+	wb.dirty = make(map[string]struct{})
+	wb.cleared = false
+	cs.mutex.Unlock()
+
+	snapshot, err := encodeRecords(records, 0)
+	if err != nil {
+		return err
+	}
+
+	// Write beside the old snapshot and rename over it, so a crash leaves one or the other
+	tmp := wb.path + ".tmp"
+	if err := writeFileSync(tmp, snapshot); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, wb.path); err != nil {
+		return err
+	}
+	return wb.log.Truncate(0)
+}
+
+// persistedEntry captures entry as a set record. The value is referenced, not copied:
+// stored values are replaced on write, never modified in place. Callers must hold the lock.
+func persistedEntry(entry *models.CacheEntry) persistRecord {
+	record := persistRecord{
+		Op:         persistSet,
+		Key:        entry.Key,
+		Expiration: entry.Expiration,
+		MaxIdle:    entry.MaxIdle,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if entry.Nonce != nil {
+		record.Sealed, record.Nonce = entry.GetValue().([]byte), entry.Nonce
+	} else {
+		record.Value = rawJSON(entry.GetValue())
+	}
+	return record
+}
+
+// rawJSON returns value as JSON, or null when it cannot be serialized
+func rawJSON(value interface{}) json.RawMessage {
+	if raw, ok := value.(json.RawMessage); ok {
+		return raw
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return encoded
+}
+
+// encodeRecords encodes records as JSON lines, stamping each with seq when it is not 0
+func encodeRecords(records []persistRecord, seq uint64) ([]byte, error) {
+	var buf []byte
+	for _, record := range records {
+		if seq != 0 {
+			record.Seq = seq
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	return buf, nil
+}
+
+// writeFileSync writes data to a new file at path and syncs it to disk
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// restore applies the snapshot at path and then the log batches written after it, and
+// returns the highest batch number seen. Missing files are treated as empty. Callers must
+// hold the write lock.
+func (cs *CacheService) restore(path string) (uint64, error) {
+	var snapshotSeq uint64
+	err := readRecords(path, func(record persistRecord) {
+		if record.Op == persistHeader {
+			snapshotSeq = record.Seq
+			return
+		}
+		cs.applyPersisted(record)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	seq := snapshotSeq
+	err = readRecords(path+".log", func(record persistRecord) {
+		if record.Seq <= snapshotSeq {
+			return
+		}
+		cs.applyPersisted(record)
+		seq = max(seq, record.Seq)
+	})
+	return seq, err
+}
+
+// readRecords calls apply for each record in the file at path, stopping at the first line
+// that does not decode: a torn write at the end of the log
+func readRecords(path string, apply func(persistRecord)) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<30)
+	for scanner.Scan() {
+		var record persistRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil
+		}
+		apply(record)
+	}
+	return scanner.Err()
+}
+
+// applyPersisted replays one record. Entries that have expired since, that were sealed
+// without a cipher to open them now, or that cannot be stored are skipped. Callers must
+// hold the write lock.
+func (cs *CacheService) applyPersisted(record persistRecord) {
+	switch record.Op {
+	case persistClear:
+		for _, entry := range cs.data {
+			cs.removeEntry(entry)
+		}
+		return
+	case persistDelete:
+		if entry, exists := cs.data[record.Key]; exists {
+			cs.removeEntry(entry)
+		}
+		return
+	case persistSet:
+	default:
+		return
+	}
+
+	if entry, exists := cs.data[record.Key]; exists {
+		cs.removeEntry(entry)
+	}
+	if record.Expiration != 0 && record.Expiration <= time.Now().Unix() {
+		return
+	}
+
+	var plain, stored interface{}
+	var nonce []byte
+	if record.Sealed != nil {
+		if cs.cipher == nil {
+			return
+		}
+		value, err := cs.cipher.open(record.Sealed, record.Nonce)
+		if err != nil {
+			return
+		}
+		plain, stored, nonce = value, record.Sealed, record.Nonce
+	} else {
+		if err := json.Unmarshal(record.Value, &plain); err != nil {
+			return
+		}
+		stored = plain
+		if cs.cipher != nil {
+			ciphertext, n, err := cs.cipher.seal(plain)
+			if err != nil {
+				return
+			}
+			stored, nonce = ciphertext, n
+		}
+	}
+
+	entry, _, err := cs.insertEntry(record.Key, stored, nonce, valueSize(plain), record.Expiration)
+	if err != nil {
+		return
+	}
+	entry.MaxIdle = record.MaxIdle
+	entry.CreatedAt = record.CreatedAt
+	entry.Checksum = cs.valueChecksum(stored)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openPersisted returns a cache built with opts, restored from and persisting to path with
+// intervals long enough that only CloseWriteBehind flushes, and the number of keys restored
+func openPersisted(t *testing.T, path string, opts CacheOptions) (*CacheService, int) {
+	t.Helper()
+	opts.MaxSize, opts.DisableCleanup = 100, true
+	cs := NewCacheServiceWithOptions(opts)
+	restored, err := cs.OpenWriteBehind(path, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenWriteBehind: %v", err)
+	}
+	return cs, restored
+}
+
+func TestCloseWriteBehindFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	cs, _ := openPersisted(t, path, CacheOptions{})
+	cs.Put("a", 1, nil)
+	cs.Put("b", 2, nil)
+	cs.Delete("a")
+	if err := cs.CloseWriteBehind(); err != nil {
+		t.Fatalf("CloseWriteBehind: %v", err)
+	}
+
+	// Later mutations are neither persisted nor collected
+	cs.Put("late", 3, nil)
+	cs.mutex.RLock()
+	dirty := len(cs.persist.dirty)
+	cs.mutex.RUnlock()
+	if dirty != 0 {
+		t.Fatalf("%d keys marked dirty after close", dirty)
+	}
+	if err := cs.CloseWriteBehind(); err != nil {
+		t.Fatalf("second CloseWriteBehind: %v", err)
+	}
+	cs.Close()
+
+	restored, n := openPersisted(t, path, CacheOptions{})
+	defer restored.Close()
+	if n != 1 {
+		t.Fatalf("restored %d keys, want 1", n)
+	}
+	if entry, found := restored.Get("b"); !found || entry.GetValue() != 2.0 {
+		t.Fatalf("b = %v, %v; want 2", entry, found)
+	}
+}
+
+// writeRecords writes records to path as a snapshot or log file would hold them
+func writeRecords(t *testing.T, path string, records ...persistRecord) {
+	t.Helper()
+	data, err := encodeRecords(records, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreIgnoresTornLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	cs, _ := openPersisted(t, path, CacheOptions{})
+	cs.Put("a", 1, nil)
+	cs.Put("b", 2, nil)
+	cs.Close()
+
+	// A crash mid-write leaves the start of a record without its newline
+	log, err := os.OpenFile(path+".log", os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.WriteString(`{"op":"set","key":"c","value":`)
+	log.Close()
+
+	restored, n := openPersisted(t, path, CacheOptions{})
+	defer restored.Close()
+	if n != 2 {
+		t.Fatalf("restored %d keys, want 2", n)
+	}
+	if _, found := restored.Get("c"); found {
+		t.Fatal("torn record restored")
+	}
+	if entry, found := restored.Get("b"); !found || entry.GetValue() != 2.0 {
+		t.Fatalf("b = %v, %v; want 2", entry, found)
+	}
+}
+
+func TestRestoreSkipsBatchesInSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	raw := func(v string) json.RawMessage { return json.RawMessage(`"` + v + `"`) }
+
+	// A crash after the snapshot was renamed into place but before the log was truncated:
+	// batches 1 and 2 are already folded into the snapshot, batch 3 came after it
+	writeRecords(t, path,
+		persistRecord{Op: persistHeader, Seq: 2},
+		persistRecord{Op: persistSet, Key: "kept", Value: raw("snapshot")},
+	)
+	writeRecords(t, path+".log",
+		persistRecord{Op: persistSet, Seq: 1, Key: "kept", Value: raw("stale")},
+		persistRecord{Op: persistSet, Seq: 1, Key: "deleted", Value: raw("stale")},
+		persistRecord{Op: persistDelete, Seq: 2, Key: "deleted"},
+		persistRecord{Op: persistClear, Seq: 2},
+		persistRecord{Op: persistSet, Seq: 3, Key: "later", Value: raw("log")},
+	)
+
+	cs, n := openPersisted(t, path, CacheOptions{})
+	defer cs.Close()
+	if n != 2 {
+		t.Fatalf("restored %d keys, want 2", n)
+	}
+	if entry, found := cs.Get("kept"); !found || entry.GetValue() != "snapshot" {
+		t.Fatalf("kept = %v, %v; want the snapshot's value", entry, found)
+	}
+	if entry, found := cs.Get("later"); !found || entry.GetValue() != "log" {
+		t.Fatalf("later = %v, %v; want the value of the batch after the snapshot", entry, found)
+	}
+	if _, found := cs.Get("deleted"); found {
+		t.Fatal("key from a batch folded into the snapshot restored")
+	}
+
+	// Batches flushed from here on continue the numbering past the log's
+	cs.mutex.RLock()
+	seq := cs.persist.seq
+	cs.mutex.RUnlock()
+	if seq != 3 {
+		t.Fatalf("seq = %d after restore, want 3", seq)
+	}
+}
+
+func TestRestoreEncryptedRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	key := []byte("0123456789abcdef")
+	cs, _ := openPersisted(t, path, CacheOptions{EncryptionKey: key})
+	cs.Put("k", "secret value", nil)
+	cs.Close()
+
+	log, err := os.ReadFile(path + ".log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(log, []byte("secret value")) {
+		t.Fatal("plaintext value written to the log")
+	}
+
+	restored, n := openPersisted(t, path, CacheOptions{EncryptionKey: key})
+	if entry, found := restored.Get("k"); n != 1 || !found || entry.GetValue() != "secret value" {
+		t.Fatalf("restored %d keys, k = %v, %v; want the decrypted value", n, entry, found)
+	}
+	restored.Close()
+
+	// Without the key the sealed records cannot be opened and are skipped
+	plain, n := openPersisted(t, path, CacheOptions{})
+	defer plain.Close()
+	if n != 0 {
+		t.Fatalf("restored %d sealed keys without a key, want 0", n)
+	}
+}
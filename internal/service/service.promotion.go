@@ -0,0 +1,141 @@
+package service
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+const (
+	// promotionBuffer is how many reads may wait to be applied to the recency list; a read
+	// that finds the buffer full takes the write lock and applies them itself
+	promotionBuffer = 4096
+
+	// promotionInterval is how often the background worker applies waiting reads
+	promotionInterval = 10 * time.Millisecond
+)
+
+// promotion is a read waiting to move its entry to the head of the recency list
+type promotion struct {
+	entry *models.CacheEntry
+	at    time.Time
+}
+
+// promotionQueue collects reads served under the read lock so their effect on recency
+// can be applied later under the write lock, in one batch
+type promotionQueue struct {
+	pending chan promotion
+}
+
+// newPromotionQueue creates an empty promotion queue
+func newPromotionQueue() *promotionQueue {
+	return &promotionQueue{pending: make(chan promotion, promotionBuffer)}
+}
+
+// record queues a read of entry and reports whether there was room for it
+func (q *promotionQueue) record(entry *models.CacheEntry) bool {
+	select {
+	case q.pending <- promotion{entry: entry, at: time.Now()}:
+		return true
+	default:
+		return false
+	}
+}
+
+// getShared serves a Get under the read lock when batching promotions, queueing the move
+// to the head of the recency list instead of making it. handled is false when the read
+// changes more than recency and needs the write lock: the key is expired or stale, has an
+// idle limit (whose clock a delayed access time would distort), TTL promotion is on, or
+// the queue is full.
+func (cs *CacheService) getShared(clientKey, key string) (entry *models.CacheEntry, found, handled bool) {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	entry, exists := cs.data[key]
+	if !exists {
+		atomic.AddInt64(&cs.misses, 1)
+		return nil, false, true
+	}
+	// Corrupted entries are removed, which takes the write lock
+	if entry.IsExpired() || entry.MaxIdle > 0 || cs.ttlPromotionStep > 0 || !cs.intact(entry) {
+		return nil, false, false
+	}
+	if !cs.promotions.record(entry) {
+		return nil, false, false
+	}
+	atomic.AddInt64(&entry.AccessCount, 1)
+
+	if cs.cipher != nil {
+		plain, err := cs.decryptedCopy(entry)
+		if err != nil {
+			atomic.AddInt64(&cs.misses, 1)
+			return nil, false, true
+		}
+		entry = plain
+	}
+	atomic.AddInt64(&cs.hits, 1)
+
+	return cs.transformGet(clientKey, entry), true, true
+}
+
+// applyPromotions moves every entry read since the last batch to the head of the recency
+// list, oldest read first, and updates its access time. Entries that left the cache in the
+// meantime are skipped. A no-op unless promotions are batched. Callers must hold the write lock.
+func (cs *CacheService) applyPromotions() {
+	if cs.promotions == nil {
+		return
+	}
+	for {
+		select {
+		case p := <-cs.promotions.pending:
+			if p.entry.Removed {
+				continue
+			}
+			if p.at.After(p.entry.AccessedAt) {
+				p.entry.AccessedAt = p.at
+			}
+			cs.moveToHead(p.entry)
+		default:
+			return
+		}
+	}
+}
+
+// dropPromotions discards every waiting promotion without applying it. Clear and ReplaceAll
+// call it before swapping in a new list, as the entries read belong to the old one and
+// relinking them would put orphans back on the list. A hot tier must be invalidated first:
+// its hits queue promotions without the write lock, so until then one could queue an old
+// entry after the drain. A no-op unless promotions are batched. Callers must hold the write lock.
+func (cs *CacheService) dropPromotions() {
+	if cs.promotions == nil {
+		return
+	}
+	for {
+		select {
+		case <-cs.promotions.pending:
+		default:
+			return
+		}
+	}
+}
+
+// promotionWorker applies batched promotions every promotionInterval until Close
+func (cs *CacheService) promotionWorker() {
+	ticker := time.NewTicker(promotionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if len(cs.promotions.pending) == 0 {
+				continue
+			}
+			cs.mutex.Lock()
+			cs.applyPromotions()
+			cs.mutex.Unlock()
+		case <-cs.stopCleanup:
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// checkList fails the test unless the recency list holds exactly the keys in the map, each
+// entry once and linked both ways
+func checkList(t *testing.T, cs *CacheService) {
+	t.Helper()
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	seen := make(map[string]bool)
+	for node := cs.head.Next; node != cs.tail; node = node.Next {
+		if node.Next.Prev != node {
+			t.Fatalf("entry %q is not linked back from its successor", node.Key)
+		}
+		if cs.data[node.Key] != node {
+			t.Fatalf("entry %q on the list is not the one in the map", node.Key)
+		}
+		if seen[node.Key] {
+			t.Fatalf("entry %q is on the list twice", node.Key)
+		}
+		seen[node.Key] = true
+	}
+	if len(seen) != len(cs.data) {
+		t.Fatalf("list holds %d entries, map holds %d", len(seen), len(cs.data))
+	}
+}
+
+// newPromotingCache returns a cache of two keys batching promotions and the Get to read it
+// through: the hot tier's when hot, whose hits queue promotions without the write lock
+func newPromotingCache(hot bool) (*CacheService, func(string) (*models.CacheEntry, bool)) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 2, BatchPromotions: true, DisableCleanup: true})
+	if !hot {
+		return cs, cs.Get
+	}
+	return cs, NewTieredCacheService(cs, 4).Get
+}
+
+func TestClearDropsQueuedPromotions(t *testing.T) {
+	for _, hot := range []bool{false, true} {
+		cs, get := newPromotingCache(hot)
+
+		cs.Put("a", 1, nil)
+		cs.Put("b", 2, nil)
+		for i := 0; i < 3; i++ {
+			if _, found := get("a"); !found {
+				t.Fatalf("hot=%v: a missing before Clear", hot)
+			}
+		}
+		cs.Clear()
+		if n := len(cs.promotions.pending); n != 0 {
+			t.Fatalf("hot=%v: %d promotions still queued after Clear", hot, n)
+		}
+
+		cs.Put("a", 10, nil)
+		cs.Put("b", 20, nil)
+		cs.Put("c", 30, nil) // evicts a
+		cs.Put("d", 40, nil) // evicts b
+		checkList(t, cs)
+
+		for key, want := range map[string]int{"c": 30, "d": 40} {
+			entry, found := get(key)
+			if !found || entry.GetValue() != want {
+				t.Fatalf("hot=%v: Get(%q) = %v, %v after evictions; want %d", hot, key, entry, found, want)
+			}
+		}
+		cs.Close()
+	}
+}
+
+func TestReplaceAllDropsQueuedPromotions(t *testing.T) {
+	for _, hot := range []bool{false, true} {
+		cs, get := newPromotingCache(hot)
+
+		cs.Put("a", 1, nil)
+		for i := 0; i < 3; i++ {
+			if _, found := get("a"); !found {
+				t.Fatalf("hot=%v: a missing before ReplaceAll", hot)
+			}
+		}
+		cs.ReplaceAll([]models.PutRequest{{Key: "a", Value: 10}, {Key: "b", Value: 20}})
+		if n := len(cs.promotions.pending); n != 0 {
+			t.Fatalf("hot=%v: %d promotions still queued after ReplaceAll", hot, n)
+		}
+
+		cs.Put("c", 30, nil) // evicts a
+		checkList(t, cs)
+		if _, found := get("a"); found {
+			t.Fatalf("hot=%v: a survived eviction", hot)
+		}
+		if entry, found := get("b"); !found || entry.GetValue() != 20 {
+			t.Fatalf("hot=%v: Get(b) = %v, %v; want 20", hot, entry, found)
+		}
+		cs.Close()
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import "sync"
+
+// subscriberBuffer is how many undelivered messages a subscriber may lag behind
+// before further messages to it are dropped
+const subscriberBuffer = 64
+
+// Subscription receives the messages published to one channel.
+// Messages are never persisted; a subscriber only sees what is published while it is registered.
+type Subscription struct {
+	Channel  string
+	Messages <-chan interface{}
+
+	messages chan interface{}
+}
+
+// pubSub is the subscription registry, kept apart from the key-value store and its lock
+type pubSub struct {
+	mutex    sync.RWMutex
+	channels map[string]map[*Subscription]struct{}
+}
+
+// newPubSub creates an empty subscription registry
+func newPubSub() *pubSub {
+	return &pubSub{channels: make(map[string]map[*Subscription]struct{})}
+}
+
+// Subscribe registers a new subscriber on channel. Callers must Unsubscribe when done.
+func (cs *CacheService) Subscribe(channel string) *Subscription {
+	messages := make(chan interface{}, subscriberBuffer)
+	sub := &Subscription{Channel: channel, Messages: messages, messages: messages}
+
+	cs.pubsub.mutex.Lock()
+	defer cs.pubsub.mutex.Unlock()
+
+	if cs.pubsub.channels[channel] == nil {
+		cs.pubsub.channels[channel] = make(map[*Subscription]struct{})
+	}
+	cs.pubsub.channels[channel][sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber; its Messages channel is closed
+func (cs *CacheService) Unsubscribe(sub *Subscription) {
+	cs.pubsub.mutex.Lock()
+	defer cs.pubsub.mutex.Unlock()
+
+	subscribers := cs.pubsub.channels[sub.Channel]
+	if _, ok := subscribers[sub]; !ok {
+		return
+	}
+
+	delete(subscribers, sub)
+	if len(subscribers) == 0 {
+		delete(cs.pubsub.channels, sub.Channel)
+	}
+	close(sub.messages)
+}
+
+// Publish delivers message to every current subscriber of channel and returns how
+// many received it. Delivery never blocks: a subscriber whose buffer is full misses the message.
+func (cs *CacheService) Publish(channel string, message interface{}) int {
+	cs.pubsub.mutex.RLock()
+	defer cs.pubsub.mutex.RUnlock()
+
+	delivered := 0
+	for sub := range cs.pubsub.channels[channel] {
+		select {
+		case sub.messages <- message:
+			delivered++
+		default:
+		}
+	}
+
+	return delivered
+}
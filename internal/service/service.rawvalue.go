@@ -0,0 +1,19 @@
+package service
+
+import "encoding/json"
+
+// decodeRaw returns a value stored as raw JSON (a put with raw_value) decoded the way a
+// regular put would have stored it, so it compares equal to one; other values are returned
+// as they are. Raw JSON that fails to decode is returned undecoded.
+func decodeRaw(value interface{}) interface{} {
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		return value
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// ReplaceAll swaps the entire contents of the cache for entries and returns how many are
+// stored. The new map and recency list are built without holding the lock, values measured
+// and encrypted as put does, then swapped in under one brief write lock, so a reader sees
+// either all of the old contents or all of the new. Each entry gets the expiration a put
+// would give it; later entries are more recent, and a key given twice keeps its last value.
+// Entries with an empty key, conflicting expiration fields, an expiration already passed
+// or over MaxTTL in reject mode, or a value that cannot be encrypted are skipped, as are
+// new keys once MaxSize is reached.
+// Statistics carry on across the swap and the cleanup worker sweeps the new contents.
+// Under LRU-K eviction the swap also rebuilds the eviction order, and under clock it clears
+// the reference bits, in time linear in the number of entries stored.
+// It returns 0 without changing anything when writes are rejected.
+func (cs *CacheService) ReplaceAll(entries []models.PutRequest) int {
+	if cs.writeGuard() != nil {
+		return 0
+	}
+
+	now := time.Now()
+	data := make(map[string]*models.CacheEntry, min(len(entries), cs.maxSize))
+	head, tail := &models.CacheEntry{}, &models.CacheEntry{}
+	head.Next, tail.Prev = tail, head
+	var sizes valueSizeStats
+
+	for _, item := range entries {
+		if item.Key == "" {
+			continue
+		}
+		expiration, maxIdle, err := cs.itemExpiration(item, nil)
+		if err == nil {
+			expiration, err = cs.capExpiration(expiration)
+		}
+		if err != nil || expiration != 0 && expiration <= now.Unix() {
+			continue
+		}
+		key := cs.internalKey(item.Key)
+
+		value := cs.transformPut(item.Key, item.Value)
+		var nonce []byte
+		size := valueSize(value)
+		if cs.cipher != nil {
+			ciphertext, n, err := cs.cipher.seal(value)
+			if err != nil {
+				continue
+			}
+			value, nonce = ciphertext, n
+		}
+
+		if previous, exists := data[key]; exists {
+			previous.Prev.Next, previous.Next.Prev = previous.Next, previous.Prev
+			sizes.current -= previous.ValueSize
+		} else if len(data) >= cs.maxSize {
+			continue
+		}
+
+		entry := &models.CacheEntry{
+			Key:        key,
+			Nonce:      nonce,
+			Expiration: expiration,
+			MaxIdle:    maxIdle,
+			CreatedAt:  now,
+			ModifiedAt: now,
+			AccessedAt: now,
+			ValueSize:  size,
+			Checksum:   cs.valueChecksum(value),
+		}
+		entry.SetValue(value)
+		entry.Prev, entry.Next = head, head.Next
+		head.Next.Prev = entry
+		head.Next = entry
+		cs.eviction.accessed(entry)
+		data[key] = entry
+		sizes.record(size)
+		sizes.current += size
+	}
+
+	cs.freeze.gate.RLock()
+	defer cs.freeze.gate.RUnlock()
+
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	if cs.hot != nil {
+		cs.hot.invalidateAll()
+	}
+	cs.dropPromotions()
+	cs.data, cs.head, cs.tail = data, head, tail
+	cs.eviction.reset(data)
+	cs.peakSize = len(data)
+	cs.valueSizes.replace(sizes)
+	if cs.persist != nil {
+		cs.persist.clearedAll()
+		for key := range data {
+			cs.persist.changed(key)
+		}
+	}
+	cs.opLog.record(OpReplace, "")
+
+	return len(data)
+}
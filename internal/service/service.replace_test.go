@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// generation returns 100 put requests for keys prefixed with name
+func generation(name string) []models.PutRequest {
+	items := make([]models.PutRequest, 100)
+	for i := range items {
+		items[i] = models.PutRequest{Key: fmt.Sprintf("%s:%d", name, i), Value: name}
+	}
+	return items
+}
+
+func TestReplaceAllIsAtomic(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 1000, DisableCleanup: true})
+	defer cs.Close()
+	cs.ReplaceAll(generation("g0"))
+
+	// Every listing taken while the contents are swapped must hold exactly one generation
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				keys := cs.ListKeys()
+				if len(keys) != 100 {
+					t.Errorf("listing holds %d keys, want one whole generation of 100", len(keys))
+					return
+				}
+				name := strings.SplitN(keys[0], ":", 2)[0]
+				for _, key := range keys {
+					if !strings.HasPrefix(key, name+":") {
+						t.Errorf("listing mixes %s with %s", key, keys[0])
+						return
+					}
+				}
+			}
+		}()
+	}
+	for g := 1; g <= 50; g++ {
+		if n := cs.ReplaceAll(generation(fmt.Sprintf("g%d", g))); n != 100 {
+			t.Fatalf("ReplaceAll stored %d entries, want 100", n)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if _, found := cs.Get("g49:0"); found {
+		t.Fatal("old generation still readable after ReplaceAll returned")
+	}
+	if entry, found := cs.Get("g50:99"); !found || entry.GetValue() != "g50" {
+		t.Fatalf("Get(g50:99) = %v, %v; want the new generation", entry, found)
+	}
+}
+
+func TestReplaceAllKeepsStatsAndCleanup(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, DisableCleanup: true})
+	defer cs.Close()
+	cs.Put("old", 1, nil)
+	cs.Get("old")
+	cs.Get("missing")
+
+	ttl := 60
+	cs.ReplaceAll([]models.PutRequest{{Key: "a", Value: 1, TTL: &ttl}, {Key: "b", Value: 2}})
+	stats := cs.GetStats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.CurrentSize != 2 {
+		t.Fatalf("stats after ReplaceAll %+v, want 1 hit, 1 miss and 2 keys", stats)
+	}
+
+	// The cleanup sweep reaps swapped-in entries like any others
+	expireNow(cs, "a")
+	if status := cs.RunCleanup(); status.LastReaped != 1 {
+		t.Fatalf("cleanup removed %d entries, want 1", status.LastReaped)
+	}
+	if _, found := cs.Get("b"); !found {
+		t.Fatal("b missing after cleanup")
+	}
+}
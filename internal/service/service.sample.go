@@ -0,0 +1,54 @@
+package service
+
+import (
+	"math/rand/v2"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// Sample returns up to n distinct live entries chosen uniformly at random, each with its
+// remaining TTL (-1 when it never expires), for estimating properties of a large cache
+// without reading all of it. Entries are picked by reservoir sampling in one pass over the
+// key map under the read lock, so every live entry is examined but only n are copied.
+// When fewer than n entries are live, all of them are returned, in no particular order.
+// Like GetInfo it neither promotes keys nor counts hits. Entries that fail to decrypt are
+// left out, and keys stored hashed because of KeyHashThreshold are returned in their
+// hashed form.
+func (cs *CacheService) Sample(n int) []models.GetResponse {
+	if n <= 0 {
+		return []models.GetResponse{}
+	}
+
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	reservoir := make([]*models.CacheEntry, 0, min(n, len(cs.data)))
+	seen := 0
+	for _, entry := range cs.data {
+		if entry.IsExpired() {
+			continue
+		}
+		seen++
+		if len(reservoir) < n {
+			reservoir = append(reservoir, entry)
+		} else if i := rand.IntN(seen); i < n {
+			reservoir[i] = entry
+		}
+	}
+
+	responses := make([]models.GetResponse, 0, len(reservoir))
+	for _, entry := range reservoir {
+		if cs.cipher != nil {
+			plain, err := cs.decryptedCopy(entry)
+			if err != nil {
+				continue
+			}
+			entry = plain
+		}
+		response := entry.ToResponse()
+		ttl := entry.GetTTL()
+		response.TTL = &ttl
+		responses = append(responses, response)
+	}
+	return responses
+}
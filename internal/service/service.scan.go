@@ -0,0 +1,86 @@
+package service
+
+import (
+	"container/heap"
+	"hash/fnv"
+	"math"
+)
+
+// scanCandidate is a key with its position in scan order
+type scanCandidate struct {
+	key  string
+	hash uint64
+}
+
+// scanHeap is a max-heap on hash, keeping the count lowest-hashed candidates seen so far
+type scanHeap []scanCandidate
+
+func (h scanHeap) Len() int            { return len(h) }
+func (h scanHeap) Less(i, j int) bool  { return h[i].hash > h[j].hash }
+func (h scanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *scanHeap) Push(x interface{}) { *h = append(*h, x.(scanCandidate)) }
+func (h *scanHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// scanHash places a key in scan order
+func scanHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// Scan returns up to count live keys at or after cursor in scan order, plus the cursor for
+// the next page; a returned cursor of 0 means the iteration is complete. Start with cursor 0.
+//
+// Keys are ordered by a 64-bit hash of the key, so the cursor is stateless and a key present
+// for the whole iteration is returned exactly once. Keys added or removed mid-iteration may
+// or may not be seen, and a key renamed mid-iteration may be seen twice. Each page walks the
+// key map once but only holds count keys, so memory stays bounded by the page size.
+// Keys stored hashed because of KeyHashThreshold are returned in their hashed form.
+func (cs *CacheService) Scan(cursor uint64, count int) ([]string, uint64) {
+	if count <= 0 {
+		return []string{}, cursor
+	}
+
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	page := make(scanHeap, 0, count)
+	more := false
+	for key, entry := range cs.data {
+		if entry.IsExpired() {
+			continue
+		}
+		hash := scanHash(key)
+		if hash < cursor {
+			continue
+		}
+		if len(page) < count {
+			heap.Push(&page, scanCandidate{key: key, hash: hash})
+			continue
+		}
+		more = true
+		if hash < page[0].hash {
+			page[0] = scanCandidate{key: key, hash: hash}
+			heap.Fix(&page, 0)
+		}
+	}
+
+	keys := make([]string, len(page))
+	for i := len(page) - 1; i >= 0; i-- {
+		keys[i] = heap.Pop(&page).(scanCandidate).key
+	}
+
+	if !more || len(keys) == 0 {
+		return keys, 0
+	}
+	last := scanHash(keys[len(keys)-1])
+	if last == math.MaxUint64 {
+		return keys, 0
+	}
+	return keys, last + 1
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+)
+
+// thrashWindow is how many seconds of evictions the rolling eviction rate covers
+const thrashWindow = 10
+
+// ThrashCallback is invoked when the cache starts thrashing (engaged) and when it stops,
+// with the eviction rate in evictions per second that caused the change
+type ThrashCallback func(engaged bool, rate float64)
+
+// evictionRate counts evictions in one-second buckets over the last thrashWindow seconds.
+// Each bucket remembers the second it counts, so stale buckets are skipped without a sweep.
+type evictionRate struct {
+	counts  [thrashWindow]int64
+	seconds [thrashWindow]int64
+}
+
+// record counts one eviction at now
+func (r *evictionRate) record(now time.Time) {
+	second := now.Unix()
+	i := second % thrashWindow
+	if r.seconds[i] != second {
+		r.seconds[i], r.counts[i] = second, 0
+	}
+	r.counts[i]++
+}
+
+// rate returns the average evictions per second over the window ending at now
+func (r *evictionRate) rate(now time.Time) float64 {
+	second := now.Unix()
+	var total int64
+	for i := range r.counts {
+		if second-r.seconds[i] < thrashWindow {
+			total += r.counts[i]
+		}
+	}
+	return float64(total) / thrashWindow
+}
+
+// thrashState detects an eviction rate above threshold, the sign of a working set larger
+// than the cache, and applies the configured protective mode. Guarded by the cache mutex.
+type thrashState struct {
+	threshold float64 // evictions per second; 0 disables detection
+	mode      string  // constants.ThrashModeWarn or constants.ThrashModeReject
+	engaged   bool
+	evictions evictionRate
+	callbacks []ThrashCallback
+}
+
+// thrashing reports whether the eviction rate at now is above the threshold
+func (t *thrashState) thrashing(now time.Time) bool {
+	return t.threshold > 0 && t.evictions.rate(now) > t.threshold
+}
+
+// OnThrashing registers a callback fired on its own goroutine whenever the cache starts
+// or stops thrashing. It only fires when a thrash threshold is configured.
+func (cs *CacheService) OnThrashing(fn ThrashCallback) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	cs.thrash.callbacks = append(cs.thrash.callbacks, fn)
+}
+
+// updateThrashing re-evaluates the eviction rate, firing the callbacks when the cache starts
+// or stops thrashing, and returns ErrThrashing when new keys must be rejected. Must be called
+// with the write lock held.
+func (cs *CacheService) updateThrashing() error {
+	now := time.Now()
+	engaged := cs.thrash.thrashing(now)
+	if engaged != cs.thrash.engaged {
+		cs.thrash.engaged = engaged
+		callbacks, rate := cs.thrash.callbacks, cs.thrash.evictions.rate(now)
+		go func() {
+			for _, fn := range callbacks {
+				fn(engaged, rate)
+			}
+		}()
+	}
+
+	if engaged && cs.thrash.mode == constants.ThrashModeReject {
+		return constants.ErrThrashing
+	}
+	return nil
+}
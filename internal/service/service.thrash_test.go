@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/constants"
+)
+
+func TestThrashingEngagesAndRecovers(t *testing.T) {
+	for _, mode := range []string{constants.ThrashModeWarn, constants.ThrashModeReject} {
+		// Above 0.5 evictions per second over the window: more than 5 evictions
+		cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 2, ThrashThreshold: 0.5, ThrashMode: mode, DisableCleanup: true})
+		changes := make(chan bool, 4)
+		cs.OnThrashing(func(engaged bool, rate float64) { changes <- engaged })
+		waitChange := func(want bool) {
+			t.Helper()
+			select {
+			case engaged := <-changes:
+				if engaged != want {
+					t.Fatalf("%s: callback fired with engaged=%v, want %v", mode, engaged, want)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("%s: callback not fired for engaged=%v", mode, want)
+			}
+		}
+
+		for i := 0; i < 8; i++ {
+			if _, err := cs.Put(fmt.Sprintf("k%d", i), i, nil); err != nil {
+				t.Fatalf("%s: put k%d before thrashing: %v", mode, i, err)
+			}
+		}
+
+		// The next eviction finds the rate above the threshold
+		_, err := cs.Put("k8", 8, nil)
+		if mode == constants.ThrashModeReject && !errors.Is(err, constants.ErrThrashing) {
+			t.Fatalf("%s: put of a new key while thrashing = %v, want ErrThrashing", mode, err)
+		}
+		if mode == constants.ThrashModeWarn && err != nil {
+			t.Fatalf("%s: put of a new key while thrashing = %v, want it stored", mode, err)
+		}
+		waitChange(true)
+		if !cs.GetStats().Thrashing {
+			t.Fatalf("%s: stats do not report thrashing", mode)
+		}
+		if _, err := cs.Put("k7", "updated", nil); err != nil {
+			t.Fatalf("%s: update of a present key while thrashing: %v", mode, err)
+		}
+
+		// Let the evictions fall out of the window
+		cs.mutex.Lock()
+		for i := range cs.thrash.evictions.seconds {
+			cs.thrash.evictions.seconds[i] -= thrashWindow
+		}
+		cs.mutex.Unlock()
+		if _, err := cs.Put("k9", 9, nil); err != nil {
+			t.Fatalf("%s: put after evictions slowed down: %v", mode, err)
+		}
+		waitChange(false)
+		if cs.GetStats().Thrashing {
+			t.Fatalf("%s: stats still report thrashing", mode)
+		}
+		cs.Close()
+	}
+}
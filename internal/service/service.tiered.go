@@ -0,0 +1,244 @@
+package service
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+const defaultHotStripes = 16
+
+// TieredCacheService puts a small lock-striped hot LRU in front of a CacheService.
+// Reads are served from the hot tier when possible and promoted into it on a
+// main-tier hit; writes always go through to the main tier, which drops the keys they
+// change from the hot tier.
+//
+// A hot hit never takes the main tier's lock: it counts as a hit in the main tier's
+// statistics and is queued as a read of the main entry, applied to its recency and access
+// time in batches as under BatchPromotions, which wrapping turns on. Keys with an idle
+// limit, expired or stale keys and every key while TTL promotion is on are only read
+// from the main tier, which those reads change beyond recency.
+type TieredCacheService struct {
+	*CacheService
+
+	stripes []*hotStripe
+}
+
+// hotStripe is one independently locked slice of the hot tier
+type hotStripe struct {
+	mutex    sync.Mutex
+	capacity int
+	items    map[string]*list.Element // values are *hotItem
+	order    *list.List               // front is most recently used
+	gen      uint64                   // bumped on every invalidation
+}
+
+// hotItem is a main-tier entry held in the hot tier. Every change to the entry drops the
+// item, so the fields copied from it when it was promoted stay current.
+type hotItem struct {
+	key        string
+	entry      *models.CacheEntry // the main tier's entry, for queueing promotions
+	plain      *models.CacheEntry // entry with its value decrypted; entry itself without a cipher
+	value      interface{}        // entry's stored value, checked against checksum on every hit
+	checksum   uint32
+	expiration int64
+}
+
+// NewTieredCacheService wraps main with a hot tier holding up to hotSize entries. Wrap the
+// service before it serves reads, as it turns on batched promotions.
+func NewTieredCacheService(main *CacheService, hotSize int) *TieredCacheService {
+	stripeCount := defaultHotStripes
+	if hotSize < stripeCount {
+		stripeCount = 1
+	}
+	perStripe := hotSize / stripeCount
+	if perStripe < 1 {
+		perStripe = 1
+	}
+
+	tiered := &TieredCacheService{
+		CacheService: main,
+		stripes:      make([]*hotStripe, stripeCount),
+	}
+	for i := range tiered.stripes {
+		tiered.stripes[i] = &hotStripe{
+			capacity: perStripe,
+			items:    make(map[string]*list.Element),
+			order:    list.New(),
+		}
+	}
+
+	main.mutex.Lock()
+	main.hot = tiered
+	if main.promotions == nil {
+		main.promotions = newPromotionQueue()
+		go main.promotionWorker()
+	}
+	main.mutex.Unlock()
+
+	return tiered
+}
+
+// Get checks the hot tier first and falls back to the main tier, promoting on hit
+func (tc *TieredCacheService) Get(key string) (*models.CacheEntry, bool) {
+	if key == "" {
+		return tc.CacheService.Get(key)
+	}
+
+	// The hot tier is keyed like the main map so invalidations line up
+	hotKey := tc.internalKey(key)
+	stripe := tc.stripeFor(hotKey)
+
+	stripe.mutex.Lock()
+	if elem, ok := stripe.items[hotKey]; ok {
+		item := elem.Value.(*hotItem)
+		if tc.servable(item) && tc.promotions.record(item.entry) {
+			stripe.order.MoveToFront(elem)
+			stripe.mutex.Unlock()
+			atomic.AddInt64(&item.entry.AccessCount, 1)
+			atomic.AddInt64(&tc.hits, 1)
+			return tc.transformGet(key, item.plain), true
+		}
+		// Expired, corrupted or out of promotion room: the main tier handles all three
+		stripe.remove(elem)
+	}
+	gen := stripe.gen
+	stripe.mutex.Unlock()
+
+	entry, found := tc.CacheService.Get(key)
+	if !found {
+		return nil, false
+	}
+	tc.promote(stripe, gen, hotKey)
+
+	return entry, true
+}
+
+// servable reports whether a hot item may be served without the main tier: it has not
+// expired and its value still matches the checksum taken when it was written
+func (tc *TieredCacheService) servable(item *hotItem) bool {
+	if item.expiration != 0 && time.Now().Unix() > item.expiration {
+		return false
+	}
+	return !tc.checksums.enabled || tc.valueChecksum(item.value) == item.checksum
+}
+
+// promote copies the main tier's entry for hotKey into the hot tier after a main-tier hit,
+// unless the stripe was invalidated since gen was read or the key must stay in the main tier
+func (tc *TieredCacheService) promote(stripe *hotStripe, gen uint64, hotKey string) {
+	if tc.ttlPromotionStep > 0 {
+		return
+	}
+
+	tc.mutex.RLock()
+	entry, exists := tc.data[hotKey]
+	if !exists || entry.MaxIdle > 0 || entry.IsExpired() {
+		tc.mutex.RUnlock()
+		return
+	}
+	item := &hotItem{
+		key:        hotKey,
+		entry:      entry,
+		plain:      entry,
+		value:      entry.GetValue(),
+		checksum:   entry.Checksum,
+		expiration: entry.Expiration,
+	}
+	if tc.cipher != nil {
+		plain, err := tc.decryptedCopy(entry)
+		if err != nil {
+			tc.mutex.RUnlock()
+			return
+		}
+		item.plain = plain
+	}
+	tc.mutex.RUnlock()
+
+	// A change after the main-tier read bumped gen, even one made before the lookup above
+	stripe.mutex.Lock()
+	if stripe.gen == gen {
+		stripe.add(item)
+	}
+	stripe.mutex.Unlock()
+}
+
+// BulkGet performs multiple get operations through the hot tier, like CacheService.BulkGet
+func (tc *TieredCacheService) BulkGet(keys []string, defaultValue interface{}) models.BulkGetResponse {
+	response := models.BulkGetResponse{
+		Results: make(map[string]models.GetResponse),
+	}
+
+	for _, key := range keys {
+		if entry, found := tc.Get(key); found {
+			result := entry.ToResponse()
+			result.Key = key // report the client key even if it is stored hashed
+			response.Results[key] = result
+			response.Found++
+		} else {
+			response.Results[key] = models.GetResponse{
+				Key:   key,
+				Value: defaultValue,
+				Found: false,
+			}
+			response.NotFound++
+		}
+	}
+
+	return response
+}
+
+// invalidate drops a key, in its stored form, from the hot tier; called by the main tier
+// under its lock
+func (tc *TieredCacheService) invalidate(key string) {
+	stripe := tc.stripeFor(key)
+
+	stripe.mutex.Lock()
+	if elem, ok := stripe.items[key]; ok {
+		stripe.remove(elem)
+	}
+	stripe.gen++
+	stripe.mutex.Unlock()
+}
+
+// invalidateAll empties the hot tier; called by the main tier under its lock
+func (tc *TieredCacheService) invalidateAll() {
+	for _, stripe := range tc.stripes {
+		stripe.mutex.Lock()
+		stripe.items = make(map[string]*list.Element)
+		stripe.order.Init()
+		stripe.gen++
+		stripe.mutex.Unlock()
+	}
+}
+
+// stripeFor picks the stripe owning a key
+func (tc *TieredCacheService) stripeFor(key string) *hotStripe {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return tc.stripes[h.Sum32()%uint32(len(tc.stripes))]
+}
+
+// add inserts an item at the front, evicting the stripe's LRU item when full
+func (hs *hotStripe) add(item *hotItem) {
+	if elem, ok := hs.items[item.key]; ok {
+		elem.Value = item
+		hs.order.MoveToFront(elem)
+		return
+	}
+
+	if hs.order.Len() >= hs.capacity {
+		hs.remove(hs.order.Back())
+	}
+
+	hs.items[item.key] = hs.order.PushFront(item)
+}
+
+// remove drops an element from the stripe
+func (hs *hotStripe) remove(elem *list.Element) {
+	delete(hs.items, elem.Value.(*hotItem).key)
+	hs.order.Remove(elem)
+}
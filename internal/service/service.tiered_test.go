@@ -0,0 +1,248 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hotHit reads key through tc twice, so the second read is served by the hot tier, and
+// returns the value it got
+func hotHit(t *testing.T, tc *TieredCacheService, key string) interface{} {
+	t.Helper()
+	for i := 0; i < 2; i++ {
+		if _, found := tc.Get(key); !found {
+			t.Fatalf("Get(%q) missed on read %d", key, i+1)
+		}
+	}
+	entry, found := tc.Get(key)
+	if !found {
+		t.Fatalf("Get(%q) missed from the hot tier", key)
+	}
+	return entry.GetValue()
+}
+
+func TestTieredWritesInvalidateHotTier(t *testing.T) {
+	tc := NewTieredCacheService(NewCacheServiceWithOptions(CacheOptions{MaxSize: 10}), 4)
+	defer tc.Close()
+
+	tc.Put("k", "one", nil)
+	if got := hotHit(t, tc, "k"); got != "one" {
+		t.Fatalf("hot value %v, want one", got)
+	}
+
+	tc.Put("k", "two", nil)
+	if entry, found := tc.Get("k"); !found || entry.GetValue() != "two" {
+		t.Fatalf("Get after Put = %v, %v; want two", entry, found)
+	}
+
+	hotHit(t, tc, "k")
+	if err := tc.Rename("k", "renamed", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := tc.Get("k"); found {
+		t.Fatal("renamed key still served from the hot tier")
+	}
+
+	hotHit(t, tc, "renamed")
+	tc.Expire("renamed", time.Second)
+	tc.mutex.Lock()
+	tc.data["renamed"].Expiration = time.Now().Add(-time.Second).Unix()
+	tc.mutex.Unlock()
+	if _, found := tc.Get("renamed"); found {
+		t.Fatal("expired key served from the hot tier")
+	}
+
+	tc.Put("d", "gone", nil)
+	hotHit(t, tc, "d")
+	tc.Delete("d")
+	if _, found := tc.Get("d"); found {
+		t.Fatal("deleted key still served from the hot tier")
+	}
+
+	tc.Put("c", "cleared", nil)
+	hotHit(t, tc, "c")
+	tc.Clear()
+	if _, found := tc.Get("c"); found {
+		t.Fatal("cleared key still served from the hot tier")
+	}
+}
+
+func TestTieredHashedKeysInvalidate(t *testing.T) {
+	tc := NewTieredCacheService(NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, KeyHashThreshold: 8}), 4)
+	defer tc.Close()
+
+	key := strings.Repeat("long-key:", 4)
+	tc.Put(key, "one", nil)
+	hotHit(t, tc, key)
+	tc.Put(key, "two", nil)
+	if entry, found := tc.Get(key); !found || entry.GetValue() != "two" {
+		t.Fatalf("Get after Put = %v, %v; want two", entry, found)
+	}
+}
+
+func TestTieredHotHitPromotesInMainTier(t *testing.T) {
+	tc := NewTieredCacheService(NewCacheServiceWithOptions(CacheOptions{MaxSize: 3, DisableCleanup: true}), 4)
+	defer tc.Close()
+
+	tc.Put("a", 1, nil)
+	hotHit(t, tc, "a")
+	tc.Put("b", 2, nil)
+	tc.Put("c", 3, nil)
+
+	before, _ := tc.GetInfo("a")
+	time.Sleep(5 * time.Millisecond)
+	hits := tc.GetStats().Hits
+	if _, found := tc.Get("a"); !found {
+		t.Fatal("a missed")
+	}
+	if got := tc.GetStats().Hits; got != hits+1 {
+		t.Fatalf("hot hit counted %d hits, want 1", got-hits)
+	}
+
+	// The queued read protects a from the eviction d causes; b is now least recently used
+	tc.Put("d", 4, nil)
+	if _, found := tc.CacheService.Get("a"); !found {
+		t.Fatal("a was evicted although a hot hit made it the most recently used")
+	}
+	if _, found := tc.CacheService.Get("b"); found {
+		t.Fatal("b survived; want it evicted as least recently used")
+	}
+	after, _ := tc.GetInfo("a")
+	if !after.AccessedAt.After(before.AccessedAt) {
+		t.Fatalf("accessed_at %v not moved past %v by a hot hit", after.AccessedAt, before.AccessedAt)
+	}
+}
+
+func TestTieredHotHitVerifiesChecksum(t *testing.T) {
+	tc := NewTieredCacheService(NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, ChecksumValues: true}), 4)
+	defer tc.Close()
+
+	tc.Put("k", map[string]interface{}{"n": 1.0}, nil)
+	value := hotHit(t, tc, "k")
+
+	// The stored map is shared with readers; changing it is the corruption checksums catch
+	value.(map[string]interface{})["n"] = 2.0
+	if _, found := tc.Get("k"); found {
+		t.Fatal("corrupted value served from the hot tier")
+	}
+	if got := tc.GetStats().CorruptionsDetected; got != 1 {
+		t.Fatalf("corruptions_detected = %d, want 1", got)
+	}
+}
+
+func TestTieredEncryptedValues(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	tc := NewTieredCacheService(NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, EncryptionKey: key}), 4)
+	defer tc.Close()
+
+	tc.Put("k", "secret", nil)
+	if got := hotHit(t, tc, "k"); got != "secret" {
+		t.Fatalf("hot value %v, want the decrypted secret", got)
+	}
+}
+
+func TestTieredBulkGet(t *testing.T) {
+	tc := NewTieredCacheService(NewCacheServiceWithOptions(CacheOptions{MaxSize: 10}), 4)
+	defer tc.Close()
+
+	tc.Put("a", 1, nil)
+	hotHit(t, tc, "a")
+	response := tc.BulkGet([]string{"a", "missing"}, "fallback")
+	if response.Found != 1 || response.NotFound != 1 {
+		t.Fatalf("found %d, not found %d; want 1 and 1", response.Found, response.NotFound)
+	}
+	if got := response.Results["a"].Value; got != 1 {
+		t.Fatalf("a = %v, want 1", got)
+	}
+	if got := response.Results["missing"]; got.Found || got.Value != "fallback" {
+		t.Fatalf("missing = %+v, want the default and found=false", got)
+	}
+}
+
+func TestTieredHotHitRacingClear(t *testing.T) {
+	tc := NewTieredCacheService(NewCacheServiceWithOptions(CacheOptions{MaxSize: 4, DisableCleanup: true}), 4)
+	defer tc.Close()
+
+	tc.Put("a", 1, nil)
+	hotHit(t, tc, "a")
+
+	// Stall a hot hit holding its stripe, where it queues its promotion, while Clear runs
+	stripe := tc.stripeFor(tc.internalKey("a"))
+	stripe.mutex.Lock()
+	item := stripe.items[tc.internalKey("a")].Value.(*hotItem)
+	cleared := make(chan struct{})
+	go func() {
+		defer close(cleared)
+		tc.Clear()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	tc.promotions.record(item.entry)
+	stripe.mutex.Unlock()
+	<-cleared
+
+	// Applying a promotion of the swapped-out entry would relink it in place of the new
+	// a, and evicting it would then take the new a out of the map
+	tc.Put("a", 2, nil)
+	tc.mutex.Lock()
+	tc.applyPromotions()
+	tc.mutex.Unlock()
+	checkList(t, tc.CacheService)
+	for i := 0; i < 8; i++ {
+		tc.Put(fmt.Sprintf("fill%d", i), i, nil)
+	}
+	checkList(t, tc.CacheService)
+	if size := tc.GetStats().CurrentSize; size != 4 {
+		t.Fatalf("size = %d after evictions, want 4", size)
+	}
+}
+
+// benchmarkSkewedGets reads from 10000 keys in parallel, 90% of reads going to 100 of them
+func benchmarkSkewedGets(b *testing.B, get func(key string)) {
+	keys := make([]string, 10000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key:%d", i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			if r.Intn(10) == 0 {
+				get(keys[r.Intn(len(keys))])
+			} else {
+				get(keys[r.Intn(100)])
+			}
+		}
+	})
+}
+
+// newSkewedCache builds a cache holding the keys benchmarkSkewedGets reads
+func newSkewedCache(opts CacheOptions) *CacheService {
+	opts.MaxSize = 10000
+	cs := NewCacheServiceWithOptions(opts)
+	for i := 0; i < 10000; i++ {
+		cs.Put(fmt.Sprintf("key:%d", i), i, nil)
+	}
+	return cs
+}
+
+func BenchmarkGetSkewed(b *testing.B) {
+	cs := newSkewedCache(CacheOptions{})
+	defer cs.Close()
+	benchmarkSkewedGets(b, func(key string) { cs.Get(key) })
+}
+
+func BenchmarkGetSkewedBatchPromotions(b *testing.B) {
+	cs := newSkewedCache(CacheOptions{BatchPromotions: true})
+	defer cs.Close()
+	benchmarkSkewedGets(b, func(key string) { cs.Get(key) })
+}
+
+func BenchmarkTieredGetSkewed(b *testing.B) {
+	tc := NewTieredCacheService(newSkewedCache(CacheOptions{}), 256)
+	defer tc.Close()
+	benchmarkSkewedGets(b, func(key string) { tc.Get(key) })
+}
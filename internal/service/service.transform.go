@@ -0,0 +1,70 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// TransformFunc rewrites a value on its way into or out of the cache, e.g. to trim,
+// normalize or redact it. key is the key as the client gave it. A transform must not
+// call back into the cache, as get transforms run under the cache lock.
+type TransformFunc func(key string, value interface{}) interface{}
+
+// transformState holds the registered transforms. It has its own lock so a put can apply
+// them before taking the cache lock.
+type transformState struct {
+	mutex sync.RWMutex
+	put   []TransformFunc
+	get   []TransformFunc
+}
+
+// RegisterPutTransform adds a transform applied to every value stored by a put, bulk put,
+// warmup, import, ReplaceAll or GetOrPut default, before it is measured and encrypted.
+// Transforms run in registration order, each given the previous one's result.
+func (cs *CacheService) RegisterPutTransform(fn TransformFunc) {
+	cs.transforms.mutex.Lock()
+	defer cs.transforms.mutex.Unlock()
+
+	cs.transforms.put = append(cs.transforms.put, fn)
+}
+
+// RegisterGetTransform adds a transform applied to every value returned by Get (and so
+// BulkGet) and GetOrPut. The stored value is left as it is. Transforms run in
+// registration order, each given the previous one's result.
+func (cs *CacheService) RegisterGetTransform(fn TransformFunc) {
+	cs.transforms.mutex.Lock()
+	defer cs.transforms.mutex.Unlock()
+
+	cs.transforms.get = append(cs.transforms.get, fn)
+}
+
+// transformPut returns value as rewritten by the put transforms
+func (cs *CacheService) transformPut(key string, value interface{}) interface{} {
+	cs.transforms.mutex.RLock()
+	defer cs.transforms.mutex.RUnlock()
+
+	for _, fn := range cs.transforms.put {
+		value = fn(key, value)
+	}
+	return value
+}
+
+// transformGet returns entry with its value rewritten by the get transforms. The entry is
+// copied first, so the stored one is never changed; without get transforms it is returned
+// as it is. Callers must hold the cache lock.
+func (cs *CacheService) transformGet(key string, entry *models.CacheEntry) *models.CacheEntry {
+	cs.transforms.mutex.RLock()
+	defer cs.transforms.mutex.RUnlock()
+
+	if len(cs.transforms.get) == 0 {
+		return entry
+	}
+	value := entry.GetValue()
+	for _, fn := range cs.transforms.get {
+		value = fn(key, value)
+	}
+	copied := *entry
+	copied.SetValue(value)
+	return &copied
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// upper uppercases string values and leaves others alone
+func upper(key string, value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.ToUpper(s)
+	}
+	return value
+}
+
+func TestPutTransformUppercases(t *testing.T) {
+	cs := NewCacheService(10, 0)
+	defer cs.Close()
+	cs.RegisterPutTransform(upper)
+
+	cs.Put("k", "hello", nil)
+	cs.BulkPut([]models.PutRequest{{Key: "b", Value: "bulk"}, {Key: "n", Value: 1.5}}, nil)
+	entry, _, err := cs.GetOrPut("d", "default", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.GetValue() != "DEFAULT" {
+		t.Fatalf("GetOrPut stored %v, want DEFAULT", entry.GetValue())
+	}
+
+	for key, want := range map[string]interface{}{"k": "HELLO", "b": "BULK", "n": 1.5, "d": "DEFAULT"} {
+		if entry, found := cs.Get(key); !found || entry.GetValue() != want {
+			t.Fatalf("Get(%s) = %v, %v; want %v", key, entry, found, want)
+		}
+	}
+}
+
+func TestGetTransformLeavesStoredValue(t *testing.T) {
+	cs := NewCacheService(10, 0)
+	defer cs.Close()
+	cs.RegisterGetTransform(upper)
+	// Transforms chain in registration order
+	cs.RegisterGetTransform(func(key string, value interface{}) interface{} {
+		return key + "=" + value.(string)
+	})
+
+	cs.Put("k", "hello", nil)
+	if entry, found := cs.Get("k"); !found || entry.GetValue() != "k=HELLO" {
+		t.Fatalf("Get = %v, %v; want k=HELLO", entry, found)
+	}
+	if response := cs.BulkGet([]string{"k"}, nil); response.Results["k"].Value != "k=HELLO" {
+		t.Fatalf("BulkGet = %v, want k=HELLO", response.Results["k"].Value)
+	}
+
+	cs.mutex.RLock()
+	stored := cs.data["k"].GetValue()
+	cs.mutex.RUnlock()
+	if stored != "hello" {
+		t.Fatalf("stored value %v changed by a get transform", stored)
+	}
+}
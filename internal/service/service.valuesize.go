@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// valueSizeStats aggregates the serialized sizes of stored values, guarded by the cache mutex.
+// count, total, min and max cover every value ever written; current sums the live entries.
+type valueSizeStats struct {
+	count   int64
+	total   int64
+	min     int64
+	max     int64
+	current int64
+}
+
+// valueSize returns the size of value as JSON, the form clients send and receive it in.
+// Values that cannot be serialized count as 0 bytes.
+func valueSize(value interface{}) int64 {
+	if raw, ok := value.(json.RawMessage); ok {
+		return int64(len(raw))
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return int64(len(encoded))
+}
+
+// storeValueSize records a value of size bytes written to entry, replacing its previous
+// value in the current total. Callers must hold the write lock.
+func (cs *CacheService) storeValueSize(entry *models.CacheEntry, size int64) {
+	cs.valueSizes.record(size)
+	cs.valueSizes.current += size - entry.ValueSize
+	entry.ValueSize = size
+}
+
+// record counts a write of size bytes, leaving current to the caller
+func (s *valueSizeStats) record(size int64) {
+	if s.count == 0 || size < s.min {
+		s.min = size
+	}
+	if size > s.max {
+		s.max = size
+	}
+	s.count++
+	s.total += size
+}
+
+// replace folds the writes counted in other into s and takes other's current total,
+// for a cache whose entries were all replaced by the ones other describes
+func (s *valueSizeStats) replace(other valueSizeStats) {
+	if other.count > 0 {
+		if s.count == 0 || other.min < s.min {
+			s.min = other.min
+		}
+		s.max = max(s.max, other.max)
+		s.count += other.count
+		s.total += other.total
+	}
+	s.current = other.current
+}
+
+// ValueSizes summarizes the serialized sizes of stored values: min, max and average over
+// every write since startup, and the total held by the entries currently in the cache
+func (cs *CacheService) ValueSizes() models.ValueSizeStats {
+	cs.mutex.RLock()
+	stats := cs.valueSizes
+	cs.mutex.RUnlock()
+
+	result := models.ValueSizeStats{
+		Writes:       stats.count,
+		MinBytes:     stats.min,
+		MaxBytes:     stats.max,
+		CurrentBytes: stats.current,
+	}
+	if stats.count > 0 {
+		result.AvgBytes = float64(stats.total) / float64(stats.count)
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// Names of the consistency checks run by Verify
+const (
+	CheckSentinels    = "sentinels"
+	CheckListLinks    = "list_links"
+	CheckListLength   = "list_length"
+	CheckNodesInMap   = "nodes_in_map"
+	CheckNoTombstones = "no_tombstones"
+)
+
+// Verify walks the LRU list under a read lock and checks it against the key map.
+// It is meant for diagnostics: a failure means the pointer bookkeeping has a bug.
+func (cs *CacheService) Verify() []models.CheckResult {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	results := []models.CheckResult{}
+	check := func(name string, err error) {
+		result := models.CheckResult{Name: name, Passed: err == nil}
+		if err != nil {
+			result.Detail = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	// Without intact sentinels the list cannot be walked
+	if cs.head == nil || cs.tail == nil || cs.head.Prev != nil || cs.tail.Next != nil ||
+		cs.head.Next == nil || cs.tail.Prev == nil {
+		check(CheckSentinels, fmt.Errorf("head/tail sentinels are missing or linked outward"))
+		return results
+	}
+	check(CheckSentinels, nil)
+
+	// Walk head to tail; more nodes than map entries means a cycle or orphaned nodes
+	var linkErr, mapErr, tombstoneErr error
+	count := 0
+	limit := len(cs.data) + 1
+	for node := cs.head; node != cs.tail; node = node.Next {
+		if node.Next == nil {
+			linkErr = fmt.Errorf("list ends at %s before reaching the tail sentinel", cs.nodeName(node))
+			break
+		}
+		if node.Next.Prev != node {
+			linkErr = fmt.Errorf("back pointer of the node after %s does not point back to it", cs.nodeName(node))
+			break
+		}
+		if node.Next == cs.tail {
+			break
+		}
+
+		count++
+		if count > limit {
+			linkErr = fmt.Errorf("walked more than %d nodes, the list has a cycle", limit)
+			break
+		}
+
+		entry := node.Next
+		if mapErr == nil && cs.data[entry.Key] != entry {
+			mapErr = fmt.Errorf("node %q is linked but not in the key map", entry.Key)
+		}
+		if tombstoneErr == nil && entry.Removed {
+			tombstoneErr = fmt.Errorf("node %q is linked but tombstoned as removed", entry.Key)
+		}
+	}
+	check(CheckListLinks, linkErr)
+
+	var lengthErr error
+	if linkErr == nil && count != len(cs.data) {
+		lengthErr = fmt.Errorf("list has %d nodes but the map has %d entries", count, len(cs.data))
+	} else if linkErr != nil {
+		lengthErr = fmt.Errorf("not checked, list links are broken")
+	}
+	check(CheckListLength, lengthErr)
+	check(CheckNodesInMap, mapErr)
+	check(CheckNoTombstones, tombstoneErr)
+
+	return results
+}
+
+// nodeName describes a list node for check details
+func (cs *CacheService) nodeName(node *models.CacheEntry) string {
+	if node == cs.head {
+		return "the head sentinel"
+	}
+	return fmt.Sprintf("%q", node.Key)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// failedChecks returns the details of the checks in results that failed, by name
+func failedChecks(results []models.CheckResult) map[string]string {
+	failed := make(map[string]string)
+	for _, result := range results {
+		if !result.Passed {
+			failed[result.Name] = result.Detail
+		}
+	}
+	return failed
+}
+
+func TestVerifyReportsBrokenBackLink(t *testing.T) {
+	cs := NewCacheServiceWithOptions(CacheOptions{MaxSize: 10, DisableCleanup: true})
+	defer cs.Close()
+	for _, key := range []string{"a", "b", "c"} {
+		cs.Put(key, key, nil)
+	}
+	if failed := failedChecks(cs.Verify()); len(failed) != 0 {
+		t.Fatalf("checks failed on an intact list: %v", failed)
+	}
+
+	// The list runs c, b, a; point b back at the head instead of c
+	cs.mutex.Lock()
+	cs.data["b"].Prev = cs.head
+	cs.mutex.Unlock()
+
+	failed := failedChecks(cs.Verify())
+	if want := `back pointer of the node after "c" does not point back to it`; failed[CheckListLinks] != want {
+		t.Fatalf("%s detail %q, want %q", CheckListLinks, failed[CheckListLinks], want)
+	}
+	if want := "not checked, list links are broken"; failed[CheckListLength] != want {
+		t.Fatalf("%s detail %q, want %q", CheckListLength, failed[CheckListLength], want)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("failed checks %v, want only %s and %s", failed, CheckListLinks, CheckListLength)
+	}
+}
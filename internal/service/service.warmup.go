@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/Vinodbagra/cache-thread/internal/models"
+)
+
+// Warmup loads items the way BulkPut does. With skipExisting, keys that already hold a
+// live value are left alone and counted as skipped; the check and the put are separate
+// steps, so a key written concurrently in between is overwritten.
+func (cs *CacheService) Warmup(items []models.PutRequest, batchTTL *int, skipExisting bool) models.WarmupResponse {
+	response := models.WarmupResponse{}
+
+	for _, item := range items {
+		if skipExisting && cs.live(item.Key) {
+			response.Skipped++
+			continue
+		}
+		if _, err := cs.PutItem(item, batchTTL); err != nil {
+			response.Failed++
+			response.Errors = append(response.Errors, fmt.Sprintf("Key '%s': %v", item.Key, err))
+		} else {
+			response.Loaded++
+		}
+	}
+
+	return response
+}
+
+// live reports whether key holds an unexpired value, without promoting it or counting a hit
+func (cs *CacheService) live(key string) bool {
+	cs.mutex.RLock()
+	defer cs.mutex.RUnlock()
+
+	entry, exists := cs.data[cs.internalKey(key)]
+	return exists && !entry.IsExpired()
+}
@@ -0,0 +1,109 @@
+// Package jsonpath evaluates a subset of JSONPath against decoded JSON values
+// (map[string]interface{}, []interface{} and scalars).
+//
+// Supported syntax: the root "$", member access ".name" or "['name']",
+// and array indices "[0]" (negative indices count from the end).
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPath = errors.New("invalid JSONPath expression")
+	ErrNoMatch     = errors.New("JSONPath expression did not match")
+)
+
+// step is a single member or index access in a parsed expression
+type step struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// Lookup returns the portion of value selected by expr
+func Lookup(value interface{}, expr string) (interface{}, error) {
+	steps, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	current := value
+	for _, s := range steps {
+		if s.isIndex {
+			items, ok := current.([]interface{})
+			if !ok {
+				return nil, ErrNoMatch
+			}
+			index := s.index
+			if index < 0 {
+				index += len(items)
+			}
+			if index < 0 || index >= len(items) {
+				return nil, ErrNoMatch
+			}
+			current = items[index]
+			continue
+		}
+
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, ErrNoMatch
+		}
+		if current, ok = object[s.name]; !ok {
+			return nil, ErrNoMatch
+		}
+	}
+
+	return current, nil
+}
+
+// parse splits an expression such as "$.user['first name'].tags[0]" into steps
+func parse(expr string) ([]step, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("%w: must start with '$'", ErrInvalidPath)
+	}
+
+	var steps []step
+	rest := expr[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("%w: empty member name in %q", ErrInvalidPath, expr)
+			}
+			steps = append(steps, step{name: name})
+			rest = rest[end+1:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated '[' in %q", ErrInvalidPath, expr)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, step{name: inner[1 : len(inner)-1]})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("%w: bad index %q in %q", ErrInvalidPath, inner, expr)
+				}
+				steps = append(steps, step{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in %q", ErrInvalidPath, rest[0], expr)
+		}
+	}
+
+	return steps, nil
+}
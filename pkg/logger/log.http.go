@@ -3,6 +3,7 @@ package logger
 import (
 	"fmt"
 
+	"github.com/Vinodbagra/cache-thread/internal/constants"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,7 +24,8 @@ func HTTPLogger(param gin.LogFormatterParams) string {
 	default:
 		color = Green
 	}
-	return fmt.Sprintf("[LOGGING HTTP] [%s] \033[%sm %d \033[0m %s %s %d %s %s %s\n",
+	requestID, _ := param.Keys[constants.LoggerRequestID].(string)
+	return fmt.Sprintf("[LOGGING HTTP] [%s] \033[%sm %d \033[0m %s %s %d %s %s %s %s=%s\n",
 		param.TimeStamp.Format("2006-01-02 15:04:05"),
 		color,
 		param.StatusCode,
@@ -33,5 +35,7 @@ func HTTPLogger(param gin.LogFormatterParams) string {
 		param.ClientIP,
 		param.ErrorMessage,
 		param.Request.UserAgent(),
+		constants.LoggerRequestID,
+		requestID,
 	)
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -23,6 +24,11 @@ func init() {
 	})
 }
 
+// SetOutput sends log entries to out instead of stdout
+func SetOutput(out io.Writer) {
+	log.SetOutput(out)
+}
+
 func Info(message string, fields logrus.Fields) {
 	log.WithFields(fields).Info(message)
 }
@@ -39,6 +45,14 @@ func DebugF(format string, fields logrus.Fields, args ...interface{}) {
 	log.WithFields(fields).Debugf(format, args...)
 }
 
+func Warn(message string, fields logrus.Fields) {
+	log.WithFields(fields).Warn(message)
+}
+
+func WarnF(format string, fields logrus.Fields, args ...interface{}) {
+	log.WithFields(fields).Warnf(format, args...)
+}
+
 func Error(message string, fields logrus.Fields) {
 	log.WithFields(fields).Error(message)
 }